@@ -0,0 +1,91 @@
+// Пакет auth содержит хеширование паролей, выпуск токенов сессий и доступа и передачу
+// текущего пользователя через контекст запроса.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Хеш bcrypt, с которым сравнивается пароль неизвестного пользователя, чтобы время ответа
+// на вход не выдавало, существует ли пользователь.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// Функция HashPassword возвращает хеш пароля bcrypt для хранения в базе данных.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Функция CheckPassword сравнивает пароль с хешем bcrypt. Пустой хеш (пользователь не найден)
+// проверяется так же долго, как настоящий, но никогда не совпадает.
+func CheckPassword(hash, password string) bool {
+	if hash == "" {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// Функция NewToken создает случайный токен (256 бит) и возвращает его вместе с хешем для хранения.
+func NewToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// Функция HashToken возвращает SHA-256 токена в шестнадцатеричном виде. Токены случайны
+// и длинны, поэтому медленный хеш, как для паролей, им не нужен.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Типы ключей контекста, недоступные другим пакетам.
+type (
+	contextKey      struct{}
+	tokenContextKey struct{}
+)
+
+// Функция WithUser возвращает контекст запроса с аутентифицированным пользователем.
+func WithUser(ctx context.Context, user db.User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// Функция UserFrom возвращает пользователя из контекста запроса; ok равен false,
+// если запрос не прошел аутентификацию.
+func UserFrom(ctx context.Context) (user db.User, ok bool) {
+	user, ok = ctx.Value(contextKey{}).(db.User)
+	return user, ok
+}
+
+// Функция WithToken возвращает контекст запроса, аутентифицированного персональным токеном доступа.
+func WithToken(ctx context.Context, token db.APIToken) context.Context {
+	return context.WithValue(ctx, tokenContextKey{}, token)
+}
+
+// Функция TokenFrom возвращает токен, которым аутентифицирован запрос; ok равен false,
+// если запрос аутентифицирован сессией.
+func TokenFrom(ctx context.Context) (token db.APIToken, ok bool) {
+	token, ok = ctx.Value(tokenContextKey{}).(db.APIToken)
+	return token, ok
+}
+
+// Функция HasScope сообщает, разрешено ли запросу действие scope. Запросам с сессией
+// разрешено все, а запросам с токеном - только выданные токену разрешения.
+func HasScope(ctx context.Context, scope string) bool {
+	token, ok := TokenFrom(ctx)
+	return !ok || token.HasScope(scope)
+}
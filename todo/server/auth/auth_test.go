@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для хеширования и проверки пароля.
+func TestPassword(t *testing.T) {
+	hash, err := HashPassword("password")
+	assert.NoError(t, err)
+	assert.NotEqual(t, "password", hash)
+
+	assert.True(t, CheckPassword(hash, "password"))
+	assert.False(t, CheckPassword(hash, "Password"))
+	// Пустой хеш (пользователь не найден) не совпадает ни с каким паролем.
+	assert.False(t, CheckPassword("", ""))
+}
+
+// Тест для выпуска токенов: токены случайны, а хранится только их хеш.
+func TestNewToken(t *testing.T) {
+	token, hash, err := NewToken()
+	assert.NoError(t, err)
+	assert.Len(t, token, 43)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, HashToken(token))
+
+	other, _, err := NewToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+// Тест для передачи пользователя через контекст запроса.
+func TestUserContext(t *testing.T) {
+	_, ok := UserFrom(context.Background())
+	assert.False(t, ok)
+
+	user, ok := UserFrom(WithUser(context.Background(), db.User{ID: 1, Username: "alice"}))
+	assert.True(t, ok)
+	assert.Equal(t, "alice", user.Username)
+}
+
+// Тест для проверки разрешений запроса: сессии разрешено все, токену - только выданное.
+func TestHasScope(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, HasScope(ctx, db.ScopeTasksWrite))
+
+	ctx = WithToken(ctx, db.APIToken{Scopes: []string{db.ScopeTasksRead}})
+	token, ok := TokenFrom(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []string{db.ScopeTasksRead}, token.Scopes)
+	assert.True(t, HasScope(ctx, db.ScopeTasksRead))
+	assert.False(t, HasScope(ctx, db.ScopeTasksWrite))
+}
@@ -3,25 +3,41 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	_ "github.com/lib/pq" // Импорт драйвера PostgreSQL для использования с database/sql.
 )
 
-// DB - глобальная переменная для хранения соединения с базой данных.
-var DB *sql.DB
-
-// Функция для инициализации соединения с базой данных.
-func InitDB(host, port, user, password, dbname string) {
-	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", user, password, host, port, dbname)
+// Функция PostgresDSN собирает строку подключения к PostgreSQL из отдельных параметров.
+func PostgresDSN(host, port, user, password, dbname string) string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", user, password, host, port, dbname)
+}
 
-	var err error
-	DB, err = sql.Open("postgres", connStr)
-	if err != nil {
-		panic(err)
+// Функция OpenStore открывает хранилище задач, выбирая реализацию по схеме DSN:
+// postgres:// (или postgresql://), sqlite://<путь к файлу> и memory://.
+// Возвращаемую функцию закрытия необходимо вызвать по завершении работы.
+func OpenStore(dsn string) (TaskStore, func() error, error) {
+	scheme, rest, found := strings.Cut(dsn, "://")
+	if !found {
+		return nil, nil, fmt.Errorf("invalid store DSN, expected <scheme>://...: %q", dsn)
 	}
-}
 
-// Функция для закрытия соединения с базой данных.
-func CloseDB() {
-	DB.Close()
+	switch scheme {
+	case "memory":
+		return NewMemoryStore(), func() error { return nil }, nil
+	case "postgres", "postgresql":
+		conn, err := sql.Open("postgres", dsn)
+		if err != nil {
+			return nil, nil, err
+		}
+		return NewPostgresStore(conn), conn.Close, nil
+	case "sqlite":
+		store, conn, err := OpenSQLite(rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, conn.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported store scheme: %s", scheme)
+	}
 }
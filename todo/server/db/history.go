@@ -0,0 +1,235 @@
+package db
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Типы событий в журнале изменений задачи.
+const (
+	EventCreated       = "created"
+	EventUpdated       = "updated"
+	EventStatusChanged = "status_changed"
+	EventDeleted       = "deleted"
+	EventRestored      = "restored"
+	EventPurged        = "purged"
+)
+
+// Структура TaskSnapshot - значения полей задачи, сохраненные в журнале изменений.
+type TaskSnapshot struct {
+	Text         string `json:"text"`
+	CreatedDate  string `json:"createdDate"`
+	ExpectedDate string `json:"expectedDate"`
+	Status       Status `json:"status"`
+	// ProjectID - ID проекта задачи; nil, если задача не входит в проект
+	// или событие записано до появления проектов.
+	ProjectID *int64 `json:"projectId"`
+	// Priority - приоритет задачи; у событий, записанных до появления приоритетов, - обычный.
+	Priority Priority `json:"priority"`
+	// Tags - имена меток задачи через запятую в порядке имен; у событий, записанных до появления
+	// меток в журнале, - пустая строка.
+	Tags    string `json:"tags"`
+	Version int64  `json:"version"`
+}
+
+// Функция snapshotOf возвращает снимок полей задачи.
+func snapshotOf(task Task) *TaskSnapshot {
+	snapshot := &TaskSnapshot{
+		Text:         task.Text,
+		CreatedDate:  task.CreatedDate.Format("2006-01-02"),
+		ExpectedDate: task.ExpectedDate.Format("2006-01-02"),
+		Status:       task.Status,
+		Priority:     task.Priority,
+		Tags:         strings.Join(task.Tags, ","),
+		Version:      task.Version,
+	}
+	if task.ProjectID != 0 {
+		projectID := task.ProjectID
+		snapshot.ProjectID = &projectID
+	}
+	return snapshot
+}
+
+// Функция changeEventType возвращает тип события журнала для изменения задачи current на task
+// или пустую строку, если поля задачи, кроме версии, не изменились.
+func changeEventType(current, task Task) string {
+	old, next := snapshotOf(current), snapshotOf(task)
+	old.Version = next.Version
+	switch {
+	case old.equal(next):
+		return ""
+	case current.Status != task.Status:
+		return EventStatusChanged
+	default:
+		return EventUpdated
+	}
+}
+
+// Метод equal сравнивает снимки по значениям полей, в том числе проекта, на который указывает ProjectID.
+func (s *TaskSnapshot) equal(other *TaskSnapshot) bool {
+	a, b := *s, *other
+	a.ProjectID, b.ProjectID = nil, nil
+	return a == b && projectValue(s.ProjectID) == projectValue(other.ProjectID)
+}
+
+// Функция projectValue возвращает ID проекта из снимка или 0, если проекта нет.
+func projectValue(id *int64) int64 {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+// Структура TaskEvent - запись журнала изменений задачи.
+type TaskEvent struct {
+	ID     int64
+	TaskID int64
+	// OwnerID - автор задачи на момент события.
+	OwnerID int64
+	// ActorID - пользователь, выполнивший действие (0 - неизвестен, напр. для окончательного
+	// удаления по сроку хранения корзины и событий, записанных до появления этого поля).
+	ActorID int64
+	// Reason - причина смены статуса, если она указана (только для EventStatusChanged).
+	Reason string
+	// Type - тип события (EventCreated, EventUpdated, EventStatusChanged, EventDeleted,
+	// EventRestored или EventPurged).
+	Type string
+	// Old и New - значения полей до и после события; Old пуст для создания и восстановления,
+	// New - для удаления в корзину и окончательного удаления.
+	Old *TaskSnapshot
+	New *TaskSnapshot
+	// CreatedAt - время события в UTC.
+	CreatedAt time.Time
+}
+
+// Функция newTaskEvent возвращает событие eventType задачи task от имени пользователя task.EditorID.
+// Причина task.Reason сохраняется только при смене статуса.
+func newTaskEvent(task Task, eventType string, old, next *TaskSnapshot) TaskEvent {
+	event := TaskEvent{
+		TaskID:  task.ID,
+		OwnerID: task.OwnerID,
+		ActorID: task.EditorID,
+		Type:    eventType,
+		Old:     old,
+		New:     next,
+	}
+	if eventType == EventStatusChanged {
+		event.Reason = task.Reason
+	}
+	return event
+}
+
+// Структура FieldChange - старое и новое значение поля задачи в истории.
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Вспомогательная структура для сериализации TaskEvent.
+type TaskEventDTO struct {
+	ID        int64  `json:"id"`
+	TaskID    int64  `json:"taskId"`
+	Type      string `json:"type"`
+	CreatedAt string `json:"createdAt"`
+	// Version - версия задачи после события (для удаления - последняя версия задачи).
+	Version int64 `json:"version"`
+	// ActorID - ID пользователя, выполнившего действие, или null, если он неизвестен.
+	ActorID *int64 `json:"actorId"`
+	// Reason - причина смены статуса, если она указана.
+	Reason string `json:"reason,omitempty"`
+	// Changes - изменившиеся поля задачи по именам полей в JSON.
+	Changes map[string]FieldChange `json:"changes"`
+}
+
+// Метод EventDTO преобразует запись журнала в TaskEventDTO, подставляя имена статусов.
+// В Changes попадают только поля, значения которых различаются до и после события.
+func (w Workflow) EventDTO(event TaskEvent) TaskEventDTO {
+	dto := TaskEventDTO{
+		ID:        event.ID,
+		TaskID:    event.TaskID,
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt.UTC().Format(time.RFC3339),
+		Reason:    event.Reason,
+		Changes:   map[string]FieldChange{},
+	}
+	if event.ActorID != 0 {
+		actorID := event.ActorID
+		dto.ActorID = &actorID
+	}
+
+	// fields возвращает значения полей снимка в представлении API.
+	fields := func(s *TaskSnapshot) map[string]interface{} {
+		if s == nil {
+			return map[string]interface{}{}
+		}
+		values := map[string]interface{}{
+			"text":         s.Text,
+			"createdDate":  s.CreatedDate,
+			"expectedDate": s.ExpectedDate,
+			"status":       w.Name(s.Status),
+			"projectId":    nil,
+			"priority":     s.Priority.String(),
+			"tags":         s.Tags,
+		}
+		if s.ProjectID != nil {
+			values["projectId"] = *s.ProjectID
+		}
+		return values
+	}
+	old, next := fields(event.Old), fields(event.New)
+	for _, field := range []string{"text", "createdDate", "expectedDate", "status", "projectId", "priority", "tags"} {
+		if old[field] != next[field] {
+			dto.Changes[field] = FieldChange{Old: old[field], New: next[field]}
+		}
+	}
+	// Метки сравниваются строкой, а в ответе, как и в задаче, передаются массивом.
+	if change, ok := dto.Changes["tags"]; ok {
+		dto.Changes["tags"] = FieldChange{Old: tagList(change.Old), New: tagList(change.New)}
+	}
+
+	switch {
+	case event.New != nil:
+		dto.Version = event.New.Version
+	case event.Old != nil:
+		dto.Version = event.Old.Version
+	}
+	return dto
+}
+
+// Функция tagList преобразует метки из снимка в массив; nil (снимка нет) остается nil.
+func tagList(value interface{}) interface{} {
+	joined, ok := value.(string)
+	if !ok {
+		return nil
+	}
+	if tags := splitTags(joined); tags != nil {
+		return tags
+	}
+	return []string{}
+}
+
+// Функция formatSnapshot сериализует снимок задачи для колонки old_values или new_values; nil - NULL.
+func formatSnapshot(snapshot *TaskSnapshot) (*string, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	value, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	text := string(value)
+	return &text, nil
+}
+
+// Функция parseSnapshot разбирает снимок задачи из колонки old_values или new_values.
+func parseSnapshot(value *string) (*TaskSnapshot, error) {
+	if value == nil {
+		return nil, nil
+	}
+	var snapshot TaskSnapshot
+	if err := json.Unmarshal([]byte(*value), &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для преобразования записи журнала: в изменения попадают только различающиеся поля.
+func TestEventDTO(t *testing.T) {
+	workflow := DefaultWorkflow()
+	at := time.Date(2023, 10, 1, 12, 30, 0, 0, time.UTC)
+	old := &TaskSnapshot{Text: "Task", CreatedDate: "2023-10-01",
+		ExpectedDate: "2023-10-02", Status: StatusTesting, Version: 2}
+	next := *old
+	next.Status, next.Version = StatusReturned, 3
+
+	assert.Equal(t, TaskEventDTO{
+		ID: 5, TaskID: 1, Type: EventStatusChanged, CreatedAt: "2023-10-01T12:30:00Z", Version: 3,
+		Changes: map[string]FieldChange{"status": {Old: "testing", New: "returned"}},
+	}, workflow.EventDTO(TaskEvent{ID: 5, TaskID: 1, Type: EventStatusChanged, Old: old, New: &next, CreatedAt: at}))
+
+	deleted := workflow.EventDTO(TaskEvent{ID: 6, TaskID: 1, Type: EventDeleted, Old: &next, CreatedAt: at})
+	assert.Equal(t, int64(3), deleted.Version)
+	assert.Equal(t, FieldChange{Old: "Task", New: nil}, deleted.Changes["text"])
+	assert.Equal(t, FieldChange{Old: "returned", New: nil}, deleted.Changes["status"])
+	assert.Equal(t, FieldChange{Old: []string{}, New: nil}, deleted.Changes["tags"])
+	assert.Len(t, deleted.Changes, 6)
+
+	// Пользователь и причина смены статуса передаются вместе с событием.
+	changed := workflow.EventDTO(TaskEvent{Type: EventStatusChanged, ActorID: 2, Reason: "Ревью", Old: old, New: &next})
+	if assert.NotNil(t, changed.ActorID) {
+		assert.Equal(t, int64(2), *changed.ActorID)
+	}
+	assert.Equal(t, "Ревью", changed.Reason)
+}
+
+// Тест для перемещения задачи между проектами: ID проекта сравнивается по значению, а не по указателю.
+func TestEventDTOProject(t *testing.T) {
+	workflow := DefaultWorkflow()
+	project, same := int64(3), int64(3)
+	old := &TaskSnapshot{Text: "Task", CreatedDate: "2023-10-01", ExpectedDate: "2023-10-02", Version: 1}
+	next := *old
+	next.ProjectID, next.Version = &project, 2
+
+	event := workflow.EventDTO(TaskEvent{Type: EventUpdated, Old: old, New: &next})
+	assert.Equal(t, map[string]FieldChange{"projectId": {Old: nil, New: int64(3)}}, event.Changes)
+
+	unchanged := next
+	unchanged.ProjectID = &same
+	assert.True(t, next.equal(&unchanged))
+	assert.False(t, old.equal(&next))
+}
+
+// Тест для смены приоритета в истории: приоритет выводится по имени, а снимки без него считаются обычными.
+func TestEventDTOPriority(t *testing.T) {
+	old := &TaskSnapshot{Text: "Task", CreatedDate: "2023-10-01", ExpectedDate: "2023-10-02", Version: 1}
+	next := *old
+	next.Priority, next.Version = PriorityUrgent, 2
+
+	event := DefaultWorkflow().EventDTO(TaskEvent{Type: EventUpdated, Old: old, New: &next})
+	assert.Equal(t, map[string]FieldChange{"priority": {Old: "normal", New: "urgent"}}, event.Changes)
+}
+
+// Тест для смены меток в истории: метки сравниваются строкой из снимка, а выводятся массивом.
+func TestEventDTOTags(t *testing.T) {
+	old := &TaskSnapshot{Text: "Task", CreatedDate: "2023-10-01", ExpectedDate: "2023-10-02", Tags: "bug", Version: 1}
+	next := *old
+	next.Tags, next.Version = "bug,ui", 2
+
+	event := DefaultWorkflow().EventDTO(TaskEvent{Type: EventUpdated, Old: old, New: &next})
+	assert.Equal(t, map[string]FieldChange{"tags": {Old: []string{"bug"}, New: []string{"bug", "ui"}}}, event.Changes)
+
+	next.Tags = ""
+	event = DefaultWorkflow().EventDTO(TaskEvent{Type: EventUpdated, Old: old, New: &next})
+	assert.Equal(t, map[string]FieldChange{"tags": {Old: []string{"bug"}, New: []string{}}}, event.Changes)
+}
@@ -0,0 +1,97 @@
+package db
+
+import "errors"
+
+// ErrListNotFound возвращается хранилищем, если список задач не открыт пользователю.
+// Чужой закрытый список считается несуществующим, чтобы не раскрывать его существование.
+var ErrListNotFound = errors.New("task list not found")
+
+// ErrMemberNotFound возвращается хранилищем, если пользователь не является участником списка.
+var ErrMemberNotFound = errors.New("list member not found")
+
+// Тип Role - роль участника общего списка задач.
+type Role string
+
+// Роли участников общего списка в порядке возрастания прав.
+const (
+	// RoleViewer разрешает только чтение задач списка.
+	RoleViewer Role = "viewer"
+	// RoleEditor разрешает еще и создание, изменение, удаление и восстановление задач.
+	RoleEditor Role = "editor"
+	// RoleAdmin разрешает еще и управление участниками списка. Автор списка всегда администратор.
+	RoleAdmin Role = "admin"
+)
+
+// Список всех ролей в порядке возрастания прав.
+var Roles = []Role{RoleViewer, RoleEditor, RoleAdmin}
+
+// Метод Valid сообщает, является ли значение известной ролью.
+func (r Role) Valid() bool {
+	return r.rank() > 0
+}
+
+// Метод Allows сообщает, дает ли роль права роли required.
+func (r Role) Allows(required Role) bool {
+	return r.Valid() && r.rank() >= required.rank()
+}
+
+// Метод rank возвращает номер роли в списке Roles, начиная с 1, или 0 для неизвестной роли.
+func (r Role) rank() int {
+	for i, role := range Roles {
+		if role == r {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Структура ListMember - участник общего списка задач пользователя OwnerID.
+type ListMember struct {
+	OwnerID  int64
+	UserID   int64
+	Username string
+	Role     Role
+}
+
+// Вспомогательная структура для сериализации ListMember.
+type ListMemberDTO struct {
+	UserID   int64  `json:"userId"`
+	Username string `json:"username"`
+	Role     Role   `json:"role"`
+}
+
+// Метод для преобразования ListMember в ListMemberDTO.
+func (m *ListMember) ToDTO() ListMemberDTO {
+	return ListMemberDTO{UserID: m.UserID, Username: m.Username, Role: m.Role}
+}
+
+// Структура ListMemberRequest - тело запроса на открытие списка пользователю или смену его роли.
+type ListMemberRequest struct {
+	Role Role `json:"role"`
+}
+
+// Структура TaskList - список задач, доступный пользователю: его собственный или открытый ему другим.
+// Список идентифицируется ID своего автора.
+type TaskList struct {
+	OwnerID   int64  `json:"id"`
+	OwnerName string `json:"owner"`
+	Role      Role   `json:"role"`
+}
+
+// Интерфейс ListStore описывает хранилище участников общих списков задач.
+// Каждый пользователь - автор своего списка задач и может открыть его другим пользователям.
+type ListStore interface {
+	// GetListRole возвращает роль пользователя user в списке задач пользователя owner.
+	// Автору списка возвращается RoleAdmin, а пользователю, которому список не открыт, - ErrListNotFound.
+	GetListRole(owner, user int64) (Role, error)
+	// GetTaskLists возвращает списки задач, доступные пользователю: первым - его собственный,
+	// затем открытые ему другими пользователями в порядке имен их авторов.
+	GetTaskLists(user int64) ([]TaskList, error)
+	// GetListMembers возвращает участников списка задач пользователя owner в порядке имен.
+	GetListMembers(owner int64) ([]ListMember, error)
+	// SetListMember добавляет пользователя user в список задач пользователя owner или меняет его роль.
+	SetListMember(owner, user int64, role Role) error
+	// DeleteListMember закрывает список задач пользователя owner для пользователя user
+	// или возвращает ErrMemberNotFound.
+	DeleteListMember(owner, user int64) error
+}
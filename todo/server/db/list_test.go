@@ -0,0 +1,18 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для сравнения прав ролей участников списка.
+func TestRoleAllows(t *testing.T) {
+	assert.True(t, RoleAdmin.Allows(RoleEditor))
+	assert.True(t, RoleEditor.Allows(RoleEditor))
+	assert.True(t, RoleEditor.Allows(RoleViewer))
+	assert.False(t, RoleViewer.Allows(RoleEditor))
+	assert.False(t, RoleEditor.Allows(RoleAdmin))
+	assert.False(t, Role("owner").Allows(RoleViewer))
+	assert.False(t, Role("").Valid())
+}
@@ -0,0 +1,842 @@
+package db
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Структура MemoryStore реализует TaskStore, храня задачи в памяти процесса.
+// Подходит для локальной разработки и тестов, данные теряются при перезапуске.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	tasks       map[int64]Task
+	nextID      int64
+	workflows   Workflows
+	events      []TaskEvent
+	users       map[int64]User
+	sessions    map[string]Session
+	tokens      map[int64]APIToken
+	nextTokenID int64
+	members     map[listMemberKey]Role
+	projects    map[int64]Project
+	nextProject int64
+}
+
+// Ключ участника общего списка в хранилище в памяти: автор списка и участник.
+type listMemberKey struct {
+	owner, user int64
+}
+
+// Функция NewMemoryStore создает пустое хранилище задач в памяти со встроенным рабочим процессом.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tasks:       make(map[int64]Task),
+		nextID:      1,
+		workflows:   Workflows{Default: DefaultWorkflow(), Projects: make(map[int64]Workflow)},
+		users:       make(map[int64]User),
+		sessions:    make(map[string]Session),
+		tokens:      make(map[int64]APIToken),
+		nextTokenID: 1,
+		members:     make(map[listMemberKey]Role),
+		projects:    make(map[int64]Project),
+		nextProject: 1,
+	}
+}
+
+// Метод GetAllTasks возвращает страницу задач пользователя по тем же правилам фильтрации, сортировки
+// и пагинации, что и PostgresStore.
+func (s *MemoryStore) GetAllTasks(q TaskQuery) (TaskPage, error) {
+	if err := q.validate(); err != nil {
+		return TaskPage{}, err
+	}
+
+	statuses, filterStatus, err := q.statusFilter()
+	if err != nil {
+		return TaskPage{}, err
+	}
+	project, filterProject, err := q.projectFilter()
+	if err != nil {
+		return TaskPage{}, err
+	}
+
+	var search searchQuery
+	if q.searching() {
+		search = parseSearch(q.Search)
+	}
+
+	var after *Task
+	if q.After != "" {
+		task, err := decodeCursor(q.After, q)
+		if err != nil {
+			return TaskPage{}, err
+		}
+		after = &task
+	}
+
+	// less сравнивает задачи по ключам сортировки по очереди; последний ключ - ID.
+	keys := q.orderKeys()
+	less := func(a, b Task) bool {
+		for _, key := range keys {
+			if c := compareTasks(a, b, key.Field); c != 0 {
+				return (c > 0) == key.Desc
+			}
+		}
+		return false
+	}
+
+	s.mu.RLock()
+	var tasks []Task
+	for _, task := range s.tasks {
+		if !task.ownedBy(q.Owner) || (task.DeletedAt != nil) != q.Deleted {
+			continue
+		}
+		if filterStatus && !slices.Contains(statuses, task.Status) {
+			continue
+		}
+		if filterProject && task.ProjectID != project {
+			continue
+		}
+		if len(q.Tags) > 0 && !task.hasTags(q.Tags, q.matchAllTags()) {
+			continue
+		}
+		if q.searching() {
+			if task.Rank = search.rank(task.Text); task.Rank == 0 {
+				continue
+			}
+		}
+		if after != nil && !less(*after, task) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	s.mu.RUnlock()
+
+	// Время удаления, как и в PostgresStore, возвращается только при выборке из корзины.
+	if !q.Deleted {
+		for i := range tasks {
+			tasks[i].DeletedAt = nil
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return less(tasks[i], tasks[j]) })
+
+	if q.Limit > 0 && len(tasks) > q.Limit+1 {
+		tasks = tasks[:q.Limit+1]
+	}
+
+	return newTaskPage(tasks, q), nil
+}
+
+// Метод GetTaskByID возвращает задачу пользователя по её идентификатору, не считая задач из корзины.
+func (s *MemoryStore) GetTaskByID(owner int64, id int) (Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, ok := s.tasks[int64(id)]
+	if !ok || !task.ownedBy(owner) || task.DeletedAt != nil {
+		return Task{}, &NotFoundError{ID: int64(id)}
+	}
+	return task, nil
+}
+
+// Метод CreateTask сохраняет новую задачу и возвращает её ID.
+func (s *MemoryStore) CreateTask(task Task) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task.ID = s.nextID
+	task.Version = 1
+	task.Tags = cloneTags(task.Tags)
+	s.nextID++
+	s.record(task, EventCreated, nil, snapshotOf(task))
+	s.store(task)
+
+	return task.ID, nil
+}
+
+// Метод UpdateTask обновляет существующую задачу пользователя task.OwnerID, проверяя её версию,
+// и возвращает новую версию.
+func (s *MemoryStore) UpdateTask(task Task) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.tasks[task.ID]
+	if !ok || !current.ownedBy(task.OwnerID) || current.DeletedAt != nil {
+		return 0, &NotFoundError{ID: task.ID}
+	}
+	if task.Version != 0 && task.Version != current.Version {
+		return 0, ErrVersionConflict
+	}
+	task.Version = current.Version + 1
+	task.DeletedAt = nil
+	if task.Tags == nil {
+		task.Tags = current.Tags
+	} else {
+		task.Tags = cloneTags(task.Tags)
+	}
+	s.store(task)
+
+	// Как и в базе данных, журнал не пополняется, если поля задачи не изменились.
+	if eventType := changeEventType(current, task); eventType != "" {
+		s.record(task, eventType, snapshotOf(current), snapshotOf(task))
+	}
+
+	return task.Version, nil
+}
+
+// Метод DeleteTask перемещает задачу пользователя в корзину, проверяя её версию.
+func (s *MemoryStore) DeleteTask(owner, actor int64, id int, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.tasks[int64(id)]
+	if !ok || !current.ownedBy(owner) || current.DeletedAt != nil {
+		return &NotFoundError{ID: int64(id)}
+	}
+	if version != 0 && version != current.Version {
+		return ErrVersionConflict
+	}
+	deleted := current
+	deletedAt := time.Now().UTC()
+	deleted.DeletedAt = &deletedAt
+	deleted.Version++
+	s.tasks[deleted.ID] = deleted
+	current.EditorID = actor
+	s.record(current, EventDeleted, snapshotOf(current), nil)
+
+	return nil
+}
+
+// Метод RestoreTask возвращает задачу пользователя из корзины и увеличивает её версию.
+func (s *MemoryStore) RestoreTask(owner, actor int64, id int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[int64(id)]
+	if !ok || !task.ownedBy(owner) || task.DeletedAt == nil {
+		return 0, &NotFoundError{ID: int64(id)}
+	}
+	task.DeletedAt = nil
+	task.Version++
+	s.tasks[task.ID] = task
+	task.EditorID = actor
+	s.record(task, EventRestored, nil, snapshotOf(task))
+
+	return task.Version, nil
+}
+
+// Метод PurgeDeletedTasks окончательно удаляет задачи, перемещенные в корзину раньше before.
+func (s *MemoryStore) PurgeDeletedTasks(before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, task := range s.tasks {
+		if task.DeletedAt != nil && task.DeletedAt.Before(before) {
+			delete(s.tasks, id)
+			s.record(task, EventPurged, snapshotOf(task), nil)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// Метод ClaimOwnerlessTasks передает пользователю owner задачи без автора и их события журнала.
+func (s *MemoryStore) ClaimOwnerlessTasks(owner int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[owner]; !ok {
+		return 0, ErrUserNotFound
+	}
+	var claimed int64
+	for id, task := range s.tasks {
+		if task.OwnerID == 0 {
+			task.OwnerID = owner
+			s.tasks[id] = task
+			claimed++
+		}
+	}
+	for i := range s.events {
+		if s.events[i].OwnerID == 0 {
+			s.events[i].OwnerID = owner
+		}
+	}
+	return claimed, nil
+}
+
+// Метод GetTaskHistory возвращает журнал изменений задачи пользователя.
+// Как и в базе данных, автор задачи определяется по записям журнала.
+func (s *MemoryStore) GetTaskHistory(owner int64, id int) ([]TaskEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := []TaskEvent{}
+	for _, event := range s.events {
+		if event.TaskID == int64(id) && owner != 0 && event.OwnerID == owner {
+			events = append(events, event)
+		}
+	}
+	if task, ok := s.tasks[int64(id)]; (!ok || !task.ownedBy(owner)) && len(events) == 0 {
+		return nil, &NotFoundError{ID: int64(id)}
+	}
+	return events, nil
+}
+
+// Метод ownedBy сообщает, принадлежит ли задача пользователю owner. Как и в базе данных,
+// где у таких задач owner_id равен NULL, задачи без автора не принадлежат никому.
+func (t Task) ownedBy(owner int64) bool {
+	return owner != 0 && t.OwnerID == owner
+}
+
+// Функция cloneTags копирует метки задачи в порядке имен, в котором их возвращает и PostgresStore.
+func cloneTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	tags = slices.Clone(tags)
+	slices.Sort(tags)
+	return tags
+}
+
+// Метод hasTags сообщает, есть ли у задачи все метки names (если all установлен) или хотя бы одна из них.
+func (t Task) hasTags(names []string, all bool) bool {
+	for _, name := range names {
+		if slices.Contains(t.Tags, name) != all {
+			return !all
+		}
+	}
+	return all
+}
+
+// Метод store сохраняет задачу без полей, которые, как и в базе данных, из хранилища не считываются.
+// Вызывается под блокировкой на запись.
+func (s *MemoryStore) store(task Task) {
+	task.EditorID, task.Reason = 0, ""
+	s.tasks[task.ID] = task
+}
+
+// Метод record добавляет событие задачи task в журнал изменений от имени пользователя task.EditorID
+// с причиной task.Reason. Вызывается под блокировкой на запись.
+func (s *MemoryStore) record(task Task, eventType string, old, next *TaskSnapshot) {
+	event := newTaskEvent(task, eventType, old, next)
+	event.ID = int64(len(s.events) + 1)
+	event.CreatedAt = time.Now().UTC()
+	s.events = append(s.events, event)
+}
+
+// Метод GetWorkflows возвращает копию рабочих процессов.
+func (s *MemoryStore) GetWorkflows() (Workflows, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.workflows.clone(), nil
+}
+
+// Метод GetWorkflow возвращает копию рабочего процесса задач проекта.
+func (s *MemoryStore) GetWorkflow(project int64) (Workflow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.workflows.For(project).clone(), nil
+}
+
+// Метод CreateStatus добавляет статус в рабочий процесс проекта с номером, следующим за наибольшим
+// из существующих во всех рабочих процессах.
+func (s *MemoryStore) CreateStatus(project int64, def StatusDef) (Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workflow, copies := s.projectWorkflow(project)
+	if _, ok := workflow.Lookup(def.Name); ok {
+		return 0, ErrStatusExists
+	}
+
+	def.ID = max(s.nextStatus(), nextStatusAfter(workflow))
+	def.Transitions = knownStatuses(workflow, def.Transitions)
+	workflow.Statuses = append(workflow.Statuses, def)
+	workflow.sort()
+	s.setWorkflow(project, workflow, copies)
+
+	return def.ID, nil
+}
+
+// Метод UpdateStatus изменяет статус с именем def.Name в рабочем процессе проекта и заменяет его переходы.
+func (s *MemoryStore) UpdateStatus(project int64, def StatusDef) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workflow, copies := s.projectWorkflow(project)
+	i := slices.IndexFunc(workflow.Statuses, func(existing StatusDef) bool { return existing.Name == def.Name })
+	if i < 0 {
+		return ErrStatusNotFound
+	}
+
+	def.ID = workflow.Statuses[i].ID
+	def.Transitions = knownStatuses(workflow, def.Transitions)
+	workflow.Statuses[i] = def
+	workflow.sort()
+	s.setWorkflow(project, workflow, copies)
+
+	return nil
+}
+
+// Метод DeleteStatus удаляет статус рабочего процесса проекта, если в нем нет задач, вместе с переходами в него.
+func (s *MemoryStore) DeleteStatus(project int64, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workflow, copies := s.projectWorkflow(project)
+	i := slices.IndexFunc(workflow.Statuses, func(existing StatusDef) bool { return existing.Name == name })
+	if i < 0 {
+		return ErrStatusNotFound
+	}
+	for _, task := range s.tasks {
+		if copyStatus(task, project, copies) == workflow.Statuses[i].ID {
+			return ErrStatusInUse
+		}
+	}
+
+	workflow.Statuses = slices.Delete(workflow.Statuses, i, i+1)
+	for j := range workflow.Statuses {
+		def := &workflow.Statuses[j]
+		def.Transitions = slices.DeleteFunc(def.Transitions, func(next string) bool { return next == name })
+	}
+	s.setWorkflow(project, workflow, copies)
+
+	return nil
+}
+
+// Метод projectWorkflow возвращает копию рабочего процесса проекта project для изменения. Проекту
+// без собственного рабочего процесса, как и в PostgresStore, достается копия рабочего процесса
+// по умолчанию с новыми номерами статусов; copies сопоставляет номерам исходных статусов номера копий
+// (nil, если копировать не пришлось). Вызывается под блокировкой на запись.
+func (s *MemoryStore) projectWorkflow(project int64) (Workflow, map[Status]Status) {
+	if project == 0 {
+		return s.workflows.Default.clone(), nil
+	}
+	if workflow, ok := s.workflows.Projects[project]; ok {
+		return workflow.clone(), nil
+	}
+
+	workflow := s.workflows.Default.clone()
+	copies := make(map[Status]Status, len(workflow.Statuses))
+	next := s.nextStatus()
+	for i := range workflow.Statuses {
+		copies[workflow.Statuses[i].ID] = next
+		workflow.Statuses[i].ID = next
+		next++
+	}
+	return workflow, copies
+}
+
+// Метод setWorkflow сохраняет измененный рабочий процесс проекта project (0 - по умолчанию)
+// и переводит задачи проекта в статусы-копии copies. Вызывается под блокировкой на запись.
+func (s *MemoryStore) setWorkflow(project int64, workflow Workflow, copies map[Status]Status) {
+	if project == 0 {
+		s.workflows.Default = workflow
+		return
+	}
+	s.workflows.Projects[project] = workflow
+	for id, task := range s.tasks {
+		task.Status = copyStatus(task, project, copies)
+		s.tasks[id] = task
+	}
+}
+
+// Функция copyStatus возвращает статус задачи task после копирования рабочего процесса проекта project.
+func copyStatus(task Task, project int64, copies map[Status]Status) Status {
+	if status, ok := copies[task.Status]; ok && task.ProjectID == project {
+		return status
+	}
+	return task.Status
+}
+
+// Метод nextStatus возвращает номер, следующий за наибольшим номером статуса во всех рабочих процессах.
+func (s *MemoryStore) nextStatus() Status {
+	return nextStatusAfter(s.workflows.All())
+}
+
+// Функция nextStatusAfter возвращает номер, следующий за наибольшим номером статуса рабочего процесса.
+func nextStatusAfter(workflow Workflow) Status {
+	var next Status
+	for _, def := range workflow.Statuses {
+		next = max(next, def.ID+1)
+	}
+	return next
+}
+
+// Функция knownStatuses оставляет в списке только имена статусов рабочего процесса workflow,
+// как и PostgresStore.
+func knownStatuses(workflow Workflow, names []string) []string {
+	known := []string{}
+	for _, name := range names {
+		if def, ok := workflow.Lookup(name); ok && def.Name == name {
+			known = append(known, name)
+		}
+	}
+	return known
+}
+
+// Метод CreateUser добавляет пользователя, если его имя не занято.
+func (s *MemoryStore) CreateUser(user User) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.users {
+		if existing.Username == user.Username {
+			return 0, ErrUserExists
+		}
+	}
+	user.ID = int64(len(s.users) + 1)
+	user.CreatedAt = time.Now().UTC()
+	s.users[user.ID] = user
+
+	return user.ID, nil
+}
+
+// Метод GetUserByName возвращает пользователя по имени.
+func (s *MemoryStore) GetUserByName(username string) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return User{}, ErrUserNotFound
+}
+
+// Метод CreateSession сохраняет сессию и удаляет истекшие сессии того же пользователя.
+func (s *MemoryStore) CreateSession(session Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for hash, existing := range s.sessions {
+		if existing.UserID == session.UserID && !existing.ExpiresAt.After(now) {
+			delete(s.sessions, hash)
+		}
+	}
+	s.sessions[session.TokenHash] = session
+
+	return nil
+}
+
+// Метод GetSessionUser возвращает пользователя сессии, действующей на момент now.
+func (s *MemoryStore) GetSessionUser(tokenHash string, now time.Time) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[tokenHash]
+	if !ok || !session.ExpiresAt.After(now) {
+		return User{}, ErrSessionNotFound
+	}
+	user, ok := s.users[session.UserID]
+	if !ok {
+		return User{}, ErrSessionNotFound
+	}
+	return user, nil
+}
+
+// Метод DeleteSession удаляет сессию.
+func (s *MemoryStore) DeleteSession(tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, tokenHash)
+	return nil
+}
+
+// Метод CreateAPIToken сохраняет токен пользователя.
+func (s *MemoryStore) CreateAPIToken(token APIToken) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token.ID = s.nextTokenID
+	s.nextTokenID++
+	token.Scopes = slices.Clone(token.Scopes)
+	token.LastUsedAt = nil
+	token.CreatedAt = time.Now().UTC()
+	s.tokens[token.ID] = token
+
+	return token.ID, nil
+}
+
+// Метод GetAPITokens возвращает токены пользователя в порядке создания.
+func (s *MemoryStore) GetAPITokens(userID int64) ([]APIToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tokens := []APIToken{}
+	for _, token := range s.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, token)
+		}
+	}
+	slices.SortFunc(tokens, func(a, b APIToken) int { return cmp.Compare(a.ID, b.ID) })
+	return tokens, nil
+}
+
+// Метод DeleteAPIToken удаляет токен пользователя. Чужой токен считается несуществующим.
+func (s *MemoryStore) DeleteAPIToken(userID, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[id]
+	if !ok || token.UserID != userID {
+		return ErrTokenNotFound
+	}
+	delete(s.tokens, id)
+	return nil
+}
+
+// Метод GetTokenUser возвращает владельца действующего токена и отмечает время его использования.
+func (s *MemoryStore) GetTokenUser(tokenHash string, now time.Time) (User, APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, token := range s.tokens {
+		if token.TokenHash != tokenHash || !token.ExpiresAt.After(now) {
+			continue
+		}
+		user, ok := s.users[token.UserID]
+		if !ok {
+			break
+		}
+		usedAt := now.UTC()
+		token.LastUsedAt = &usedAt
+		s.tokens[id] = token
+		return user, token, nil
+	}
+	return User{}, APIToken{}, ErrTokenNotFound
+}
+
+// Метод GetListRole возвращает роль пользователя в списке задач; автор списка - администратор.
+func (s *MemoryStore) GetListRole(owner, user int64) (Role, error) {
+	if owner != 0 && owner == user {
+		return RoleAdmin, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	role, ok := s.members[listMemberKey{owner: owner, user: user}]
+	if !ok {
+		return "", ErrListNotFound
+	}
+	return role, nil
+}
+
+// Метод GetTaskLists возвращает собственный список пользователя и открытые ему списки.
+func (s *MemoryStore) GetTaskLists(user int64) ([]TaskList, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lists := []TaskList{}
+	if owner, ok := s.users[user]; ok {
+		lists = append(lists, TaskList{OwnerID: owner.ID, OwnerName: owner.Username, Role: RoleAdmin})
+	}
+	var shared []TaskList
+	for key, role := range s.members {
+		if key.user == user {
+			shared = append(shared, TaskList{OwnerID: key.owner, OwnerName: s.users[key.owner].Username, Role: role})
+		}
+	}
+	slices.SortFunc(shared, func(a, b TaskList) int { return strings.Compare(a.OwnerName, b.OwnerName) })
+	return append(lists, shared...), nil
+}
+
+// Метод GetListMembers возвращает участников списка задач в порядке имен.
+func (s *MemoryStore) GetListMembers(owner int64) ([]ListMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	members := []ListMember{}
+	for key, role := range s.members {
+		if key.owner == owner {
+			members = append(members, ListMember{
+				OwnerID: owner, UserID: key.user, Username: s.users[key.user].Username, Role: role,
+			})
+		}
+	}
+	slices.SortFunc(members, func(a, b ListMember) int { return strings.Compare(a.Username, b.Username) })
+	return members, nil
+}
+
+// Метод SetListMember добавляет участника списка или меняет его роль.
+func (s *MemoryStore) SetListMember(owner, user int64, role Role) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.members[listMemberKey{owner: owner, user: user}] = role
+	return nil
+}
+
+// Метод DeleteListMember удаляет участника списка.
+func (s *MemoryStore) DeleteListMember(owner, user int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := listMemberKey{owner: owner, user: user}
+	if _, ok := s.members[key]; !ok {
+		return ErrMemberNotFound
+	}
+	delete(s.members, key)
+	return nil
+}
+
+// Функция compareTasks сравнивает две задачи по полю из белого списка validSortFields или по релевантности.
+func compareTasks(a, b Task, sortField string) int {
+	switch sortField {
+	case "id":
+		return cmp.Compare(a.ID, b.ID)
+	case "task_text":
+		return strings.Compare(a.Text, b.Text)
+	case "createdDate":
+		return a.CreatedDate.Compare(b.CreatedDate)
+	case "expectedDate":
+		return a.ExpectedDate.Compare(b.ExpectedDate)
+	case "status":
+		return cmp.Compare(a.Status, b.Status)
+	case "priority":
+		return cmp.Compare(a.Priority, b.Priority)
+	case "rank":
+		return cmp.Compare(a.Rank, b.Rank)
+	}
+	return 0
+}
+
+// Метод GetProjects возвращает проекты пользователя в порядке имен.
+func (s *MemoryStore) GetProjects(owner int64) ([]Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects := []Project{}
+	for _, project := range s.projects {
+		if project.OwnerID == owner {
+			projects = append(projects, project)
+		}
+	}
+	slices.SortFunc(projects, func(a, b Project) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return projects, nil
+}
+
+// Метод GetProject возвращает проект пользователя по идентификатору.
+func (s *MemoryStore) GetProject(owner, id int64) (Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	project, ok := s.projects[id]
+	if !ok || project.OwnerID != owner {
+		return Project{}, ErrProjectNotFound
+	}
+	return project, nil
+}
+
+// Метод CreateProject сохраняет новый проект, если его имя не занято, и возвращает его ID.
+func (s *MemoryStore) CreateProject(project Project) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.projectNameTaken(project) {
+		return 0, ErrProjectExists
+	}
+	project.ID = s.nextProject
+	project.CreatedAt = time.Now().UTC()
+	s.nextProject++
+	s.projects[project.ID] = project
+	return project.ID, nil
+}
+
+// Метод UpdateProject переименовывает проект, если имя не занято другим проектом пользователя.
+func (s *MemoryStore) UpdateProject(project Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.projects[project.ID]
+	if !ok || current.OwnerID != project.OwnerID {
+		return ErrProjectNotFound
+	}
+	if s.projectNameTaken(project) {
+		return ErrProjectExists
+	}
+	current.Name = project.Name
+	s.projects[current.ID] = current
+	return nil
+}
+
+// Метод DeleteProject удаляет проект, убирая из него задачи, как и в базе данных, - с новой версией
+// и записью в журнале изменений.
+func (s *MemoryStore) DeleteProject(owner, actor, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.projects[id]
+	if !ok || project.OwnerID != owner {
+		return ErrProjectNotFound
+	}
+	for _, task := range s.tasks {
+		if task.ProjectID != id || !task.ownedBy(owner) {
+			continue
+		}
+		updated := task
+		updated.ProjectID = 0
+		updated.Status = s.workflows.moveStatus(task.Status, 0)
+		updated.Version++
+		s.tasks[task.ID] = updated
+		updated.EditorID = actor
+		s.record(updated, changeEventType(task, updated), snapshotOf(task), snapshotOf(updated))
+	}
+	delete(s.projects, id)
+	delete(s.workflows.Projects, id)
+	return nil
+}
+
+// Метод projectNameTaken сообщает, занято ли имя проекта другим проектом того же пользователя.
+// Вызывается под блокировкой.
+func (s *MemoryStore) projectNameTaken(project Project) bool {
+	for _, other := range s.projects {
+		if other.OwnerID == project.OwnerID && other.Name == project.Name && other.ID != project.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// Метод GetTags возвращает метки пользователя по префиксу имени, подсчитывая задачи, которым они назначены.
+func (s *MemoryStore) GetTags(owner int64, prefix string, limit int) ([]Tag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, task := range s.tasks {
+		if !task.ownedBy(owner) || task.DeletedAt != nil {
+			continue
+		}
+		for _, name := range task.Tags {
+			if strings.HasPrefix(name, prefix) {
+				counts[name]++
+			}
+		}
+	}
+
+	tags := []Tag{}
+	for name, count := range counts {
+		tags = append(tags, Tag{Name: name, Count: count})
+	}
+	slices.SortFunc(tags, func(a, b Tag) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Name, b.Name))
+	})
+	if len(tags) > limit {
+		tags = tags[:limit]
+	}
+	return tags, nil
+}
@@ -0,0 +1,204 @@
+package db
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Функция newTestMemoryStore создает хранилище в памяти с тремя задачами.
+func newTestMemoryStore(t *testing.T) *MemoryStore {
+	t.Helper()
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	for _, task := range []Task{
+		{Text: "B", CreatedDate: day, ExpectedDate: day.AddDate(0, 0, 3), Status: StatusInProgress},
+		{Text: "C", CreatedDate: day, ExpectedDate: day.AddDate(0, 0, 1), Status: StatusCompleted},
+		{Text: "A", CreatedDate: day, ExpectedDate: day.AddDate(0, 0, 2), Status: StatusInProgress},
+	} {
+		task.OwnerID = testOwner
+		_, err := store.CreateTask(task)
+		assert.NoError(t, err)
+	}
+	return store
+}
+
+// Функция taskIDs возвращает идентификаторы задач в порядке следования.
+func taskIDs(tasks []Task) []int64 {
+	ids := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+// Тест для метода GetAllTasks хранилища в памяти.
+func TestMemoryStoreGetAllTasks(t *testing.T) {
+	testCases := []struct {
+		name         string
+		statusFilter string
+		sortOrder    string
+		sortField    string
+		expectedIDs  []int64
+	}{
+		{name: "Получить все задачи", expectedIDs: []int64{2, 3, 1}},
+		{name: "Фильтрация по статусу", statusFilter: "0", expectedIDs: []int64{3, 1}},
+		{name: "Сортировка по ID", sortField: "id", expectedIDs: []int64{1, 2, 3}},
+		{name: "Сортировка по тексту", sortField: "task_text", expectedIDs: []int64{3, 1, 2}},
+		{name: "Сортировка по убыванию даты", sortField: "expectedDate", sortOrder: "desc", expectedIDs: []int64{1, 3, 2}},
+		{
+			name:         "Фильтрация и сортировка",
+			statusFilter: "0",
+			sortField:    "expectedDate",
+			expectedIDs:  []int64{3, 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newTestMemoryStore(t)
+
+			page, err := store.GetAllTasks(TaskQuery{Owner: testOwner, Status: tc.statusFilter, SortOrder: tc.sortOrder,
+				SortField: tc.sortField})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedIDs, taskIDs(page.Tasks))
+		})
+	}
+}
+
+// Тест для некорректных параметров GetAllTasks хранилища в памяти.
+func TestMemoryStoreGetAllTasksInvalidParams(t *testing.T) {
+	store := newTestMemoryStore(t)
+
+	_, err := store.GetAllTasks(TaskQuery{Owner: testOwner, SortField: "DROP TABLE tasks"})
+	assert.Error(t, err)
+
+	_, err = store.GetAllTasks(TaskQuery{Owner: testOwner, Status: "завершено"})
+	assert.Error(t, err)
+}
+
+// Тест для методов GetTaskByID, UpdateTask и DeleteTask хранилища в памяти.
+func TestMemoryStoreUpdateAndDelete(t *testing.T) {
+	store := newTestMemoryStore(t)
+
+	page, err := store.GetAllTasks(TaskQuery{Owner: testOwner})
+	assert.NoError(t, err)
+	task := page.Tasks[0]
+	task.Text = "Updated"
+	version, err := store.UpdateTask(task)
+	assert.NoError(t, err)
+	assert.Equal(t, task.Version+1, version)
+
+	found, err := store.GetTaskByID(testOwner, int(task.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, "Updated", found.Text)
+
+	assert.NoError(t, store.DeleteTask(testOwner, testOwner, int(task.ID), 0))
+	_, err = store.GetTaskByID(testOwner, int(task.ID))
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.DeleteTask(testOwner, testOwner, int(task.ID), 0), ErrNotFound)
+	_, err = store.UpdateTask(task)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// Тест для конкурентного доступа к хранилищу в памяти.
+func TestMemoryStoreConcurrentAccess(t *testing.T) {
+	store := NewMemoryStore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := store.CreateTask(Task{OwnerID: testOwner, Text: "Task"})
+			assert.NoError(t, err)
+			_, err = store.UpdateTask(Task{OwnerID: testOwner, ID: id, Text: "Updated"})
+			assert.NoError(t, err)
+			_, err = store.GetAllTasks(TaskQuery{Owner: testOwner, SortField: "id"})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	page, err := store.GetAllTasks(TaskQuery{Owner: testOwner})
+	assert.NoError(t, err)
+	assert.Len(t, page.Tasks, 50)
+}
+
+// Тест для пагинации хранилища в памяти.
+func TestMemoryStorePagination(t *testing.T) {
+	testPagination(t, NewMemoryStore())
+}
+
+// Тест для полнотекстового поиска хранилища в памяти.
+func TestMemoryStoreSearch(t *testing.T) {
+	testSearch(t, NewMemoryStore())
+}
+
+// Тест для оптимистичной блокировки хранилища в памяти.
+func TestMemoryStoreVersioning(t *testing.T) {
+	testVersioning(t, NewMemoryStore())
+}
+
+// Тест для статусов рабочего процесса в хранилище в памяти.
+func TestMemoryStoreWorkflow(t *testing.T) {
+	testWorkflow(t, NewMemoryStore())
+}
+
+// Тест для журнала изменений задач в хранилище в памяти.
+func TestMemoryStoreHistory(t *testing.T) {
+	testHistory(t, NewMemoryStore())
+}
+
+// Тест для корзины хранилища в памяти.
+func TestMemoryStoreTrash(t *testing.T) {
+	testTrash(t, NewMemoryStore())
+}
+
+// Тест для пользователей и сессий в хранилище в памяти.
+func TestMemoryStoreUsers(t *testing.T) {
+	testUsers(t, NewMemoryStore())
+}
+
+// Тест для изоляции задач пользователей в хранилище в памяти.
+func TestMemoryStoreIsolation(t *testing.T) {
+	testIsolation(t, NewMemoryStore())
+}
+
+// Тест для персональных токенов доступа в хранилище в памяти.
+func TestMemoryStoreTokens(t *testing.T) {
+	testTokens(t, NewMemoryStore())
+}
+
+// Тест для участников общих списков задач в хранилище в памяти.
+func TestMemoryStoreLists(t *testing.T) {
+	testLists(t, NewMemoryStore())
+}
+
+// Тест для проектов в хранилище в памяти.
+func TestMemoryStoreProjects(t *testing.T) {
+	testProjects(t, NewMemoryStore())
+}
+
+// Тест для меток задач в хранилище в памяти.
+func TestMemoryStoreTags(t *testing.T) {
+	testTags(t, NewMemoryStore())
+}
+
+// Тест для приоритета задач в хранилище в памяти.
+func TestMemoryStorePriority(t *testing.T) {
+	testPriority(t, NewMemoryStore())
+}
+
+// Тест для передачи задач без автора пользователю в хранилище в памяти.
+func TestMemoryStoreOwnerlessTasks(t *testing.T) {
+	testOwnerlessTasks(t, NewMemoryStore())
+}
+
+// Тест для собственных рабочих процессов проектов в хранилище в памяти.
+func TestMemoryStoreProjectWorkflows(t *testing.T) {
+	testProjectWorkflows(t, NewMemoryStore())
+}
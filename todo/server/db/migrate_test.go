@@ -0,0 +1,111 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для загрузки миграций из каталога.
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_add_column.up.sql":   {Data: []byte("ALTER TABLE t ADD COLUMN c INTEGER;")},
+		"m/0002_add_column.down.sql": {Data: []byte("ALTER TABLE t DROP COLUMN c;")},
+		"m/0001_create.up.sql":       {Data: []byte("CREATE TABLE t (id INTEGER);")},
+		"m/0001_create.down.sql":     {Data: []byte("DROP TABLE t;")},
+	}
+
+	migrations, err := LoadMigrations(fsys, "m")
+
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "create", migrations[0].Name)
+	assert.Equal(t, 2, migrations[1].Version)
+	assert.Equal(t, "ALTER TABLE t DROP COLUMN c;", migrations[1].Down)
+}
+
+// Тест для некорректных наборов файлов миграций.
+func TestLoadMigrationsInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{
+			name: "Нет файла down",
+			fsys: fstest.MapFS{"m/0001_create.up.sql": {Data: []byte("CREATE TABLE t (id INTEGER);")}},
+		},
+		{
+			name: "Неверное имя файла",
+			fsys: fstest.MapFS{"m/create.sql": {Data: []byte("CREATE TABLE t (id INTEGER);")}},
+		},
+		{
+			name: "Разные имена у одной версии",
+			fsys: fstest.MapFS{
+				"m/0001_create.up.sql":  {Data: []byte("CREATE TABLE t (id INTEGER);")},
+				"m/0001_other.down.sql": {Data: []byte("DROP TABLE t;")},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadMigrations(tc.fsys, "m")
+			assert.Error(t, err)
+		})
+	}
+}
+
+// Тест для согласованности встроенных миграций PostgreSQL и SQLite.
+func TestEmbeddedMigrationsMatch(t *testing.T) {
+	postgres, err := LoadMigrations(migrationsFS, "migrations/postgres")
+	assert.NoError(t, err)
+	sqlite, err := LoadMigrations(migrationsFS, "migrations/sqlite")
+	assert.NoError(t, err)
+
+	assert.Equal(t, len(postgres), len(sqlite))
+	for i := range postgres {
+		assert.Equal(t, postgres[i].Version, sqlite[i].Version)
+		assert.Equal(t, postgres[i].Name, sqlite[i].Name)
+	}
+}
+
+// Тест для применения и отката миграций на базе SQLite.
+func TestMigratorUpDown(t *testing.T) {
+	conn, err := sql.Open("sqlite", ":memory:")
+	assert.NoError(t, err)
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	migrator := NewMigrator(conn, "sqlite")
+
+	applied, err := migrator.Up()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, applied)
+
+	// Повторный запуск не применяет миграции второй раз.
+	applied, err = migrator.Up()
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	statuses, err := migrator.Status()
+	assert.NoError(t, err)
+	for _, s := range statuses {
+		assert.NotNil(t, s.AppliedAt)
+	}
+
+	reverted, err := migrator.Down(len(statuses))
+	assert.NoError(t, err)
+	assert.Len(t, reverted, len(statuses))
+
+	_, err = conn.Exec("SELECT id FROM tasks")
+	assert.Error(t, err)
+
+	statuses, err = migrator.Status()
+	assert.NoError(t, err)
+	for _, s := range statuses {
+		assert.Nil(t, s.AppliedAt)
+	}
+}
@@ -1,17 +1,18 @@
 -- Создание таблицы tasks.
-CREATE TABLE tasks (
+-- IF NOT EXISTS позволяет принять под управление базы, созданные старым скриптом init.d.
+CREATE TABLE IF NOT EXISTS tasks (
     -- Первичный ключ id с автоинкрементом.
     id SERIAL PRIMARY KEY,
-    
+
     -- Текст задачи (максимум 255 символов).
     task_text VARCHAR(255),
-    
+
     -- Дата создания задачи.
     createdDate DATE,
-    
+
     -- Ожидаемая дата выполнения задачи.
     expectedDate DATE,
-    
+
     -- Статус задачи (целое число).
     status INTEGER
-);
\ No newline at end of file
+);
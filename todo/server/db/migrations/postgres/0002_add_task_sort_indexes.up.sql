@@ -0,0 +1,4 @@
+-- Индексы для keyset-пагинации по полям сортировки: (поле, id) соответствует порядку ORDER BY.
+CREATE INDEX IF NOT EXISTS tasks_createdDate_id_idx ON tasks (createdDate, id);
+CREATE INDEX IF NOT EXISTS tasks_expectedDate_id_idx ON tasks (expectedDate, id);
+CREATE INDEX IF NOT EXISTS tasks_status_id_idx ON tasks (status, id);
@@ -0,0 +1,7 @@
+-- Поисковый вектор по тексту задачи. Колонка вычисляемая, поэтому всегда соответствует task_text.
+-- Конфигурация 'simple' не зависит от языка: задачи пишутся и на русском, и на английском.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(task_text, ''))) STORED;
+
+-- GIN-индекс для полнотекстового поиска.
+CREATE INDEX IF NOT EXISTS tasks_search_vector_idx ON tasks USING GIN (search_vector);
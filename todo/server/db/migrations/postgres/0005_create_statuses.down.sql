@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS status_transitions;
+DROP TABLE IF EXISTS statuses;
@@ -0,0 +1,40 @@
+-- Статусы рабочего процесса. id совпадает с числом в колонке tasks.status.
+CREATE TABLE IF NOT EXISTS statuses (
+    id INTEGER PRIMARY KEY,
+
+    -- Неизменяемое имя статуса в API.
+    name VARCHAR(32) NOT NULL UNIQUE,
+
+    -- Название статуса для интерфейса.
+    title VARCHAR(50) NOT NULL,
+
+    -- Порядок статуса в списках; первый по порядку статус - начальный.
+    position INTEGER NOT NULL,
+
+    -- Цвет статуса в формате #rrggbb.
+    color VARCHAR(7) NOT NULL,
+
+    -- Признак завершающего статуса.
+    terminal BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+-- Допустимые переходы между статусами.
+CREATE TABLE IF NOT EXISTS status_transitions (
+    from_status INTEGER NOT NULL REFERENCES statuses (id) ON DELETE CASCADE,
+    to_status INTEGER NOT NULL REFERENCES statuses (id) ON DELETE CASCADE,
+    PRIMARY KEY (from_status, to_status)
+);
+
+-- Встроенный рабочий процесс (см. db.DefaultWorkflow).
+INSERT INTO statuses (id, name, title, position, color, terminal) VALUES
+    (0, 'in_progress', 'В процессе', 10, '#2196f3', FALSE),
+    (2, 'testing', 'Тестирование', 20, '#ff9800', FALSE),
+    (3, 'returned', 'Возвращено', 30, '#9c27b0', FALSE),
+    (1, 'completed', 'Завершено', 40, '#4caf50', TRUE);
+
+INSERT INTO status_transitions (from_status, to_status) VALUES
+    (0, 2),
+    (2, 1),
+    (2, 3),
+    (3, 0),
+    (1, 3);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS task_events_trigger ON tasks;
+DROP FUNCTION IF EXISTS record_task_event();
+DROP FUNCTION IF EXISTS task_snapshot(tasks);
+DROP TABLE IF EXISTS task_events;
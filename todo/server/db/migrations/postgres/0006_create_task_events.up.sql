@@ -0,0 +1,56 @@
+-- Журнал изменений задач. Таблица только пополняется: записи не изменяются и не удаляются,
+-- поэтому история сохраняется и после удаления задачи (внешнего ключа на tasks нет).
+CREATE TABLE IF NOT EXISTS task_events (
+    id SERIAL PRIMARY KEY,
+
+    -- Идентификатор задачи.
+    task_id INTEGER NOT NULL,
+
+    -- Тип события: created, updated, status_changed или deleted.
+    event_type VARCHAR(16) NOT NULL,
+
+    -- Значения полей задачи до и после изменения в формате JSON (NULL для создания и удаления соответственно).
+    old_values TEXT,
+    new_values TEXT,
+
+    -- Время события.
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS task_events_task_id_idx ON task_events (task_id, id);
+
+-- Снимок полей задачи, которые попадают в журнал.
+CREATE OR REPLACE FUNCTION task_snapshot(t tasks) RETURNS TEXT AS $$
+    SELECT json_build_object(
+        'text', t.task_text,
+        'createdDate', t.createdDate,
+        'expectedDate', t.expectedDate,
+        'status', t.status,
+        'version', t.version
+    )::text
+$$ LANGUAGE sql STABLE;
+
+-- Триггер записывает событие в той же транзакции, что и изменение задачи.
+-- Обновление, не изменившее полей задачи, в журнал не попадает.
+CREATE OR REPLACE FUNCTION record_task_event() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_events (task_id, event_type, new_values) VALUES (NEW.id, 'created', task_snapshot(NEW));
+    ELSIF TG_OP = 'DELETE' THEN
+        INSERT INTO task_events (task_id, event_type, old_values) VALUES (OLD.id, 'deleted', task_snapshot(OLD));
+    ELSIF (OLD.task_text, OLD.createdDate, OLD.expectedDate, OLD.status)
+        IS DISTINCT FROM (NEW.task_text, NEW.createdDate, NEW.expectedDate, NEW.status) THEN
+        INSERT INTO task_events (task_id, event_type, old_values, new_values) VALUES (
+            NEW.id,
+            CASE WHEN OLD.status IS DISTINCT FROM NEW.status THEN 'status_changed' ELSE 'updated' END,
+            task_snapshot(OLD),
+            task_snapshot(NEW)
+        );
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS task_events_trigger ON tasks;
+CREATE TRIGGER task_events_trigger AFTER INSERT OR UPDATE OR DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION record_task_event();
@@ -0,0 +1,22 @@
+-- Возврат к журналу без корзины: удаление задачи снова считается событием deleted.
+CREATE OR REPLACE FUNCTION record_task_event() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_events (task_id, event_type, new_values) VALUES (NEW.id, 'created', task_snapshot(NEW));
+    ELSIF TG_OP = 'DELETE' THEN
+        INSERT INTO task_events (task_id, event_type, old_values) VALUES (OLD.id, 'deleted', task_snapshot(OLD));
+    ELSIF (OLD.task_text, OLD.createdDate, OLD.expectedDate, OLD.status)
+        IS DISTINCT FROM (NEW.task_text, NEW.createdDate, NEW.expectedDate, NEW.status) THEN
+        INSERT INTO task_events (task_id, event_type, old_values, new_values) VALUES (
+            NEW.id,
+            CASE WHEN OLD.status IS DISTINCT FROM NEW.status THEN 'status_changed' ELSE 'updated' END,
+            task_snapshot(OLD),
+            task_snapshot(NEW)
+        );
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS tasks_deleted_at_idx;
+ALTER TABLE tasks DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,28 @@
+-- Время удаления задачи в корзину (NULL - задача не удалена).
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS tasks_deleted_at_idx ON tasks (deleted_at);
+
+-- Журнал различает удаление в корзину, восстановление и окончательное удаление задачи.
+CREATE OR REPLACE FUNCTION record_task_event() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_events (task_id, event_type, new_values) VALUES (NEW.id, 'created', task_snapshot(NEW));
+    ELSIF TG_OP = 'DELETE' THEN
+        INSERT INTO task_events (task_id, event_type, old_values) VALUES (OLD.id, 'purged', task_snapshot(OLD));
+    ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        INSERT INTO task_events (task_id, event_type, old_values) VALUES (NEW.id, 'deleted', task_snapshot(OLD));
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        INSERT INTO task_events (task_id, event_type, new_values) VALUES (NEW.id, 'restored', task_snapshot(NEW));
+    ELSIF (OLD.task_text, OLD.createdDate, OLD.expectedDate, OLD.status)
+        IS DISTINCT FROM (NEW.task_text, NEW.createdDate, NEW.expectedDate, NEW.status) THEN
+        INSERT INTO task_events (task_id, event_type, old_values, new_values) VALUES (
+            NEW.id,
+            CASE WHEN OLD.status IS DISTINCT FROM NEW.status THEN 'status_changed' ELSE 'updated' END,
+            task_snapshot(OLD),
+            task_snapshot(NEW)
+        );
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,22 @@
+-- Пользователи. Пароль хранится только в виде хеша bcrypt.
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(32) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Сессии пользователей. Хранится SHA-256 токена из cookie, а не сам токен.
+CREATE TABLE IF NOT EXISTS sessions (
+    token_hash CHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+
+-- Автор задачи. У задач, созданных до появления пользователей, автора нет.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS tasks_owner_id_idx ON tasks (owner_id);
@@ -0,0 +1,33 @@
+-- Автор задачи в журнале изменений. Он сохраняется и после окончательного удаления задачи,
+-- поэтому историю удаленной задачи по-прежнему может получить только её автор.
+ALTER TABLE task_events ADD COLUMN IF NOT EXISTS owner_id INTEGER;
+
+UPDATE task_events e SET owner_id = t.owner_id FROM tasks t WHERE t.id = e.task_id;
+
+CREATE OR REPLACE FUNCTION record_task_event() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, new_values)
+        VALUES (NEW.id, NEW.owner_id, 'created', task_snapshot(NEW));
+    ELSIF TG_OP = 'DELETE' THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, old_values)
+        VALUES (OLD.id, OLD.owner_id, 'purged', task_snapshot(OLD));
+    ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, old_values)
+        VALUES (NEW.id, NEW.owner_id, 'deleted', task_snapshot(OLD));
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, new_values)
+        VALUES (NEW.id, NEW.owner_id, 'restored', task_snapshot(NEW));
+    ELSIF (OLD.task_text, OLD.createdDate, OLD.expectedDate, OLD.status)
+        IS DISTINCT FROM (NEW.task_text, NEW.createdDate, NEW.expectedDate, NEW.status) THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, old_values, new_values) VALUES (
+            NEW.id,
+            NEW.owner_id,
+            CASE WHEN OLD.status IS DISTINCT FROM NEW.status THEN 'status_changed' ELSE 'updated' END,
+            task_snapshot(OLD),
+            task_snapshot(NEW)
+        );
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
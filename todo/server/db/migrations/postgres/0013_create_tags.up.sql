@@ -0,0 +1,19 @@
+-- Метки задач. Метки, как и проекты, принадлежат списку задач пользователя, а имя метки уникально в списке.
+-- Метка создается при первом назначении задаче.
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    owner_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (owner_id, name)
+);
+
+-- Связь задач с метками (многие ко многим).
+CREATE TABLE IF NOT EXISTS task_tags (
+    task_id INTEGER NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, tag_id)
+);
+
+-- Индекс для фильтра задач по метке.
+CREATE INDEX IF NOT EXISTS task_tags_tag_id_idx ON task_tags (tag_id);
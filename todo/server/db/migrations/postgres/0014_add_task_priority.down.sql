@@ -0,0 +1,42 @@
+-- Возврат к журналу без приоритета задачи.
+CREATE OR REPLACE FUNCTION record_task_event() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, new_values)
+        VALUES (NEW.id, NEW.owner_id, 'created', task_snapshot(NEW));
+    ELSIF TG_OP = 'DELETE' THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, old_values)
+        VALUES (OLD.id, OLD.owner_id, 'purged', task_snapshot(OLD));
+    ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, old_values)
+        VALUES (NEW.id, NEW.owner_id, 'deleted', task_snapshot(OLD));
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, new_values)
+        VALUES (NEW.id, NEW.owner_id, 'restored', task_snapshot(NEW));
+    ELSIF (OLD.task_text, OLD.createdDate, OLD.expectedDate, OLD.status, OLD.project_id)
+        IS DISTINCT FROM (NEW.task_text, NEW.createdDate, NEW.expectedDate, NEW.status, NEW.project_id) THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, old_values, new_values) VALUES (
+            NEW.id,
+            NEW.owner_id,
+            CASE WHEN OLD.status IS DISTINCT FROM NEW.status THEN 'status_changed' ELSE 'updated' END,
+            task_snapshot(OLD),
+            task_snapshot(NEW)
+        );
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION task_snapshot(t tasks) RETURNS TEXT AS $$
+    SELECT json_build_object(
+        'text', t.task_text,
+        'createdDate', t.createdDate,
+        'expectedDate', t.expectedDate,
+        'status', t.status,
+        'projectId', t.project_id,
+        'version', t.version
+    )::text
+$$ LANGUAGE sql STABLE;
+
+DROP INDEX IF EXISTS tasks_owner_priority_idx;
+ALTER TABLE tasks DROP COLUMN IF EXISTS priority;
@@ -0,0 +1,47 @@
+-- Приоритет задачи: -1 - low, 0 - normal, 1 - high, 2 - urgent. Числовое значение упорядочивает задачи
+-- по важности, а существующие задачи получают обычный приоритет.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0 CHECK (priority BETWEEN -1 AND 2);
+
+-- Индекс для порядка задач по умолчанию: сначала важные, затем с более ранним сроком.
+CREATE INDEX IF NOT EXISTS tasks_owner_priority_idx ON tasks (owner_id, priority DESC, expectedDate, id);
+
+-- Приоритет задачи попадает в журнал изменений, поэтому его смена видна в истории.
+CREATE OR REPLACE FUNCTION task_snapshot(t tasks) RETURNS TEXT AS $$
+    SELECT json_build_object(
+        'text', t.task_text,
+        'createdDate', t.createdDate,
+        'expectedDate', t.expectedDate,
+        'status', t.status,
+        'projectId', t.project_id,
+        'priority', t.priority,
+        'version', t.version
+    )::text
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION record_task_event() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, new_values)
+        VALUES (NEW.id, NEW.owner_id, 'created', task_snapshot(NEW));
+    ELSIF TG_OP = 'DELETE' THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, old_values)
+        VALUES (OLD.id, OLD.owner_id, 'purged', task_snapshot(OLD));
+    ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, old_values)
+        VALUES (NEW.id, NEW.owner_id, 'deleted', task_snapshot(OLD));
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, new_values)
+        VALUES (NEW.id, NEW.owner_id, 'restored', task_snapshot(NEW));
+    ELSIF (OLD.task_text, OLD.createdDate, OLD.expectedDate, OLD.status, OLD.project_id, OLD.priority)
+        IS DISTINCT FROM (NEW.task_text, NEW.createdDate, NEW.expectedDate, NEW.status, NEW.project_id, NEW.priority) THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, old_values, new_values) VALUES (
+            NEW.id,
+            NEW.owner_id,
+            CASE WHEN OLD.status IS DISTINCT FROM NEW.status THEN 'status_changed' ELSE 'updated' END,
+            task_snapshot(OLD),
+            task_snapshot(NEW)
+        );
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,49 @@
+-- Имена меток задачи через запятую в порядке имен. Копия связей task_tags хранится в строке задачи,
+-- чтобы триггер журнала видел смену меток вместе с остальными полями и записывал ее в историю.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS tag_names TEXT NOT NULL DEFAULT '';
+
+-- Заполнение выполняется до замены триггера, поэтому в журнал не попадает.
+UPDATE tasks SET tag_names = COALESCE((SELECT string_agg(g.name, ',' ORDER BY g.name)
+    FROM task_tags tt JOIN tags g ON g.id = tt.tag_id WHERE tt.task_id = tasks.id), '');
+
+CREATE OR REPLACE FUNCTION task_snapshot(t tasks) RETURNS TEXT AS $$
+    SELECT json_build_object(
+        'text', t.task_text,
+        'createdDate', t.createdDate,
+        'expectedDate', t.expectedDate,
+        'status', t.status,
+        'projectId', t.project_id,
+        'priority', t.priority,
+        'tags', t.tag_names,
+        'version', t.version
+    )::text
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION record_task_event() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, new_values)
+        VALUES (NEW.id, NEW.owner_id, 'created', task_snapshot(NEW));
+    ELSIF TG_OP = 'DELETE' THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, old_values)
+        VALUES (OLD.id, OLD.owner_id, 'purged', task_snapshot(OLD));
+    ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, old_values)
+        VALUES (NEW.id, NEW.owner_id, 'deleted', task_snapshot(OLD));
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, new_values)
+        VALUES (NEW.id, NEW.owner_id, 'restored', task_snapshot(NEW));
+    ELSIF (OLD.task_text, OLD.createdDate, OLD.expectedDate, OLD.status, OLD.project_id, OLD.priority, OLD.tag_names)
+        IS DISTINCT FROM
+        (NEW.task_text, NEW.createdDate, NEW.expectedDate, NEW.status, NEW.project_id, NEW.priority, NEW.tag_names) THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, old_values, new_values) VALUES (
+            NEW.id,
+            NEW.owner_id,
+            CASE WHEN OLD.status IS DISTINCT FROM NEW.status THEN 'status_changed' ELSE 'updated' END,
+            task_snapshot(OLD),
+            task_snapshot(NEW)
+        );
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
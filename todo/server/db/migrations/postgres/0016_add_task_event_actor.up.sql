@@ -0,0 +1,35 @@
+-- Пользователь, выполнивший действие, и причина смены статуса в журнале изменений. Автор задачи
+-- (owner_id) может открыть список другим пользователям, поэтому изменять её может не только он.
+ALTER TABLE task_events ADD COLUMN IF NOT EXISTS actor_id INTEGER;
+ALTER TABLE task_events ADD COLUMN IF NOT EXISTS reason TEXT;
+
+-- Запрос, изменяющий задачу, записывает в строку задачи пользователя и причину, а триггер переносит их в журнал.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS changed_by INTEGER;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS status_reason TEXT;
+
+CREATE OR REPLACE FUNCTION record_task_event() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_events (task_id, owner_id, actor_id, event_type, new_values)
+        VALUES (NEW.id, NEW.owner_id, NEW.changed_by, 'created', task_snapshot(NEW));
+    ELSIF TG_OP = 'DELETE' THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, old_values)
+        VALUES (OLD.id, OLD.owner_id, 'purged', task_snapshot(OLD));
+    ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        INSERT INTO task_events (task_id, owner_id, actor_id, event_type, old_values)
+        VALUES (NEW.id, NEW.owner_id, NEW.changed_by, 'deleted', task_snapshot(OLD));
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        INSERT INTO task_events (task_id, owner_id, actor_id, event_type, new_values)
+        VALUES (NEW.id, NEW.owner_id, NEW.changed_by, 'restored', task_snapshot(NEW));
+    ELSIF OLD.status IS DISTINCT FROM NEW.status THEN
+        INSERT INTO task_events (task_id, owner_id, actor_id, event_type, reason, old_values, new_values)
+        VALUES (NEW.id, NEW.owner_id, NEW.changed_by, 'status_changed', NEW.status_reason,
+            task_snapshot(OLD), task_snapshot(NEW));
+    ELSIF (OLD.task_text, OLD.createdDate, OLD.expectedDate, OLD.project_id, OLD.priority, OLD.tag_names)
+        IS DISTINCT FROM (NEW.task_text, NEW.createdDate, NEW.expectedDate, NEW.project_id, NEW.priority, NEW.tag_names) THEN
+        INSERT INTO task_events (task_id, owner_id, actor_id, event_type, old_values, new_values)
+        VALUES (NEW.id, NEW.owner_id, NEW.changed_by, 'updated', task_snapshot(OLD), task_snapshot(NEW));
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,47 @@
+-- Возврат к журналу, который пополняет триггер.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS changed_by INTEGER;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS status_reason TEXT;
+
+CREATE OR REPLACE FUNCTION task_snapshot(t tasks) RETURNS TEXT AS $$
+    SELECT json_build_object(
+        'text', t.task_text,
+        'createdDate', t.createdDate,
+        'expectedDate', t.expectedDate,
+        'status', t.status,
+        'projectId', t.project_id,
+        'priority', t.priority,
+        'tags', t.tag_names,
+        'version', t.version
+    )::text
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION record_task_event() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_events (task_id, owner_id, actor_id, event_type, new_values)
+        VALUES (NEW.id, NEW.owner_id, NEW.changed_by, 'created', task_snapshot(NEW));
+    ELSIF TG_OP = 'DELETE' THEN
+        INSERT INTO task_events (task_id, owner_id, event_type, old_values)
+        VALUES (OLD.id, OLD.owner_id, 'purged', task_snapshot(OLD));
+    ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        INSERT INTO task_events (task_id, owner_id, actor_id, event_type, old_values)
+        VALUES (NEW.id, NEW.owner_id, NEW.changed_by, 'deleted', task_snapshot(OLD));
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        INSERT INTO task_events (task_id, owner_id, actor_id, event_type, new_values)
+        VALUES (NEW.id, NEW.owner_id, NEW.changed_by, 'restored', task_snapshot(NEW));
+    ELSIF OLD.status IS DISTINCT FROM NEW.status THEN
+        INSERT INTO task_events (task_id, owner_id, actor_id, event_type, reason, old_values, new_values)
+        VALUES (NEW.id, NEW.owner_id, NEW.changed_by, 'status_changed', NEW.status_reason,
+            task_snapshot(OLD), task_snapshot(NEW));
+    ELSIF (OLD.task_text, OLD.createdDate, OLD.expectedDate, OLD.project_id, OLD.priority, OLD.tag_names)
+        IS DISTINCT FROM (NEW.task_text, NEW.createdDate, NEW.expectedDate, NEW.project_id, NEW.priority, NEW.tag_names) THEN
+        INSERT INTO task_events (task_id, owner_id, actor_id, event_type, old_values, new_values)
+        VALUES (NEW.id, NEW.owner_id, NEW.changed_by, 'updated', task_snapshot(OLD), task_snapshot(NEW));
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS task_events_trigger ON tasks;
+CREATE TRIGGER task_events_trigger AFTER INSERT OR UPDATE OR DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION record_task_event();
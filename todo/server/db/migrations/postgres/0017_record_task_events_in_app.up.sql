@@ -0,0 +1,9 @@
+-- Журнал изменений задач пополняет приложение в той же транзакции, что и изменение задачи, и само передает
+-- в запись журнала пользователя и причину смены статуса. Триггер и колонки задачи, через которые запросы
+-- передавали их триггеру, больше не нужны.
+DROP TRIGGER IF EXISTS task_events_trigger ON tasks;
+DROP FUNCTION IF EXISTS record_task_event();
+DROP FUNCTION IF EXISTS task_snapshot(tasks);
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS status_reason;
+ALTER TABLE tasks DROP COLUMN IF EXISTS changed_by;
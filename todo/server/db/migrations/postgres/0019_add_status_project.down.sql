@@ -0,0 +1,14 @@
+-- Возврат к одному рабочему процессу на весь сервер. Задачи из статусов проектов переходят в статус
+-- по умолчанию с тем же именем, а если такого нет - в начальный статус.
+UPDATE tasks SET status = COALESCE(
+    (SELECT d.id FROM statuses p JOIN statuses d ON d.name = p.name AND d.project_id IS NULL WHERE p.id = tasks.status),
+    (SELECT id FROM statuses WHERE project_id IS NULL ORDER BY position, id LIMIT 1))
+WHERE status IN (SELECT id FROM statuses WHERE project_id IS NOT NULL);
+
+DELETE FROM statuses WHERE project_id IS NOT NULL;
+
+DROP INDEX IF EXISTS statuses_project_name_idx;
+
+ALTER TABLE statuses DROP COLUMN IF EXISTS project_id;
+
+ALTER TABLE statuses ADD CONSTRAINT statuses_name_key UNIQUE (name);
@@ -0,0 +1,10 @@
+-- Проект, которому принадлежит статус. Статусы без проекта составляют рабочий процесс по умолчанию,
+-- а статусы проекта - его собственный рабочий процесс. Номера статусов по-прежнему уникальны
+-- на всем сервере, поэтому tasks.status однозначно определяет статус задачи любого проекта.
+-- Переходы status_transitions связывают статусы одного рабочего процесса.
+ALTER TABLE statuses ADD COLUMN IF NOT EXISTS project_id INTEGER REFERENCES projects (id) ON DELETE CASCADE;
+
+-- Имя статуса уникально в пределах рабочего процесса.
+ALTER TABLE statuses DROP CONSTRAINT IF EXISTS statuses_name_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS statuses_project_name_idx ON statuses (COALESCE(project_id, 0), name);
@@ -0,0 +1,8 @@
+-- Создание таблицы tasks.
+CREATE TABLE IF NOT EXISTS tasks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_text VARCHAR(255),
+    createdDate DATE,
+    expectedDate DATE,
+    status INTEGER
+);
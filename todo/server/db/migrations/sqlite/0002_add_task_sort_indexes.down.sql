@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tasks_createdDate_id_idx;
+DROP INDEX IF EXISTS tasks_expectedDate_id_idx;
+DROP INDEX IF EXISTS tasks_status_id_idx;
@@ -0,0 +1,19 @@
+-- Полнотекстовый индекс FTS5 по тексту задачи, содержимое берется из таблицы tasks.
+CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5(task_text, content='tasks', content_rowid='id');
+
+-- Индексация уже существующих задач.
+INSERT INTO tasks_fts(tasks_fts) VALUES ('rebuild');
+
+-- Триггеры поддерживают индекс в актуальном состоянии.
+CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON tasks BEGIN
+    INSERT INTO tasks_fts(rowid, task_text) VALUES (new.id, new.task_text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_delete AFTER DELETE ON tasks BEGIN
+    INSERT INTO tasks_fts(tasks_fts, rowid, task_text) VALUES ('delete', old.id, old.task_text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_update AFTER UPDATE OF task_text ON tasks BEGIN
+    INSERT INTO tasks_fts(tasks_fts, rowid, task_text) VALUES ('delete', old.id, old.task_text);
+    INSERT INTO tasks_fts(rowid, task_text) VALUES (new.id, new.task_text);
+END;
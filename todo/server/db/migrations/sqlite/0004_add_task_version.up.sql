@@ -0,0 +1,2 @@
+-- Номер версии задачи для оптимистичной блокировки: увеличивается при каждом изменении.
+ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS task_events_delete;
+DROP TRIGGER IF EXISTS task_events_update;
+DROP TRIGGER IF EXISTS task_events_insert;
+DROP TABLE IF EXISTS task_events;
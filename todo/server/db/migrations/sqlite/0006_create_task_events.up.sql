@@ -0,0 +1,46 @@
+-- Журнал изменений задач. Таблица только пополняется: записи не изменяются и не удаляются,
+-- поэтому история сохраняется и после удаления задачи (внешнего ключа на tasks нет).
+CREATE TABLE IF NOT EXISTS task_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- Идентификатор задачи.
+    task_id INTEGER NOT NULL,
+
+    -- Тип события: created, updated, status_changed или deleted.
+    event_type TEXT NOT NULL,
+
+    -- Значения полей задачи до и после изменения в формате JSON (NULL для создания и удаления соответственно).
+    old_values TEXT,
+    new_values TEXT,
+
+    -- Время события (UTC).
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS task_events_task_id_idx ON task_events (task_id, id);
+
+-- Триггеры записывают события в той же транзакции, что и изменение задачи.
+CREATE TRIGGER IF NOT EXISTS task_events_insert AFTER INSERT ON tasks BEGIN
+    INSERT INTO task_events (task_id, event_type, new_values) VALUES (new.id, 'created',
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'version', new.version));
+END;
+
+-- Обновление, не изменившее полей задачи, в журнал не попадает.
+CREATE TRIGGER IF NOT EXISTS task_events_update AFTER UPDATE ON tasks
+WHEN old.task_text IS NOT new.task_text OR old.createdDate IS NOT new.createdDate
+    OR old.expectedDate IS NOT new.expectedDate OR old.status IS NOT new.status
+BEGIN
+    INSERT INTO task_events (task_id, event_type, old_values, new_values) VALUES (new.id,
+        CASE WHEN old.status IS NOT new.status THEN 'status_changed' ELSE 'updated' END,
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'version', old.version),
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_delete AFTER DELETE ON tasks BEGIN
+    INSERT INTO task_events (task_id, event_type, old_values) VALUES (old.id, 'deleted',
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'version', old.version));
+END;
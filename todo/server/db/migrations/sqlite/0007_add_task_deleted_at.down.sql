@@ -0,0 +1,27 @@
+DROP TRIGGER IF EXISTS task_events_delete;
+DROP TRIGGER IF EXISTS task_events_restore;
+DROP TRIGGER IF EXISTS task_events_soft_delete;
+DROP TRIGGER IF EXISTS task_events_update;
+
+-- Возврат к журналу без корзины.
+-- Обновление, не изменившее полей задачи, в журнал не попадает.
+CREATE TRIGGER IF NOT EXISTS task_events_update AFTER UPDATE ON tasks
+WHEN old.task_text IS NOT new.task_text OR old.createdDate IS NOT new.createdDate
+    OR old.expectedDate IS NOT new.expectedDate OR old.status IS NOT new.status
+BEGIN
+    INSERT INTO task_events (task_id, event_type, old_values, new_values) VALUES (new.id,
+        CASE WHEN old.status IS NOT new.status THEN 'status_changed' ELSE 'updated' END,
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'version', old.version),
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_delete AFTER DELETE ON tasks BEGIN
+    INSERT INTO task_events (task_id, event_type, old_values) VALUES (old.id, 'deleted',
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'version', old.version));
+END;
+
+DROP INDEX IF EXISTS tasks_deleted_at_idx;
+ALTER TABLE tasks DROP COLUMN deleted_at;
@@ -0,0 +1,42 @@
+-- Время удаления задачи в корзину (NULL - задача не удалена).
+ALTER TABLE tasks ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS tasks_deleted_at_idx ON tasks (deleted_at);
+
+-- Журнал различает удаление в корзину, восстановление и окончательное удаление задачи.
+DROP TRIGGER IF EXISTS task_events_update;
+DROP TRIGGER IF EXISTS task_events_delete;
+
+CREATE TRIGGER IF NOT EXISTS task_events_update AFTER UPDATE ON tasks
+WHEN old.deleted_at IS new.deleted_at AND (old.task_text IS NOT new.task_text OR old.createdDate IS NOT new.createdDate
+    OR old.expectedDate IS NOT new.expectedDate OR old.status IS NOT new.status)
+BEGIN
+    INSERT INTO task_events (task_id, event_type, old_values, new_values) VALUES (new.id,
+        CASE WHEN old.status IS NOT new.status THEN 'status_changed' ELSE 'updated' END,
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'version', old.version),
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_soft_delete AFTER UPDATE OF deleted_at ON tasks
+WHEN old.deleted_at IS NULL AND new.deleted_at IS NOT NULL
+BEGIN
+    INSERT INTO task_events (task_id, event_type, old_values) VALUES (old.id, 'deleted',
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'version', old.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_restore AFTER UPDATE OF deleted_at ON tasks
+WHEN old.deleted_at IS NOT NULL AND new.deleted_at IS NULL
+BEGIN
+    INSERT INTO task_events (task_id, event_type, new_values) VALUES (new.id, 'restored',
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_delete AFTER DELETE ON tasks BEGIN
+    INSERT INTO task_events (task_id, event_type, old_values) VALUES (old.id, 'purged',
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'version', old.version));
+END;
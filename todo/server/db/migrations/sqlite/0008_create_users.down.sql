@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS tasks_owner_id_idx;
+ALTER TABLE tasks DROP COLUMN owner_id;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
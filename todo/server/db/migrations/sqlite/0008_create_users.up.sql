@@ -0,0 +1,23 @@
+-- Пользователи. Пароль хранится только в виде хеша bcrypt.
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(32) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Сессии пользователей. Хранится SHA-256 токена из cookie, а не сам токен.
+CREATE TABLE IF NOT EXISTS sessions (
+    token_hash CHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+
+-- Автор задачи. У задач, созданных до появления пользователей, автора нет.
+-- Внешний ключ не объявляется: SQLite не может удалить колонку с ним при откате миграции.
+ALTER TABLE tasks ADD COLUMN owner_id INTEGER;
+
+CREATE INDEX IF NOT EXISTS tasks_owner_id_idx ON tasks (owner_id);
@@ -0,0 +1,51 @@
+-- Автор задачи в журнале изменений. Он сохраняется и после окончательного удаления задачи,
+-- поэтому историю удаленной задачи по-прежнему может получить только её автор.
+ALTER TABLE task_events ADD COLUMN owner_id INTEGER;
+
+UPDATE task_events SET owner_id = (SELECT owner_id FROM tasks WHERE tasks.id = task_events.task_id);
+
+DROP TRIGGER IF EXISTS task_events_insert;
+DROP TRIGGER IF EXISTS task_events_update;
+DROP TRIGGER IF EXISTS task_events_soft_delete;
+DROP TRIGGER IF EXISTS task_events_restore;
+DROP TRIGGER IF EXISTS task_events_delete;
+
+CREATE TRIGGER IF NOT EXISTS task_events_insert AFTER INSERT ON tasks BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, new_values) VALUES (new.id, new.owner_id, 'created',
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_update AFTER UPDATE ON tasks
+WHEN old.deleted_at IS new.deleted_at AND (old.task_text IS NOT new.task_text OR old.createdDate IS NOT new.createdDate
+    OR old.expectedDate IS NOT new.expectedDate OR old.status IS NOT new.status)
+BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, old_values, new_values) VALUES (new.id, new.owner_id,
+        CASE WHEN old.status IS NOT new.status THEN 'status_changed' ELSE 'updated' END,
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'version', old.version),
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_soft_delete AFTER UPDATE OF deleted_at ON tasks
+WHEN old.deleted_at IS NULL AND new.deleted_at IS NOT NULL
+BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, old_values) VALUES (old.id, old.owner_id, 'deleted',
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'version', old.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_restore AFTER UPDATE OF deleted_at ON tasks
+WHEN old.deleted_at IS NOT NULL AND new.deleted_at IS NULL
+BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, new_values) VALUES (new.id, new.owner_id, 'restored',
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_delete AFTER DELETE ON tasks BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, old_values) VALUES (old.id, old.owner_id, 'purged',
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'version', old.version));
+END;
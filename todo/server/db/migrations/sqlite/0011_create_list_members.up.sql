@@ -0,0 +1,13 @@
+-- Участники общих списков задач. Список задач пользователя owner_id доступен участнику user_id
+-- с ролью: viewer - только чтение, editor - изменение задач, admin - еще и управление участниками.
+-- Автор списка в таблице не хранится: ему всегда доступны все действия.
+CREATE TABLE IF NOT EXISTS list_members (
+    owner_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('viewer', 'editor', 'admin')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (owner_id, user_id),
+    CHECK (owner_id <> user_id)
+);
+
+CREATE INDEX IF NOT EXISTS list_members_user_id_idx ON list_members (user_id);
@@ -0,0 +1,63 @@
+-- Проекты - группы задач внутри списка задач пользователя. Имя проекта уникально в списке.
+CREATE TABLE IF NOT EXISTS projects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (owner_id, name)
+);
+
+-- Проект задачи. Задачи без проекта (в том числе созданные до появления проектов) остаются в списке.
+-- Внешний ключ не объявляется: SQLite не может удалить колонку с ним при откате миграции,
+-- поэтому при удалении проекта хранилище само убирает его из задач.
+ALTER TABLE tasks ADD COLUMN project_id INTEGER;
+
+CREATE INDEX IF NOT EXISTS tasks_project_id_idx ON tasks (project_id);
+
+-- Проект задачи попадает в журнал изменений, поэтому перенос задачи в другой проект виден в истории.
+DROP TRIGGER IF EXISTS task_events_insert;
+DROP TRIGGER IF EXISTS task_events_update;
+DROP TRIGGER IF EXISTS task_events_soft_delete;
+DROP TRIGGER IF EXISTS task_events_restore;
+DROP TRIGGER IF EXISTS task_events_delete;
+
+CREATE TRIGGER IF NOT EXISTS task_events_insert AFTER INSERT ON tasks BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, new_values) VALUES (new.id, new.owner_id, 'created',
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'projectId', new.project_id, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_update AFTER UPDATE ON tasks
+WHEN old.deleted_at IS new.deleted_at AND (old.task_text IS NOT new.task_text OR old.createdDate IS NOT new.createdDate
+    OR old.expectedDate IS NOT new.expectedDate OR old.status IS NOT new.status
+    OR old.project_id IS NOT new.project_id)
+BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, old_values, new_values) VALUES (new.id, new.owner_id,
+        CASE WHEN old.status IS NOT new.status THEN 'status_changed' ELSE 'updated' END,
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'projectId', old.project_id, 'version', old.version),
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'projectId', new.project_id, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_soft_delete AFTER UPDATE OF deleted_at ON tasks
+WHEN old.deleted_at IS NULL AND new.deleted_at IS NOT NULL
+BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, old_values) VALUES (old.id, old.owner_id, 'deleted',
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'projectId', old.project_id, 'version', old.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_restore AFTER UPDATE OF deleted_at ON tasks
+WHEN old.deleted_at IS NOT NULL AND new.deleted_at IS NULL
+BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, new_values) VALUES (new.id, new.owner_id, 'restored',
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'projectId', new.project_id, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_delete AFTER DELETE ON tasks BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, old_values) VALUES (old.id, old.owner_id, 'purged',
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'projectId', old.project_id, 'version', old.version));
+END;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS task_tags_delete;
+DROP TABLE IF EXISTS task_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,54 @@
+-- Приоритет задачи: -1 - low, 0 - normal, 1 - high, 2 - urgent. Числовое значение упорядочивает задачи
+-- по важности, а существующие задачи получают обычный приоритет.
+ALTER TABLE tasks ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0 CHECK (priority BETWEEN -1 AND 2);
+
+-- Индекс для порядка задач по умолчанию: сначала важные, затем с более ранним сроком.
+CREATE INDEX IF NOT EXISTS tasks_owner_priority_idx ON tasks (owner_id, priority DESC, expectedDate, id);
+
+-- Приоритет задачи попадает в журнал изменений, поэтому его смена видна в истории.
+DROP TRIGGER IF EXISTS task_events_insert;
+DROP TRIGGER IF EXISTS task_events_update;
+DROP TRIGGER IF EXISTS task_events_soft_delete;
+DROP TRIGGER IF EXISTS task_events_restore;
+DROP TRIGGER IF EXISTS task_events_delete;
+
+CREATE TRIGGER IF NOT EXISTS task_events_insert AFTER INSERT ON tasks BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, new_values) VALUES (new.id, new.owner_id, 'created',
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'projectId', new.project_id, 'priority', new.priority, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_update AFTER UPDATE ON tasks
+WHEN old.deleted_at IS new.deleted_at AND (old.task_text IS NOT new.task_text OR old.createdDate IS NOT new.createdDate
+    OR old.expectedDate IS NOT new.expectedDate OR old.status IS NOT new.status
+    OR old.project_id IS NOT new.project_id OR old.priority IS NOT new.priority)
+BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, old_values, new_values) VALUES (new.id, new.owner_id,
+        CASE WHEN old.status IS NOT new.status THEN 'status_changed' ELSE 'updated' END,
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'projectId', old.project_id, 'priority', old.priority, 'version', old.version),
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'projectId', new.project_id, 'priority', new.priority, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_soft_delete AFTER UPDATE OF deleted_at ON tasks
+WHEN old.deleted_at IS NULL AND new.deleted_at IS NOT NULL
+BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, old_values) VALUES (old.id, old.owner_id, 'deleted',
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'projectId', old.project_id, 'priority', old.priority, 'version', old.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_restore AFTER UPDATE OF deleted_at ON tasks
+WHEN old.deleted_at IS NOT NULL AND new.deleted_at IS NULL
+BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, new_values) VALUES (new.id, new.owner_id, 'restored',
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'projectId', new.project_id, 'priority', new.priority, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_delete AFTER DELETE ON tasks BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, old_values) VALUES (old.id, old.owner_id, 'purged',
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'projectId', old.project_id, 'priority', old.priority, 'version', old.version));
+END;
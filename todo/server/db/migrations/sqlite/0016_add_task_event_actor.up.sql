@@ -0,0 +1,66 @@
+-- Пользователь, выполнивший действие, и причина смены статуса в журнале изменений. Автор задачи
+-- (owner_id) может открыть список другим пользователям, поэтому изменять её может не только он.
+ALTER TABLE task_events ADD COLUMN actor_id INTEGER;
+ALTER TABLE task_events ADD COLUMN reason TEXT;
+
+-- Запрос, изменяющий задачу, записывает в строку задачи пользователя и причину, а триггер переносит их в журнал.
+ALTER TABLE tasks ADD COLUMN changed_by INTEGER;
+ALTER TABLE tasks ADD COLUMN status_reason TEXT;
+
+DROP TRIGGER IF EXISTS task_events_insert;
+DROP TRIGGER IF EXISTS task_events_update;
+DROP TRIGGER IF EXISTS task_events_soft_delete;
+DROP TRIGGER IF EXISTS task_events_restore;
+DROP TRIGGER IF EXISTS task_events_delete;
+
+CREATE TRIGGER IF NOT EXISTS task_events_insert AFTER INSERT ON tasks BEGIN
+    INSERT INTO task_events (task_id, owner_id, actor_id, event_type, new_values)
+    VALUES (new.id, new.owner_id, new.changed_by, 'created',
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'projectId', new.project_id, 'priority', new.priority,
+            'tags', new.tag_names, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_update AFTER UPDATE ON tasks
+WHEN old.deleted_at IS new.deleted_at AND (old.task_text IS NOT new.task_text OR old.createdDate IS NOT new.createdDate
+    OR old.expectedDate IS NOT new.expectedDate OR old.status IS NOT new.status
+    OR old.project_id IS NOT new.project_id OR old.priority IS NOT new.priority OR old.tag_names IS NOT new.tag_names)
+BEGIN
+    INSERT INTO task_events (task_id, owner_id, actor_id, event_type, reason, old_values, new_values)
+    VALUES (new.id, new.owner_id, new.changed_by,
+        CASE WHEN old.status IS NOT new.status THEN 'status_changed' ELSE 'updated' END,
+        CASE WHEN old.status IS NOT new.status THEN new.status_reason END,
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'projectId', old.project_id, 'priority', old.priority,
+            'tags', old.tag_names, 'version', old.version),
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'projectId', new.project_id, 'priority', new.priority,
+            'tags', new.tag_names, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_soft_delete AFTER UPDATE OF deleted_at ON tasks
+WHEN old.deleted_at IS NULL AND new.deleted_at IS NOT NULL
+BEGIN
+    INSERT INTO task_events (task_id, owner_id, actor_id, event_type, old_values)
+    VALUES (old.id, old.owner_id, new.changed_by, 'deleted',
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'projectId', old.project_id, 'priority', old.priority,
+            'tags', old.tag_names, 'version', old.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_restore AFTER UPDATE OF deleted_at ON tasks
+WHEN old.deleted_at IS NOT NULL AND new.deleted_at IS NULL
+BEGIN
+    INSERT INTO task_events (task_id, owner_id, actor_id, event_type, new_values)
+    VALUES (new.id, new.owner_id, new.changed_by, 'restored',
+        json_object('text', new.task_text, 'createdDate', new.createdDate, 'expectedDate', new.expectedDate,
+            'status', new.status, 'projectId', new.project_id, 'priority', new.priority,
+            'tags', new.tag_names, 'version', new.version));
+END;
+
+CREATE TRIGGER IF NOT EXISTS task_events_delete AFTER DELETE ON tasks BEGIN
+    INSERT INTO task_events (task_id, owner_id, event_type, old_values) VALUES (old.id, old.owner_id, 'purged',
+        json_object('text', old.task_text, 'createdDate', old.createdDate, 'expectedDate', old.expectedDate,
+            'status', old.status, 'projectId', old.project_id, 'priority', old.priority,
+            'tags', old.tag_names, 'version', old.version));
+END;
@@ -0,0 +1,11 @@
+-- Журнал изменений задач пополняет приложение в той же транзакции, что и изменение задачи, и само передает
+-- в запись журнала пользователя и причину смены статуса. Триггеры и колонки задачи, через которые запросы
+-- передавали их триггерам, больше не нужны.
+DROP TRIGGER IF EXISTS task_events_insert;
+DROP TRIGGER IF EXISTS task_events_update;
+DROP TRIGGER IF EXISTS task_events_soft_delete;
+DROP TRIGGER IF EXISTS task_events_restore;
+DROP TRIGGER IF EXISTS task_events_delete;
+
+ALTER TABLE tasks DROP COLUMN status_reason;
+ALTER TABLE tasks DROP COLUMN changed_by;
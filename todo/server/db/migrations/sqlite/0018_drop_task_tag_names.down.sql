@@ -0,0 +1,5 @@
+-- Возврат к копии меток в строке задачи.
+ALTER TABLE tasks ADD COLUMN tag_names TEXT NOT NULL DEFAULT '';
+
+UPDATE tasks SET tag_names = COALESCE((SELECT group_concat(name, ',') FROM (SELECT g.name FROM task_tags tt
+    JOIN tags g ON g.id = tt.tag_id WHERE tt.task_id = tasks.id ORDER BY g.name)), '');
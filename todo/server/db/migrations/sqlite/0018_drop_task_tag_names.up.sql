@@ -0,0 +1,3 @@
+-- Журнал изменений видит смену меток без копии в строке задачи: событие записывает приложение,
+-- а метки задачи считываются из связей task_tags.
+ALTER TABLE tasks DROP COLUMN tag_names;
@@ -0,0 +1,25 @@
+-- Возврат к одному рабочему процессу на весь сервер. Задачи из статусов проектов переходят в статус
+-- по умолчанию с тем же именем, а если такого нет - в начальный статус.
+UPDATE tasks SET status = COALESCE(
+    (SELECT d.id FROM statuses p JOIN statuses d ON d.name = p.name AND d.project_id IS NULL WHERE p.id = tasks.status),
+    (SELECT id FROM statuses WHERE project_id IS NULL ORDER BY position, id LIMIT 1))
+WHERE status IN (SELECT id FROM statuses WHERE project_id IS NOT NULL);
+
+DELETE FROM status_transitions WHERE from_status IN (SELECT id FROM statuses WHERE project_id IS NOT NULL)
+    OR to_status IN (SELECT id FROM statuses WHERE project_id IS NOT NULL);
+
+CREATE TABLE statuses_old (
+    id INTEGER PRIMARY KEY,
+    name VARCHAR(32) NOT NULL UNIQUE,
+    title VARCHAR(50) NOT NULL,
+    position INTEGER NOT NULL,
+    color VARCHAR(7) NOT NULL,
+    terminal INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO statuses_old (id, name, title, position, color, terminal)
+SELECT id, name, title, position, color, terminal FROM statuses WHERE project_id IS NULL;
+
+DROP TABLE statuses;
+
+ALTER TABLE statuses_old RENAME TO statuses;
@@ -0,0 +1,24 @@
+-- Проект, которому принадлежит статус. Статусы без проекта составляют рабочий процесс по умолчанию,
+-- а статусы проекта - его собственный рабочий процесс. Номера статусов по-прежнему уникальны
+-- на всем сервере, поэтому tasks.status однозначно определяет статус задачи любого проекта.
+-- Переходы status_transitions связывают статусы одного рабочего процесса.
+-- SQLite не удаляет ограничение UNIQUE у колонки, поэтому таблица статусов пересоздается.
+CREATE TABLE statuses_new (
+    id INTEGER PRIMARY KEY,
+    project_id INTEGER REFERENCES projects (id),
+    name VARCHAR(32) NOT NULL,
+    title VARCHAR(50) NOT NULL,
+    position INTEGER NOT NULL,
+    color VARCHAR(7) NOT NULL,
+    terminal INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO statuses_new (id, name, title, position, color, terminal)
+SELECT id, name, title, position, color, terminal FROM statuses;
+
+DROP TABLE statuses;
+
+ALTER TABLE statuses_new RENAME TO statuses;
+
+-- Имя статуса уникально в пределах рабочего процесса.
+CREATE UNIQUE INDEX IF NOT EXISTS statuses_project_name_idx ON statuses (COALESCE(project_id, 0), name);
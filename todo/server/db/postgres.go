@@ -0,0 +1,1203 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Структура PostgresStore реализует TaskStore поверх PostgreSQL.
+type PostgresStore struct {
+	db     *sql.DB
+	search searchDialect
+	// rowLock - окончание запроса, блокирующее считанные строки задач до конца транзакции.
+	rowLock string
+}
+
+// Функция NewPostgresStore создает хранилище задач поверх открытого соединения.
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db, search: postgresSearch{}, rowLock: " FOR UPDATE"}
+}
+
+// Метод Migrator возвращает мигратор схемы PostgreSQL.
+func (s *PostgresStore) Migrator() *Migrator {
+	return NewMigrator(s.db, "postgres")
+}
+
+// Метод GetAllTasks получает страницу задач пользователя из базы данных с учетом фильтрации и сортировки.
+// Задачи с одинаковым значением поля сортировки упорядочиваются по id, поэтому порядок стабилен
+// и страницы можно листать курсором (keyset-пагинация по паре (поле сортировки, id)).
+func (s *PostgresStore) GetAllTasks(q TaskQuery) (TaskPage, error) {
+	if err := q.validate(); err != nil {
+		return TaskPage{}, err
+	}
+
+	columns := taskColumns
+	conditions := []string{"owner_id = $1", "deleted_at IS NULL"}
+	args := []interface{}{q.Owner}
+	if q.Deleted {
+		columns += ", deleted_at"
+		conditions[1] = "deleted_at IS NOT NULL"
+	}
+
+	if statuses, ok, _ := q.statusFilter(); ok {
+		placeholders := make([]string, 0, len(statuses))
+		for _, status := range statuses {
+			args = append(args, int(status))
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+		if len(placeholders) == 1 {
+			conditions = append(conditions, "status = "+placeholders[0])
+		} else {
+			conditions = append(conditions, "status IN ("+strings.Join(placeholders, ", ")+")")
+		}
+	}
+
+	if project, ok, _ := q.projectFilter(); ok {
+		if project == 0 {
+			conditions = append(conditions, "project_id IS NULL")
+		} else {
+			args = append(args, project)
+			conditions = append(conditions, fmt.Sprintf("project_id = $%d", len(args)))
+		}
+	}
+
+	if len(q.Tags) > 0 {
+		placeholders := make([]string, 0, len(q.Tags))
+		for _, tag := range q.Tags {
+			args = append(args, tag)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+		tagged := "FROM task_tags tt JOIN tags g ON g.id = tt.tag_id " +
+			"WHERE tt.task_id = tasks.id AND g.name IN (" + strings.Join(placeholders, ", ") + ")"
+		if q.matchAllTags() {
+			// Метки фильтра различны, а у задачи каждая метка встречается один раз.
+			conditions = append(conditions, fmt.Sprintf("(SELECT COUNT(*) %s) = %d", tagged, len(q.Tags)))
+		} else {
+			conditions = append(conditions, "EXISTS (SELECT 1 "+tagged+")")
+		}
+	}
+
+	// Выражения, по которым сортируются задачи; при поиске по релевантности это ранг.
+	keys := q.orderKeys()
+	exprs := make([]string, len(keys))
+	for i, key := range keys {
+		exprs[i] = key.Field
+	}
+	if q.searching() {
+		search := parseSearch(q.Search)
+		if len(search) == 0 {
+			return TaskPage{}, nil
+		}
+		args = append(args, s.search.query(search))
+		conditions = append(conditions, s.search.match(len(args)))
+		rank := s.search.rank(len(args))
+		columns += ", " + rank
+		for i, key := range keys {
+			if key.Field == "rank" {
+				exprs[i] = rank
+			}
+		}
+	}
+
+	if q.After != "" {
+		after, err := decodeCursor(q.After, q)
+		if err != nil {
+			return TaskPage{}, err
+		}
+		params := make([]int, len(keys))
+		for i, key := range keys {
+			if key.Field == "id" {
+				args = append(args, after.ID)
+			} else {
+				args = append(args, cursorValue(after, key.Field))
+			}
+			params[i] = len(args)
+		}
+		conditions = append(conditions, keysetCondition(keys, exprs, params))
+	}
+
+	query := "SELECT " + columns + " FROM tasks WHERE " + strings.Join(conditions, " AND ")
+
+	// Поля сортировки проверены по белому списку в q.validate.
+	order := make([]string, len(keys))
+	for i, key := range keys {
+		order[i] = exprs[i]
+		if key.Desc {
+			order[i] += " DESC"
+		}
+	}
+	query += " ORDER BY " + strings.Join(order, ", ")
+
+	// Запрашивается на одну задачу больше, чтобы узнать, есть ли следующая страница.
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", q.Limit+1)
+	}
+
+	tasks, err := s.queryTasks(query, q.Deleted, q.searching(), args...)
+	if err != nil {
+		return TaskPage{}, err
+	}
+	for i := range tasks {
+		tasks[i].OwnerID = q.Owner
+	}
+
+	return newTaskPage(tasks, q), nil
+}
+
+// Функция keysetCondition возвращает условие выборки задач, следующих после задачи-ориентира
+// в порядке ключей keys: exprs - выражения ключей, params - номера параметров со значениями ориентира.
+// Если направления всех ключей совпадают, задачи сравниваются как кортежи, иначе - по ключам по очереди.
+func keysetCondition(keys []sortKey, exprs []string, params []int) string {
+	comparison := func(key sortKey) string {
+		if key.Desc {
+			return "<"
+		}
+		return ">"
+	}
+
+	mixed := false
+	for _, key := range keys {
+		mixed = mixed || key.Desc != keys[0].Desc
+	}
+	if !mixed {
+		if len(keys) == 1 {
+			return fmt.Sprintf("%s %s $%d", exprs[0], comparison(keys[0]), params[0])
+		}
+		placeholders := make([]string, len(params))
+		for i, param := range params {
+			placeholders[i] = fmt.Sprintf("$%d", param)
+		}
+		return fmt.Sprintf("(%s) %s (%s)",
+			strings.Join(exprs, ", "), comparison(keys[0]), strings.Join(placeholders, ", "))
+	}
+
+	last := len(keys) - 1
+	condition := fmt.Sprintf("%s %s $%d", exprs[last], comparison(keys[last]), params[last])
+	for i := last - 1; i >= 0; i-- {
+		condition = fmt.Sprintf("(%s %s $%d OR (%s = $%d AND %s))",
+			exprs[i], comparison(keys[i]), params[i], exprs[i], params[i], condition)
+	}
+	return condition
+}
+
+// Столбцы задачи в порядке, в котором их считывают queryTasks и GetTaskByID.
+// Метки задачи выбираются тем же запросом из связей task_tags одной строкой через запятую в порядке имен.
+const taskColumns = "id, task_text, createdDate, expectedDate, status, version, project_id, priority, " +
+	"COALESCE((SELECT string_agg(g.name, ',' ORDER BY g.name) FROM task_tags tt JOIN tags g ON g.id = tt.tag_id " +
+	"WHERE tt.task_id = tasks.id), '')"
+
+// Метод queryTasks выполняет запрос и считывает задачи из результата.
+// Если withDeleted установлен, за полями задачи следует время удаления,
+// а если withRank - последней колонкой результата считается релевантность.
+func (s *PostgresStore) queryTasks(query string, withDeleted, withRank bool, args ...interface{}) ([]Task, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []Task
+	for rows.Next() {
+		var task Task
+		var extra []interface{}
+		if withDeleted {
+			extra = append(extra, &task.DeletedAt)
+		}
+		if withRank {
+			extra = append(extra, &task.Rank)
+		}
+		if scanErr := scanTask(rows, &task, extra...); scanErr != nil {
+			return nil, scanErr
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// Функция scanTask считывает в task задачу из строки результата со столбцами taskColumns,
+// за которыми следуют столбцы extra.
+func scanTask(row rowScanner, task *Task, extra ...interface{}) error {
+	var project sql.NullInt64
+	var tags string
+	dest := []interface{}{&task.ID, &task.Text, &task.CreatedDate, &task.ExpectedDate, &task.Status, &task.Version,
+		&project, &task.Priority, &tags}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	task.ProjectID = project.Int64
+	task.Tags = splitTags(tags)
+	return nil
+}
+
+// Метод GetTaskByID получает задачу пользователя из базы данных по ее идентификатору,
+// не считая задач из корзины.
+func (s *PostgresStore) GetTaskByID(owner int64, id int) (Task, error) {
+	query := "SELECT " + taskColumns + " FROM tasks WHERE id = $1 AND owner_id = $2 AND deleted_at IS NULL"
+
+	task := Task{OwnerID: owner}
+	err := scanTask(s.db.QueryRow(query, id, owner), &task)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Task{}, &NotFoundError{ID: int64(id)}
+	}
+	if err != nil {
+		return Task{}, err
+	}
+	return task, nil
+}
+
+// Метод lockTasks считывает в транзакции tx задачи, подходящие под условие condition, вместе с автором
+// и временем удаления. В PostgreSQL строки задач блокируются до конца транзакции, чтобы значения,
+// которые попадут в журнал как значения до изменения, не изменил параллельный запрос.
+func (s *PostgresStore) lockTasks(tx *sql.Tx, condition string, args ...interface{}) ([]Task, error) {
+	query := "SELECT " + taskColumns + ", owner_id, deleted_at FROM tasks WHERE " + condition + " ORDER BY id" +
+		s.rowLock
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []Task
+	for rows.Next() {
+		var task Task
+		var owner sql.NullInt64
+		if err := scanTask(rows, &task, &owner, &task.DeletedAt); err != nil {
+			return nil, err
+		}
+		task.OwnerID = owner.Int64
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// Метод lockTask считывает в транзакции tx задачу пользователя owner: из корзины, если deleted установлен,
+// иначе не удаленную. Если такой задачи нет, возвращается *NotFoundError.
+func (s *PostgresStore) lockTask(tx *sql.Tx, owner, id int64, deleted bool) (Task, error) {
+	condition := "id = $1 AND owner_id = $2 AND deleted_at IS NULL"
+	if deleted {
+		condition = "id = $1 AND owner_id = $2 AND deleted_at IS NOT NULL"
+	}
+
+	tasks, err := s.lockTasks(tx, condition, id, owner)
+	if err != nil {
+		return Task{}, err
+	}
+	if len(tasks) == 0 {
+		return Task{}, &NotFoundError{ID: id}
+	}
+	return tasks[0], nil
+}
+
+// Функция recordEvent добавляет событие в журнал изменений задачи. Событие записывается в транзакции tx,
+// изменяющей задачу, поэтому изменение и запись журнала сохраняются или откатываются вместе.
+func recordEvent(tx *sql.Tx, event TaskEvent) error {
+	oldValues, err := formatSnapshot(event.Old)
+	if err != nil {
+		return err
+	}
+	newValues, err := formatSnapshot(event.New)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO task_events (task_id, owner_id, actor_id, event_type, reason, old_values, new_values) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	_, err = tx.Exec(query, event.TaskID, nullUser(event.OwnerID), nullUser(event.ActorID), event.Type,
+		sql.NullString{String: event.Reason, Valid: event.Reason != ""}, oldValues, newValues)
+	return err
+}
+
+// Метод CreateTask создает новую задачу в базе данных и возвращает её ID.
+// Задача создается в одной транзакции с назначением меток и записью в журнале изменений.
+func (s *PostgresStore) CreateTask(task Task) (int64, error) {
+	query := "INSERT INTO tasks (task_text, createdDate, expectedDate, status, owner_id, project_id, priority) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, version"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	createdDateStr := task.CreatedDate.Format("2006-01-02")
+	expectedDateStr := task.ExpectedDate.Format("2006-01-02")
+	task.Tags = cloneTags(task.Tags)
+	err = tx.QueryRow(query, task.Text, createdDateStr, expectedDateStr, task.Status, nullUser(task.OwnerID),
+		nullProject(task.ProjectID), task.Priority).Scan(&task.ID, &task.Version)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(task.Tags) > 0 {
+		if err := setTaskTags(tx, task.OwnerID, task.ID, task.Tags); err != nil {
+			return 0, err
+		}
+	}
+	if err := recordEvent(tx, newTaskEvent(task, EventCreated, nil, snapshotOf(task))); err != nil {
+		return 0, err
+	}
+	return task.ID, tx.Commit()
+}
+
+// Метод UpdateTask обновляет существующую задачу пользователя task.OwnerID в базе данных и увеличивает её версию.
+// Если task.Version задан, обновление выполняется, только если версия в базе совпадает с ним.
+// Метки, если они заданы (task.Tags не nil), заменяются в одной транзакции с задачей.
+// Если поля задачи изменились, в той же транзакции в журнал записывается событие от имени task.EditorID.
+func (s *PostgresStore) UpdateTask(task Task) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	current, err := s.lockTask(tx, task.OwnerID, task.ID, false)
+	if err != nil {
+		return 0, err
+	}
+	if task.Version != 0 && task.Version != current.Version {
+		return 0, ErrVersionConflict
+	}
+	replaceTags := task.Tags != nil
+	if replaceTags {
+		task.Tags = cloneTags(task.Tags)
+	} else {
+		task.Tags = current.Tags
+	}
+	task.Version = current.Version + 1
+
+	query := "UPDATE tasks SET task_text = $1, createdDate = $2, expectedDate = $3, status = $4, project_id = $5, " +
+		"priority = $6, version = $7 WHERE id = $8"
+	_, err = tx.Exec(query, task.Text, task.CreatedDate.Format("2006-01-02"), task.ExpectedDate.Format("2006-01-02"),
+		task.Status, nullProject(task.ProjectID), task.Priority, task.Version, task.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	if replaceTags {
+		if err := setTaskTags(tx, task.OwnerID, task.ID, task.Tags); err != nil {
+			return 0, err
+		}
+	}
+	if eventType := changeEventType(current, task); eventType != "" {
+		if err := recordEvent(tx, newTaskEvent(task, eventType, snapshotOf(current), snapshotOf(task))); err != nil {
+			return 0, err
+		}
+	}
+	return task.Version, tx.Commit()
+}
+
+// Функция setTaskTags заменяет метки задачи id метками с именами names из списка пользователя owner.
+// Метки, которых еще нет в списке, создаются.
+func setTaskTags(tx *sql.Tx, owner, id int64, names []string) error {
+	if _, err := tx.Exec("DELETE FROM task_tags WHERE task_id = $1", id); err != nil {
+		return err
+	}
+	for _, name := range names {
+		query := "INSERT INTO tags (owner_id, name) VALUES ($1, $2) ON CONFLICT (owner_id, name) DO NOTHING"
+		if _, err := tx.Exec(query, owner, name); err != nil {
+			return err
+		}
+		query = "INSERT INTO task_tags (task_id, tag_id) SELECT $1, id FROM tags WHERE owner_id = $2 AND name = $3"
+		if _, err := tx.Exec(query, id, owner, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Функция nullProject возвращает значение колонки project_id: NULL для задачи без проекта.
+func nullProject(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
+
+// Функция nullUser возвращает значение колонки с ID пользователя: NULL, если пользователь неизвестен.
+func nullUser(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
+
+// Метод DeleteTask перемещает задачу пользователя в корзину, записывая время удаления в deleted_at.
+// Если version задан, задача удаляется, только если версия в базе совпадает с ним.
+func (s *PostgresStore) DeleteTask(owner, actor int64, id int, version int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current, err := s.lockTask(tx, owner, int64(id), false)
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != current.Version {
+		return ErrVersionConflict
+	}
+
+	query := "UPDATE tasks SET deleted_at = $1, version = version + 1 WHERE id = $2"
+	if _, err := tx.Exec(query, time.Now().UTC(), id); err != nil {
+		return err
+	}
+	current.EditorID = actor
+	if err := recordEvent(tx, newTaskEvent(current, EventDeleted, snapshotOf(current), nil)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Метод RestoreTask возвращает задачу пользователя из корзины и увеличивает её версию.
+func (s *PostgresStore) RestoreTask(owner, actor int64, id int) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	task, err := s.lockTask(tx, owner, int64(id), true)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec("UPDATE tasks SET deleted_at = NULL, version = version + 1 WHERE id = $1", id); err != nil {
+		return 0, err
+	}
+	task.Version++
+	task.EditorID = actor
+	if err := recordEvent(tx, newTaskEvent(task, EventRestored, nil, snapshotOf(task))); err != nil {
+		return 0, err
+	}
+	return task.Version, tx.Commit()
+}
+
+// Метод PurgeDeletedTasks окончательно удаляет из базы данных задачи, перемещенные в корзину раньше before.
+// Журнал изменений удаленных задач сохраняется и пополняется событием окончательного удаления.
+func (s *PostgresStore) PurgeDeletedTasks(before time.Time) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	tasks, err := s.lockTasks(tx, "deleted_at IS NOT NULL AND deleted_at < $1", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	for _, task := range tasks {
+		if _, err := tx.Exec("DELETE FROM tasks WHERE id = $1", task.ID); err != nil {
+			return 0, err
+		}
+		if err := recordEvent(tx, newTaskEvent(task, EventPurged, snapshotOf(task), nil)); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(tasks)), tx.Commit()
+}
+
+// Метод ClaimOwnerlessTasks записывает задачи с owner_id, равным NULL, и их события журнала
+// на пользователя owner в одной транзакции.
+func (s *PostgresStore) ClaimOwnerlessTasks(owner int64) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)", owner).Scan(&exists); err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, ErrUserNotFound
+	}
+
+	result, err := tx.Exec("UPDATE tasks SET owner_id = $1 WHERE owner_id IS NULL", owner)
+	if err != nil {
+		return 0, err
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec("UPDATE task_events SET owner_id = $1 WHERE owner_id IS NULL", owner); err != nil {
+		return 0, err
+	}
+	return claimed, tx.Commit()
+}
+
+// Интерфейс queryer объединяет *sql.DB и *sql.Tx для запросов, которые выполняются и вне транзакции.
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Метод GetWorkflows получает статусы всех рабочих процессов вместе с переходами между ними одним запросом.
+func (s *PostgresStore) GetWorkflows() (Workflows, error) {
+	return loadWorkflows(s.db)
+}
+
+// Метод GetWorkflow получает рабочий процесс задач проекта project.
+func (s *PostgresStore) GetWorkflow(project int64) (Workflow, error) {
+	workflows, err := loadWorkflows(s.db)
+	if err != nil {
+		return Workflow{}, err
+	}
+	return workflows.For(project), nil
+}
+
+// Функция loadWorkflows читает статусы и переходы и группирует их по рабочим процессам.
+// Переходы связывают только статусы одного рабочего процесса (см. setTransitions).
+func loadWorkflows(q queryer) (Workflows, error) {
+	query := "SELECT s.id, COALESCE(s.project_id, 0), s.name, s.title, s.position, s.color, s.terminal, n.name " +
+		"FROM statuses s " +
+		"LEFT JOIN status_transitions t ON t.from_status = s.id " +
+		"LEFT JOIN statuses n ON n.id = t.to_status " +
+		"ORDER BY COALESCE(s.project_id, 0), s.position, s.id, n.position, n.id"
+
+	rows, err := q.Query(query)
+	if err != nil {
+		return Workflows{}, err
+	}
+	defer rows.Close()
+
+	// Каждая строка - один переход статуса, поэтому статус повторяется для каждого перехода.
+	workflows := Workflows{Projects: map[int64]Workflow{}}
+	for rows.Next() {
+		var def StatusDef
+		var project int64
+		var next sql.NullString
+		err := rows.Scan(&def.ID, &project, &def.Name, &def.Title, &def.Position, &def.Color, &def.Terminal, &next)
+		if err != nil {
+			return Workflows{}, err
+		}
+		workflow := workflows.Default
+		if project != 0 {
+			workflow = workflows.Projects[project]
+		}
+		if n := len(workflow.Statuses); n == 0 || workflow.Statuses[n-1].ID != def.ID {
+			def.Transitions = []string{}
+			workflow.Statuses = append(workflow.Statuses, def)
+		}
+		if next.Valid {
+			last := &workflow.Statuses[len(workflow.Statuses)-1]
+			last.Transitions = append(last.Transitions, next.String)
+		}
+		if project != 0 {
+			workflows.Projects[project] = workflow
+		} else {
+			workflows.Default = workflow
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return Workflows{}, err
+	}
+	return workflows, nil
+}
+
+// Метод CreateStatus создает статус в рабочем процессе проекта с номером, следующим за наибольшим
+// из существующих во всех рабочих процессах.
+func (s *PostgresStore) CreateStatus(project int64, def StatusDef) (Status, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := ensureProjectWorkflow(tx, project); err != nil {
+		return 0, err
+	}
+
+	var exists bool
+	query := "SELECT EXISTS (SELECT 1 FROM statuses WHERE COALESCE(project_id, 0) = $1 AND name = $2)"
+	if err := tx.QueryRow(query, project, def.Name).Scan(&exists); err != nil {
+		return 0, err
+	}
+	if exists {
+		return 0, ErrStatusExists
+	}
+
+	id, err := insertStatus(tx, project, def)
+	if err != nil {
+		return 0, err
+	}
+	if err := setTransitions(tx, project, id, def.Transitions); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// Функция insertStatus сохраняет статус рабочего процесса проекта project с номером,
+// следующим за наибольшим из существующих.
+func insertStatus(tx *sql.Tx, project int64, def StatusDef) (Status, error) {
+	query := "INSERT INTO statuses (id, project_id, name, title, position, color, terminal) " +
+		"SELECT COALESCE(MAX(id), -1) + 1, $1, $2, $3, $4, $5, $6 FROM statuses RETURNING id"
+	var id Status
+	err := tx.QueryRow(query, nullProject(project), def.Name, def.Title, def.Position, def.Color, def.Terminal).Scan(&id)
+	return id, err
+}
+
+// Функция ensureProjectWorkflow заводит проекту project собственный рабочий процесс, если его еще нет:
+// копирует статусы и переходы рабочего процесса по умолчанию и переводит задачи проекта
+// (в том числе из корзины) в статусы-копии. Для project, равного 0, ничего не делает.
+func ensureProjectWorkflow(tx *sql.Tx, project int64) error {
+	if project == 0 {
+		return nil
+	}
+	workflows, err := loadWorkflows(tx)
+	if err != nil {
+		return err
+	}
+	if workflows.Scope(project) == project {
+		return nil
+	}
+
+	copies := make(map[Status]Status, len(workflows.Default.Statuses))
+	for _, def := range workflows.Default.Statuses {
+		if copies[def.ID], err = insertStatus(tx, project, def); err != nil {
+			return err
+		}
+	}
+	for _, def := range workflows.Default.Statuses {
+		if err := setTransitions(tx, project, copies[def.ID], def.Transitions); err != nil {
+			return err
+		}
+		// Номера копий больше номеров исходных статусов, поэтому задачи не переводятся дважды.
+		query := "UPDATE tasks SET status = $1 WHERE project_id = $2 AND status = $3"
+		if _, err := tx.Exec(query, copies[def.ID], project, def.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Метод UpdateStatus изменяет статус с именем def.Name в рабочем процессе проекта и заменяет его переходы.
+func (s *PostgresStore) UpdateStatus(project int64, def StatusDef) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := ensureProjectWorkflow(tx, project); err != nil {
+		return err
+	}
+
+	query := "UPDATE statuses SET title = $1, position = $2, color = $3, terminal = $4 " +
+		"WHERE COALESCE(project_id, 0) = $5 AND name = $6 RETURNING id"
+	var id Status
+	err = tx.QueryRow(query, def.Title, def.Position, def.Color, def.Terminal, project, def.Name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrStatusNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := setTransitions(tx, project, id, def.Transitions); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Метод DeleteStatus удаляет статус рабочего процесса проекта, если в нем нет задач,
+// вместе с переходами из него и в него.
+func (s *PostgresStore) DeleteStatus(project int64, name string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := ensureProjectWorkflow(tx, project); err != nil {
+		return err
+	}
+
+	var id Status
+	err = tx.QueryRow("SELECT id FROM statuses WHERE COALESCE(project_id, 0) = $1 AND name = $2", project, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrStatusNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var inUse bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM tasks WHERE status = $1)", id).Scan(&inUse); err != nil {
+		return err
+	}
+	if inUse {
+		return ErrStatusInUse
+	}
+
+	if _, err := tx.Exec("DELETE FROM status_transitions WHERE from_status = $1 OR to_status = $1", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM statuses WHERE id = $1", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Функция setTransitions заменяет переходы из статуса id переходами в статусы с именами names
+// того же рабочего процесса проекта project.
+func setTransitions(tx *sql.Tx, project int64, id Status, names []string) error {
+	if _, err := tx.Exec("DELETE FROM status_transitions WHERE from_status = $1", id); err != nil {
+		return err
+	}
+	for _, name := range names {
+		query := "INSERT INTO status_transitions (from_status, to_status) " +
+			"SELECT $1, id FROM statuses WHERE COALESCE(project_id, 0) = $2 AND name = $3"
+		if _, err := tx.Exec(query, id, project, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Метод GetTaskHistory получает журнал изменений задачи пользователя. Записи журнала создают методы,
+// изменяющие задачу, в той же транзакции (см. recordEvent) и сохраняют в них автора задачи,
+// пользователя, выполнившего действие, и причину смены статуса.
+func (s *PostgresStore) GetTaskHistory(owner int64, id int) ([]TaskEvent, error) {
+	query := "SELECT id, task_id, event_type, old_values, new_values, created_at, actor_id, reason FROM task_events " +
+		"WHERE task_id = $1 AND owner_id = $2 ORDER BY id"
+
+	rows, err := s.db.Query(query, id, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []TaskEvent
+	for rows.Next() {
+		event := TaskEvent{OwnerID: owner}
+		var oldValues, newValues *string
+		var actor sql.NullInt64
+		var reason sql.NullString
+		err := rows.Scan(&event.ID, &event.TaskID, &event.Type, &oldValues, &newValues, &event.CreatedAt, &actor, &reason)
+		if err != nil {
+			return nil, err
+		}
+		event.ActorID, event.Reason = actor.Int64, reason.String
+		if event.Old, err = parseSnapshot(oldValues); err != nil {
+			return nil, err
+		}
+		if event.New, err = parseSnapshot(newValues); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// У задач, созданных до появления журнала, истории нет, но сами задачи существуют.
+	if len(events) == 0 {
+		if _, err := s.GetTaskByID(owner, id); err != nil {
+			return nil, err
+		}
+		return []TaskEvent{}, nil
+	}
+	return events, nil
+}
+
+// Метод CreateUser создает пользователя. Занятое имя определяется по уникальному индексу,
+// поэтому одновременная регистрация двух пользователей с одним именем невозможна.
+func (s *PostgresStore) CreateUser(user User) (int64, error) {
+	query := "INSERT INTO users (username, password_hash) VALUES ($1, $2) " +
+		"ON CONFLICT (username) DO NOTHING RETURNING id"
+
+	var id int64
+	err := s.db.QueryRow(query, user.Username, user.PasswordHash).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrUserExists
+	}
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Метод GetUserByName получает пользователя по имени.
+func (s *PostgresStore) GetUserByName(username string) (User, error) {
+	query := "SELECT id, username, password_hash, created_at FROM users WHERE username = $1"
+
+	var user User
+	err := s.db.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
+
+// Метод CreateSession сохраняет сессию и удаляет истекшие сессии того же пользователя.
+func (s *PostgresStore) CreateSession(session Session) error {
+	if _, err := s.db.Exec("DELETE FROM sessions WHERE user_id = $1 AND expires_at <= $2",
+		session.UserID, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	query := "INSERT INTO sessions (token_hash, user_id, expires_at) VALUES ($1, $2, $3)"
+	_, err := s.db.Exec(query, session.TokenHash, session.UserID, session.ExpiresAt.UTC())
+	return err
+}
+
+// Метод GetSessionUser получает пользователя действующей сессии одним запросом.
+func (s *PostgresStore) GetSessionUser(tokenHash string, now time.Time) (User, error) {
+	query := "SELECT u.id, u.username, u.password_hash, u.created_at FROM sessions s " +
+		"JOIN users u ON u.id = s.user_id WHERE s.token_hash = $1 AND s.expires_at > $2"
+
+	var user User
+	err := s.db.QueryRow(query, tokenHash, now.UTC()).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return User{}, ErrSessionNotFound
+	}
+	if err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
+
+// Метод DeleteSession удаляет сессию.
+func (s *PostgresStore) DeleteSession(tokenHash string) error {
+	_, err := s.db.Exec("DELETE FROM sessions WHERE token_hash = $1", tokenHash)
+	return err
+}
+
+// Столбцы api_tokens в порядке, в котором их считывает scanAPIToken.
+const apiTokenColumns = "id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at"
+
+// Метод CreateAPIToken сохраняет токен. Разрешения хранятся одной строкой через пробел.
+func (s *PostgresStore) CreateAPIToken(token APIToken) (int64, error) {
+	query := "INSERT INTO api_tokens (user_id, name, token_hash, scopes, expires_at) " +
+		"VALUES ($1, $2, $3, $4, $5) RETURNING id"
+
+	var id int64
+	err := s.db.QueryRow(query, token.UserID, token.Name, token.TokenHash,
+		strings.Join(token.Scopes, " "), token.ExpiresAt.UTC()).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Метод GetAPITokens получает токены пользователя в порядке создания.
+func (s *PostgresStore) GetAPITokens(userID int64) ([]APIToken, error) {
+	rows, err := s.db.Query("SELECT "+apiTokenColumns+" FROM api_tokens WHERE user_id = $1 ORDER BY id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// Метод DeleteAPIToken удаляет токен пользователя. Чужой токен считается несуществующим.
+func (s *PostgresStore) DeleteAPIToken(userID, id int64) error {
+	result, err := s.db.Exec("DELETE FROM api_tokens WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+// Метод GetTokenUser отмечает использование действующего токена и получает его владельца.
+// Проверка срока действия и запись времени использования выполняются одним запросом.
+func (s *PostgresStore) GetTokenUser(tokenHash string, now time.Time) (User, APIToken, error) {
+	query := "UPDATE api_tokens SET last_used_at = $2 WHERE token_hash = $1 AND expires_at > $2 " +
+		"RETURNING " + apiTokenColumns
+
+	token, err := scanAPIToken(s.db.QueryRow(query, tokenHash, now.UTC()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return User{}, APIToken{}, ErrTokenNotFound
+	}
+	if err != nil {
+		return User{}, APIToken{}, err
+	}
+
+	var user User
+	err = s.db.QueryRow("SELECT id, username, password_hash, created_at FROM users WHERE id = $1", token.UserID).
+		Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return User{}, APIToken{}, ErrTokenNotFound
+	}
+	if err != nil {
+		return User{}, APIToken{}, err
+	}
+	return user, token, nil
+}
+
+// Интерфейс rowScanner - общее у *sql.Row и *sql.Rows: считывание столбцов текущей строки.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// Функция scanAPIToken считывает токен из строки результата запроса со столбцами apiTokenColumns.
+func scanAPIToken(row rowScanner) (APIToken, error) {
+	var token APIToken
+	var scopes string
+	err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.TokenHash, &scopes,
+		&token.ExpiresAt, &token.LastUsedAt, &token.CreatedAt)
+	if err != nil {
+		return APIToken{}, err
+	}
+	token.Scopes = strings.Fields(scopes)
+	return token, nil
+}
+
+// Метод GetListRole получает роль участника списка задач. Автору списка запрос не нужен.
+func (s *PostgresStore) GetListRole(owner, user int64) (Role, error) {
+	if owner != 0 && owner == user {
+		return RoleAdmin, nil
+	}
+
+	var role Role
+	err := s.db.QueryRow("SELECT role FROM list_members WHERE owner_id = $1 AND user_id = $2", owner, user).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrListNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return role, nil
+}
+
+// Метод GetTaskLists получает собственный список пользователя и открытые ему списки одним запросом.
+func (s *PostgresStore) GetTaskLists(user int64) ([]TaskList, error) {
+	query := "SELECT u.id, u.username, COALESCE(m.role, 'admin') FROM users u " +
+		"LEFT JOIN list_members m ON m.owner_id = u.id AND m.user_id = $1 " +
+		"WHERE u.id = $1 OR m.user_id IS NOT NULL ORDER BY u.id <> $1, u.username"
+
+	rows, err := s.db.Query(query, user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := []TaskList{}
+	for rows.Next() {
+		var list TaskList
+		if err := rows.Scan(&list.OwnerID, &list.OwnerName, &list.Role); err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+	return lists, rows.Err()
+}
+
+// Метод GetListMembers получает участников списка задач вместе с их именами.
+func (s *PostgresStore) GetListMembers(owner int64) ([]ListMember, error) {
+	query := "SELECT m.user_id, u.username, m.role FROM list_members m " +
+		"JOIN users u ON u.id = m.user_id WHERE m.owner_id = $1 ORDER BY u.username"
+
+	rows, err := s.db.Query(query, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []ListMember{}
+	for rows.Next() {
+		member := ListMember{OwnerID: owner}
+		if err := rows.Scan(&member.UserID, &member.Username, &member.Role); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// Метод SetListMember добавляет участника списка или меняет его роль одним запросом.
+func (s *PostgresStore) SetListMember(owner, user int64, role Role) error {
+	query := "INSERT INTO list_members (owner_id, user_id, role) VALUES ($1, $2, $3) " +
+		"ON CONFLICT (owner_id, user_id) DO UPDATE SET role = excluded.role"
+	_, err := s.db.Exec(query, owner, user, role)
+	return err
+}
+
+// Метод DeleteListMember удаляет участника списка.
+func (s *PostgresStore) DeleteListMember(owner, user int64) error {
+	result, err := s.db.Exec("DELETE FROM list_members WHERE owner_id = $1 AND user_id = $2", owner, user)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// Метод GetProjects получает проекты пользователя в порядке имен.
+func (s *PostgresStore) GetProjects(owner int64) ([]Project, error) {
+	rows, err := s.db.Query("SELECT id, name, created_at FROM projects WHERE owner_id = $1 ORDER BY name, id", owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []Project{}
+	for rows.Next() {
+		project := Project{OwnerID: owner}
+		if err := rows.Scan(&project.ID, &project.Name, &project.CreatedAt); err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+	return projects, rows.Err()
+}
+
+// Метод GetProject получает проект пользователя по идентификатору.
+func (s *PostgresStore) GetProject(owner, id int64) (Project, error) {
+	project := Project{OwnerID: owner}
+	err := s.db.QueryRow("SELECT id, name, created_at FROM projects WHERE id = $1 AND owner_id = $2", id, owner).
+		Scan(&project.ID, &project.Name, &project.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Project{}, ErrProjectNotFound
+	}
+	if err != nil {
+		return Project{}, err
+	}
+	return project, nil
+}
+
+// Метод CreateProject создает проект. Занятое имя определяется по уникальному индексу
+// (owner_id, name), как и при регистрации пользователей.
+func (s *PostgresStore) CreateProject(project Project) (int64, error) {
+	query := "INSERT INTO projects (owner_id, name) VALUES ($1, $2) " +
+		"ON CONFLICT (owner_id, name) DO NOTHING RETURNING id"
+
+	var id int64
+	err := s.db.QueryRow(query, project.OwnerID, project.Name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrProjectExists
+	}
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Метод UpdateProject переименовывает проект, если имя не занято другим проектом пользователя.
+func (s *PostgresStore) UpdateProject(project Project) error {
+	query := "UPDATE projects SET name = $1 WHERE id = $2 AND owner_id = $3 " +
+		"AND NOT EXISTS (SELECT 1 FROM projects WHERE owner_id = $3 AND name = $1 AND id <> $2) RETURNING id"
+
+	var id int64
+	err := s.db.QueryRow(query, project.Name, project.ID, project.OwnerID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Запрос не затронул проект: либо его нет, либо имя занято.
+		if _, err := s.GetProject(project.OwnerID, project.ID); err != nil {
+			return err
+		}
+		return ErrProjectExists
+	}
+	return err
+}
+
+// Метод DeleteProject в одной транзакции убирает задачи из проекта и удаляет сам проект.
+// Задачи изменяются явно, а не внешним ключом, чтобы увеличилась их версия и изменение попало в журнал.
+func (s *PostgresStore) DeleteProject(owner, actor, id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tasks, err := s.lockTasks(tx, "project_id = $1 AND owner_id = $2", id, owner)
+	if err != nil {
+		return err
+	}
+	workflows, err := loadWorkflows(tx)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		updated := task
+		updated.ProjectID = 0
+		updated.Status = workflows.moveStatus(task.Status, 0)
+		updated.Version++
+		query := "UPDATE tasks SET project_id = NULL, status = $1, version = version + 1 WHERE id = $2"
+		if _, err := tx.Exec(query, updated.Status, task.ID); err != nil {
+			return err
+		}
+		updated.EditorID = actor
+		event := newTaskEvent(updated, changeEventType(task, updated), snapshotOf(task), snapshotOf(updated))
+		if err := recordEvent(tx, event); err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.Exec("DELETE FROM projects WHERE id = $1 AND owner_id = $2", id, owner)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrProjectNotFound
+	}
+
+	// Собственный рабочий процесс проекта удаляется вместе с ним.
+	query := "DELETE FROM status_transitions WHERE from_status IN (SELECT id FROM statuses WHERE project_id = $1)"
+	if _, err := tx.Exec(query, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM statuses WHERE project_id = $1", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Метод GetTags получает метки пользователя по префиксу имени вместе с количеством задач одним запросом.
+func (s *PostgresStore) GetTags(owner int64, prefix string, limit int) ([]Tag, error) {
+	query := "SELECT g.name, COUNT(*) FROM tags g " +
+		"JOIN task_tags tt ON tt.tag_id = g.id " +
+		"JOIN tasks t ON t.id = tt.task_id AND t.deleted_at IS NULL " +
+		`WHERE g.owner_id = $1 AND g.name LIKE $2 ESCAPE '\' ` +
+		"GROUP BY g.name ORDER BY COUNT(*) DESC, g.name LIMIT $3"
+
+	rows, err := s.db.Query(query, owner, escapeLike(prefix)+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
@@ -0,0 +1,1137 @@
+package db
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// Структура AnyTime используется для сопоставления любых значений типа time.Time в тестах.
+type AnyTime struct{}
+
+func (a AnyTime) Match(v driver.Value) bool {
+	_, ok := v.(time.Time)
+	return ok
+}
+
+// Колонки строк задач, которые возвращают запросы PostgresStore.
+var taskRowColumns = []string{
+	"id", "task_text", "createdDate", "expectedDate", "status", "version", "project_id", "priority", "tags",
+}
+
+// Колонки строк задач, которые считывает и блокирует перед изменением метод lockTasks.
+var lockedRowColumns = append(slices.Clone(taskRowColumns), "owner_id", "deleted_at")
+
+// Запрос, которым методы, изменяющие задачу, записывают событие в журнал.
+const insertEventQuery = `^INSERT INTO task_events ` +
+	`\(task_id, owner_id, actor_id, event_type, reason, old_values, new_values\) ` +
+	`VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7\)$`
+
+// Тест для функции GetAllTasks.
+func TestGetAllTasks(t *testing.T) {
+	// Подготовка тестовых данных.
+	fixedTime := time.Now()
+	task1 := Task{ID: 1, Text: "Task 1", CreatedDate: fixedTime, ExpectedDate: fixedTime, Status: StatusInProgress,
+		OwnerID: testOwner}
+	task2 := Task{ID: 2, Text: "Task 2", CreatedDate: fixedTime, ExpectedDate: fixedTime, Status: StatusCompleted,
+		OwnerID: testOwner}
+	task3 := Task{ID: 3, Text: "Task 3", CreatedDate: fixedTime, ExpectedDate: fixedTime, Status: StatusInProgress,
+		OwnerID: testOwner}
+
+	testCases := []struct {
+		name          string
+		statusFilter  string
+		sortOrder     string
+		expectedTasks []Task
+	}{
+		{
+			name:          "Получить все задачи",
+			statusFilter:  "",
+			sortOrder:     "",
+			expectedTasks: []Task{task1, task2, task3},
+		},
+		{
+			name:          "Фильтрация по статусу 'в процессе'",
+			statusFilter:  "0",
+			sortOrder:     "",
+			expectedTasks: []Task{task1, task3},
+		},
+		{
+			name:          "Фильтрация по статусу 'завершено'",
+			statusFilter:  "1",
+			sortOrder:     "",
+			expectedTasks: []Task{task2},
+		},
+		{
+			name:          "Сортировка по возрастанию даты",
+			statusFilter:  "",
+			sortOrder:     "asc",
+			expectedTasks: []Task{task1, task2, task3},
+		},
+		{
+			name:          "Сортировка по убыванию даты",
+			statusFilter:  "",
+			sortOrder:     "desc",
+			expectedTasks: []Task{task3, task2, task1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			store := NewPostgresStore(db)
+
+			// Настройка ожидаемого запроса и возвращаемых данных.
+			rows := sqlmock.NewRows(taskRowColumns)
+			for _, task := range tc.expectedTasks {
+				rows.AddRow(task.ID, task.Text, task.CreatedDate, task.ExpectedDate, task.Status, task.Version, nil, 0, "")
+			}
+			mock.ExpectQuery("SELECT " + regexp.QuoteMeta(taskColumns) + " FROM tasks").WillReturnRows(rows)
+
+			// Вызов тестируемой функции.
+			page, err := store.GetAllTasks(TaskQuery{Owner: testOwner, Status: tc.statusFilter, SortOrder: tc.sortOrder})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedTasks, page.Tasks)
+		})
+	}
+}
+
+// Тест для функции CreateTask: задача и событие создания записываются в одной транзакции.
+func TestCreateTask(t *testing.T) {
+	task := Task{
+		Text:         "New Task",
+		Status:       StatusInProgress,
+		CreatedDate:  time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC),
+		ExpectedDate: time.Date(2023, 10, 2, 0, 0, 0, 0, time.UTC),
+		OwnerID:      7,
+		EditorID:     5,
+	}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+
+	// Настройка ожидаемых запросов и возвращаемого результата.
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO tasks").
+		WithArgs(task.Text, "2023-10-01", "2023-10-02", task.Status, int64(7), nil, PriorityNormal).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1))
+	mock.ExpectExec(insertEventQuery).
+		WithArgs(int64(1), int64(7), int64(5), EventCreated, nil, nil,
+			`{"text":"New Task","createdDate":"2023-10-01","expectedDate":"2023-10-02","status":0,"projectId":null,`+
+				`"priority":0,"tags":"","version":1}`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Вызов тестируемой функции.
+	id, err := store.CreateTask(task)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для функции UpdateTask: задача считывается с блокировкой строки, а смена статуса
+// записывается в журнал с пользователем и причиной в той же транзакции.
+func TestUpdateTask(t *testing.T) {
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	task := Task{
+		ID:           1,
+		Text:         "Updated Task",
+		Status:       StatusCompleted,
+		CreatedDate:  day,
+		ExpectedDate: day,
+		OwnerID:      testOwner,
+		ProjectID:    3,
+		EditorID:     5,
+		Reason:       "Ждет ревью",
+	}
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+
+	// Настройка ожидаемых запросов и возвращаемого результата.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT `+regexp.QuoteMeta(taskColumns)+`, owner_id, deleted_at FROM tasks `+
+		`WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL ORDER BY id FOR UPDATE$`).
+		WithArgs(task.ID, testOwner).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns).
+			AddRow(1, "Task", day, day, StatusInProgress, 1, 3, 0, "bug", testOwner, nil))
+	mock.ExpectExec(`^UPDATE tasks SET task_text = \$1, createdDate = \$2, expectedDate = \$3, status = \$4, `+
+		`project_id = \$5, priority = \$6, version = \$7 WHERE id = \$8$`).
+		WithArgs(task.Text, "2023-10-01", "2023-10-01", task.Status, int64(3), PriorityNormal, int64(2), task.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(insertEventQuery).
+		WithArgs(task.ID, testOwner, int64(5), EventStatusChanged, "Ждет ревью", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Вызов тестируемой функции.
+	version, err := store.UpdateTask(task)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для функции DeleteTask.
+func TestDeleteTask(t *testing.T) {
+	taskID := 1
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+
+	// Настройка ожидаемых запросов и возвращаемого результата.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL `+
+		`ORDER BY id FOR UPDATE$`).
+		WithArgs(int64(taskID), testOwner).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns).
+			AddRow(1, "Task", day, day, StatusInProgress, 2, nil, 0, "", testOwner, nil))
+	mock.ExpectExec(`^UPDATE tasks SET deleted_at = \$1, version = version \+ 1 WHERE id = \$2$`).
+		WithArgs(AnyTime{}, taskID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(insertEventQuery).
+		WithArgs(int64(taskID), testOwner, int64(5), EventDeleted, nil, sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Вызов тестируемой функции.
+	err = store.DeleteTask(testOwner, 5, taskID, 0)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода DeleteTask при отсутствии задачи.
+func TestDeleteTaskNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL `).
+		WithArgs(int64(42), testOwner).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns))
+	mock.ExpectRollback()
+
+	err = store.DeleteTask(testOwner, testOwner, 42, 0)
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода UpdateTask при отсутствии задачи.
+func TestUpdateTaskNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL `).
+		WithArgs(int64(42), testOwner).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns))
+	mock.ExpectRollback()
+
+	_, err = store.UpdateTask(Task{OwnerID: testOwner, ID: 42, Text: "Task", CreatedDate: day, ExpectedDate: day})
+
+	var notFound *NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+	assert.Equal(t, int64(42), notFound.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для проверки версии в методах UpdateTask и DeleteTask.
+func TestUpdateAndDeleteTaskVersionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	task := Task{ID: 1, Text: "Task 1", CreatedDate: day, ExpectedDate: day, Status: StatusInProgress, Version: 2,
+		OwnerID: testOwner}
+
+	// Задача существует, но её версия изменилась: транзакция откатывается без изменений.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL `).
+		WithArgs(task.ID, testOwner).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns).
+			AddRow(1, "Task 1", day, day, StatusInProgress, 3, nil, 0, "", testOwner, nil))
+	mock.ExpectRollback()
+
+	_, err = store.UpdateTask(task)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL `).
+		WithArgs(task.ID, testOwner).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns).
+			AddRow(1, "Task 1", day, day, StatusInProgress, 3, nil, 0, "", testOwner, nil))
+	mock.ExpectRollback()
+
+	err = store.DeleteTask(testOwner, testOwner, 1, 2)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для keyset-пагинации в методе GetAllTasks.
+func TestGetAllTasksPagination(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	q := TaskQuery{Owner: testOwner, Status: "0", SortField: "expectedDate", SortOrder: "desc", Limit: 1}
+
+	rows := sqlmock.NewRows(taskRowColumns).
+		AddRow(5, "Task 5", day, day.AddDate(0, 0, 3), StatusInProgress, 1, nil, 0, "").
+		AddRow(4, "Task 4", day, day.AddDate(0, 0, 2), StatusInProgress, 1, nil, 0, "")
+	mock.ExpectQuery(`^SELECT `+regexp.QuoteMeta(taskColumns)+` FROM tasks `+
+		`WHERE owner_id = \$1 AND deleted_at IS NULL AND status = \$2 ORDER BY expectedDate DESC, id DESC LIMIT 2$`).
+		WithArgs(testOwner, int64(StatusInProgress)).
+		WillReturnRows(rows)
+
+	page, err := store.GetAllTasks(q)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5}, taskIDs(page.Tasks))
+	assert.NotEmpty(t, page.NextCursor)
+
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \$1 AND deleted_at IS NULL AND status = \$2 `+
+		`AND \(expectedDate, id\) < \(\$3, \$4\) ORDER BY expectedDate DESC, id DESC LIMIT 2$`).
+		WithArgs(testOwner, int64(StatusInProgress), "2023-10-04", int64(5)).
+		WillReturnRows(sqlmock.NewRows(taskRowColumns))
+
+	q.After = page.NextCursor
+	page, err = store.GetAllTasks(q)
+	assert.NoError(t, err)
+	assert.Empty(t, page.Tasks)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для порядка по умолчанию в методе GetAllTasks: направления ключей различаются,
+// поэтому следующая страница выбирается сравнением ключей по очереди, а не кортежем.
+func TestGetAllTasksDefaultOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	q := TaskQuery{Owner: testOwner, Limit: 1}
+	columns := taskRowColumns
+
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \$1 AND deleted_at IS NULL ` +
+		`ORDER BY priority DESC, expectedDate, id LIMIT 2$`).
+		WithArgs(testOwner).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(5, "Task 5", day, day.AddDate(0, 0, 1), StatusInProgress, 1, nil, PriorityHigh, "").
+			AddRow(4, "Task 4", day, day.AddDate(0, 0, 2), StatusInProgress, 1, nil, PriorityHigh, ""))
+
+	page, err := store.GetAllTasks(q)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5}, taskIDs(page.Tasks))
+	assert.Equal(t, PriorityHigh, page.Tasks[0].Priority)
+
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \$1 AND deleted_at IS NULL `+
+		`AND \(priority < \$2 OR \(priority = \$2 AND \(expectedDate > \$3 OR \(expectedDate = \$3 AND id > \$4\)\)\)\) `+
+		`ORDER BY priority DESC, expectedDate, id LIMIT 2$`).
+		WithArgs(testOwner, PriorityHigh, "2023-10-02", int64(5)).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	q.After = page.NextCursor
+	page, err = store.GetAllTasks(q)
+	assert.NoError(t, err)
+	assert.Empty(t, page.Tasks)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для сортировки по нескольким ключам в методе GetAllTasks.
+func TestGetAllTasksSortKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	q := TaskQuery{Owner: testOwner, Sort: "-status,expectedDate", Limit: 1}
+	columns := taskRowColumns
+
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \$1 AND deleted_at IS NULL ` +
+		`ORDER BY status DESC, expectedDate, id LIMIT 2$`).
+		WithArgs(testOwner).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(2, "Task 2", day, day, StatusTesting, 1, nil, 0, "").
+			AddRow(1, "Task 1", day, day, StatusInProgress, 1, nil, 0, ""))
+
+	page, err := store.GetAllTasks(q)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2}, taskIDs(page.Tasks))
+
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \$1 AND deleted_at IS NULL `+
+		`AND \(status < \$2 OR \(status = \$2 AND \(expectedDate > \$3 OR \(expectedDate = \$3 AND id > \$4\)\)\)\) `+
+		`ORDER BY status DESC, expectedDate, id LIMIT 2$`).
+		WithArgs(testOwner, StatusTesting, "2023-10-01", int64(2)).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	q.After = page.NextCursor
+	page, err = store.GetAllTasks(q)
+	assert.NoError(t, err)
+	assert.Empty(t, page.Tasks)
+
+	_, err = store.GetAllTasks(TaskQuery{Owner: testOwner, Sort: "status; DROP TABLE tasks"})
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для полнотекстового поиска в методе GetAllTasks.
+func TestGetAllTasksSearch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows(append(taskRowColumns, "rank")).
+		AddRow(3, "Купить молоко", day, day, StatusInProgress, 1, nil, 0, "", 0.0607927).
+		AddRow(1, "Молоко", day, day, StatusInProgress, 1, nil, 0, "", 0.0303964)
+	mock.ExpectQuery(`^SELECT `+regexp.QuoteMeta(taskColumns)+`, `+
+		`ts_rank\(search_vector, to_tsquery\('simple', \$3\)\) FROM tasks `+
+		`WHERE owner_id = \$1 AND deleted_at IS NULL AND status = \$2 AND search_vector @@ to_tsquery\('simple', \$3\) `+
+		`ORDER BY ts_rank\(search_vector, to_tsquery\('simple', \$3\)\) DESC, id DESC LIMIT 2$`).
+		WithArgs(testOwner, int64(StatusInProgress), "(мол:*)").
+		WillReturnRows(rows)
+
+	q := TaskQuery{Owner: testOwner, Status: "0", Search: "мол*", Limit: 1}
+	page, err := store.GetAllTasks(q)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3}, taskIDs(page.Tasks))
+	assert.Equal(t, 0.0607927, page.Tasks[0].Rank)
+
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \$1 AND deleted_at IS NULL AND status = \$2 `+
+		`AND search_vector @@ to_tsquery\('simple', \$3\) `+
+		`AND \(ts_rank\(search_vector, to_tsquery\('simple', \$3\)\), id\) < \(\$4, \$5\) `+
+		`ORDER BY (.+) LIMIT 2$`).
+		WithArgs(testOwner, int64(StatusInProgress), "(мол:*)", 0.0607927, int64(3)).
+		WillReturnRows(sqlmock.NewRows(append(taskRowColumns, "rank")))
+
+	q.After = page.NextCursor
+	_, err = store.GetAllTasks(q)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода GetTaskByID.
+func TestGetTaskByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`^SELECT `+regexp.QuoteMeta(taskColumns)+
+		` FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL$`).
+		WithArgs(1, testOwner).
+		WillReturnRows(sqlmock.NewRows(taskRowColumns).
+			AddRow(1, "Task 1", day, day, StatusTesting, 3, 4, 0, ""))
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL$`).
+		WithArgs(2, testOwner).
+		WillReturnRows(sqlmock.NewRows(taskRowColumns))
+
+	task, err := store.GetTaskByID(testOwner, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, Task{ID: 1, Text: "Task 1", CreatedDate: day, ExpectedDate: day, Status: StatusTesting, Version: 3,
+		OwnerID: testOwner, ProjectID: 4}, task)
+
+	_, err = store.GetTaskByID(testOwner, 2)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Колонки строк, из которых PostgresStore собирает рабочие процессы: по строке на переход статуса.
+var workflowColumns = []string{"id", "project_id", "name", "title", "position", "color", "terminal", "name"}
+
+// Запрос, которым PostgresStore читает статусы и переходы всех рабочих процессов.
+const workflowsQuery = `^SELECT s.id, COALESCE\(s.project_id, 0\), s.name, (.+) FROM statuses s LEFT JOIN`
+
+// Тест для метода GetWorkflows: переходы статуса собираются из строк соединения таблиц,
+// а статусы группируются по рабочим процессам проектов.
+func TestGetWorkflows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+
+	rows := func() *sqlmock.Rows {
+		return sqlmock.NewRows(workflowColumns).
+			AddRow(0, 0, "in_progress", "В процессе", 10, "#2196f3", false, "testing").
+			AddRow(2, 0, "testing", "Тестирование", 20, "#ff9800", false, "returned").
+			AddRow(2, 0, "testing", "Тестирование", 20, "#ff9800", false, "completed").
+			AddRow(1, 0, "completed", "Завершено", 40, "#4caf50", true, nil).
+			AddRow(5, 7, "in_progress", "В работе", 10, "#2196f3", false, nil)
+	}
+	mock.ExpectQuery(workflowsQuery).WillReturnRows(rows())
+	mock.ExpectQuery(workflowsQuery).WillReturnRows(rows())
+
+	workflows, err := store.GetWorkflows()
+	assert.NoError(t, err)
+	assert.Equal(t, Workflow{Statuses: []StatusDef{
+		{ID: 0, Name: "in_progress", Title: "В процессе", Position: 10, Color: "#2196f3", Transitions: []string{"testing"}},
+		{ID: 2, Name: "testing", Title: "Тестирование", Position: 20, Color: "#ff9800",
+			Transitions: []string{"returned", "completed"}},
+		{ID: 1, Name: "completed", Title: "Завершено", Position: 40, Color: "#4caf50", Terminal: true,
+			Transitions: []string{}},
+	}}, workflows.Default)
+	assert.Equal(t, map[int64]Workflow{7: {Statuses: []StatusDef{
+		{ID: 5, Name: "in_progress", Title: "В работе", Position: 10, Color: "#2196f3", Transitions: []string{}},
+	}}}, workflows.Projects)
+
+	workflow, err := store.GetWorkflow(7)
+	assert.NoError(t, err)
+	assert.Equal(t, workflows.Projects[7], workflow)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода DeleteStatus: статус с задачами не удаляется.
+func TestDeleteStatusInUse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT id FROM statuses WHERE COALESCE\(project_id, 0\) = \$1 AND name = \$2$`).
+		WithArgs(int64(0), "testing").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery(`^SELECT EXISTS \(SELECT 1 FROM tasks WHERE status = \$1\)$`).
+		WithArgs(int64(StatusTesting)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, store.DeleteStatus(0, "testing"), ErrStatusInUse)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода CreateStatus в проекте без собственного рабочего процесса: в той же транзакции
+// проект получает копию рабочего процесса по умолчанию, а его задачи - статусы-копии.
+func TestCreateProjectStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	insertStatus := `^INSERT INTO statuses \(id, project_id, name, title, position, color, terminal\) ` +
+		`SELECT COALESCE\(MAX\(id\), -1\) \+ 1, \$1, \$2, \$3, \$4, \$5, \$6 FROM statuses RETURNING id$`
+	insertTransition := `^INSERT INTO status_transitions \(from_status, to_status\) ` +
+		`SELECT \$1, id FROM statuses WHERE COALESCE\(project_id, 0\) = \$2 AND name = \$3$`
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(workflowsQuery).
+		WillReturnRows(sqlmock.NewRows(workflowColumns).
+			AddRow(0, 0, "in_progress", "В процессе", 10, "#2196f3", false, "completed").
+			AddRow(1, 0, "completed", "Завершено", 40, "#4caf50", true, nil))
+	mock.ExpectQuery(insertStatus).
+		WithArgs(int64(7), "in_progress", "В процессе", 10, "#2196f3", false).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery(insertStatus).
+		WithArgs(int64(7), "completed", "Завершено", 40, "#4caf50", true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	for _, copy := range []struct {
+		id, source  int64
+		transitions []string
+	}{{id: 2, source: 0, transitions: []string{"completed"}}, {id: 3, source: 1}} {
+		mock.ExpectExec(`^DELETE FROM status_transitions WHERE from_status = \$1$`).
+			WithArgs(copy.id).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		for _, name := range copy.transitions {
+			mock.ExpectExec(insertTransition).
+				WithArgs(copy.id, int64(7), name).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectExec(`^UPDATE tasks SET status = \$1 WHERE project_id = \$2 AND status = \$3$`).
+			WithArgs(copy.id, int64(7), copy.source).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectQuery(`^SELECT EXISTS \(SELECT 1 FROM statuses WHERE COALESCE\(project_id, 0\) = \$1 AND name = \$2\)$`).
+		WithArgs(int64(7), "blocked").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(insertStatus).
+		WithArgs(int64(7), "blocked", "Заблокировано", 15, "#f44336", false).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectExec(`^DELETE FROM status_transitions WHERE from_status = \$1$`).
+		WithArgs(int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(insertTransition).
+		WithArgs(int64(4), int64(7), "in_progress").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	id, err := store.CreateStatus(7, StatusDef{Name: "blocked", Title: "Заблокировано", Position: 15, Color: "#f44336",
+		Transitions: []string{"in_progress"}})
+	assert.NoError(t, err)
+	assert.Equal(t, Status(4), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода GetTaskHistory.
+func TestGetTaskHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	at := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	created := `{"text":"Task","createdDate":"2023-10-01","expectedDate":"2023-10-02","status":0,"version":1}`
+
+	columns := []string{"id", "task_id", "event_type", "old_values", "new_values", "created_at", "actor_id", "reason"}
+	mock.ExpectQuery(`^SELECT id, task_id, event_type, old_values, new_values, created_at, actor_id, reason `+
+		`FROM task_events WHERE task_id = \$1 AND owner_id = \$2 ORDER BY id$`).
+		WithArgs(1, testOwner).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, 1, EventCreated, nil, created, at, nil, nil).
+			AddRow(2, 1, EventDeleted, created, nil, at, 5, nil))
+	mock.ExpectQuery(`^SELECT (.+) FROM task_events WHERE task_id = \$1 AND owner_id = \$2 ORDER BY id$`).
+		WithArgs(2, testOwner).
+		WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL$`).
+		WithArgs(2, testOwner).
+		WillReturnRows(sqlmock.NewRows(taskRowColumns))
+
+	events, err := store.GetTaskHistory(testOwner, 1)
+	assert.NoError(t, err)
+	snapshot := &TaskSnapshot{Text: "Task", CreatedDate: "2023-10-01", ExpectedDate: "2023-10-02",
+		Status: StatusInProgress, Version: 1}
+	assert.Equal(t, []TaskEvent{
+		{ID: 1, TaskID: 1, OwnerID: testOwner, Type: EventCreated, New: snapshot, CreatedAt: at},
+		{ID: 2, TaskID: 1, OwnerID: testOwner, ActorID: 5, Type: EventDeleted, Old: snapshot, CreatedAt: at},
+	}, events)
+
+	_, err = store.GetTaskHistory(testOwner, 2)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для выборки задач из корзины вместе со временем удаления.
+func TestGetAllTasksTrash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	deletedAt := time.Date(2023, 10, 5, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`^SELECT ` + regexp.QuoteMeta(taskColumns) + `, deleted_at FROM tasks ` +
+		`WHERE owner_id = \$1 AND deleted_at IS NOT NULL ORDER BY priority DESC, expectedDate, id$`).
+		WithArgs(testOwner).
+		WillReturnRows(sqlmock.NewRows(append(taskRowColumns, "deleted_at")).
+			AddRow(2, "Task 2", day, day, StatusInProgress, 2, nil, 0, "", deletedAt))
+
+	page, err := store.GetAllTasks(TaskQuery{Owner: testOwner, Deleted: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []Task{{ID: 2, Text: "Task 2", CreatedDate: day, ExpectedDate: day, Status: StatusInProgress,
+		Version: 2, DeletedAt: &deletedAt, OwnerID: testOwner}}, page.Tasks)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода RestoreTask.
+func TestRestoreTask(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NOT NULL `+
+		`ORDER BY id FOR UPDATE$`).
+		WithArgs(int64(1), testOwner).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns).
+			AddRow(1, "Task", day, day, StatusInProgress, 2, nil, 0, "", testOwner, day))
+	mock.ExpectExec(`^UPDATE tasks SET deleted_at = NULL, version = version \+ 1 WHERE id = \$1$`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(insertEventQuery).
+		WithArgs(int64(1), testOwner, testOwner, EventRestored, nil, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NOT NULL `).
+		WithArgs(int64(2), testOwner).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns))
+	mock.ExpectRollback()
+
+	version, err := store.RestoreTask(testOwner, testOwner, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), version)
+
+	_, err = store.RestoreTask(testOwner, testOwner, 2)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода PurgeDeletedTasks: каждая удаленная задача получает в журнале событие без пользователя.
+func TestPurgeDeletedTasks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	before := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	day := before.AddDate(0, 0, -40)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE deleted_at IS NOT NULL AND deleted_at < \$1 ` +
+		`ORDER BY id FOR UPDATE$`).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns).
+			AddRow(1, "Task 1", day, day, StatusInProgress, 2, nil, 0, "", testOwner, day).
+			AddRow(2, "Task 2", day, day, StatusInProgress, 3, nil, 0, "", nil, day))
+	for _, id := range []int64{1, 2} {
+		owner := interface{}(testOwner)
+		if id == 2 {
+			owner = nil
+		}
+		mock.ExpectExec(`^DELETE FROM tasks WHERE id = \$1$`).
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(insertEventQuery).
+			WithArgs(id, owner, nil, EventPurged, nil, sqlmock.AnyArg(), nil).
+			WillReturnResult(sqlmock.NewResult(id, 1))
+	}
+	mock.ExpectCommit()
+
+	purged, err := store.PurgeDeletedTasks(before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода ClaimOwnerlessTasks: задачи без автора и их журнал передаются существующему
+// пользователю в одной транзакции, а для несуществующего пользователя ничего не меняется.
+func TestClaimOwnerlessTasks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	userQuery := `^SELECT EXISTS \(SELECT 1 FROM users WHERE id = \$1\)$`
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(userQuery).WithArgs(int64(2)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectQuery(userQuery).WithArgs(testOwner).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(`^UPDATE tasks SET owner_id = \$1 WHERE owner_id IS NULL$`).
+		WithArgs(testOwner).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`^UPDATE task_events SET owner_id = \$1 WHERE owner_id IS NULL$`).
+		WithArgs(testOwner).
+		WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectCommit()
+
+	_, err = store.ClaimOwnerlessTasks(2)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	claimed, err := store.ClaimOwnerlessTasks(testOwner)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), claimed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода CreateUser: занятое имя определяется по пустому результату ON CONFLICT DO NOTHING.
+func TestCreateUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+
+	mock.ExpectQuery(`^INSERT INTO users \(username, password_hash\) VALUES \(\$1, \$2\) `+
+		`ON CONFLICT \(username\) DO NOTHING RETURNING id$`).
+		WithArgs("alice", "hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`^INSERT INTO users (.+) RETURNING id$`).
+		WithArgs("alice", "hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	id, err := store.CreateUser(User{Username: "alice", PasswordHash: "hash"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+
+	_, err = store.CreateUser(User{Username: "alice", PasswordHash: "hash"})
+	assert.ErrorIs(t, err, ErrUserExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода GetSessionUser.
+func TestGetSessionUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`^SELECT u.id, u.username, u.password_hash, u.created_at FROM sessions s `+
+		`JOIN users u ON u.id = s.user_id WHERE s.token_hash = \$1 AND s.expires_at > \$2$`).
+		WithArgs("hash", now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password_hash", "created_at"}).
+			AddRow(1, "alice", "bcrypt", now))
+	mock.ExpectQuery(`^SELECT (.+) FROM sessions s`).
+		WithArgs("missing", now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password_hash", "created_at"}))
+
+	user, err := store.GetSessionUser("hash", now)
+	assert.NoError(t, err)
+	assert.Equal(t, User{ID: 1, Username: "alice", PasswordHash: "bcrypt", CreatedAt: now}, user)
+
+	_, err = store.GetSessionUser("missing", now)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода CreateAPIToken: разрешения сохраняются одной строкой через пробел.
+func TestCreateAPIToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	expires := time.Date(2023, 12, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`^INSERT INTO api_tokens \(user_id, name, token_hash, scopes, expires_at\) `+
+		`VALUES \(\$1, \$2, \$3, \$4, \$5\) RETURNING id$`).
+		WithArgs(1, "ci", "hash", "tasks:read tasks:write", expires).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	id, err := store.CreateAPIToken(APIToken{
+		UserID: 1, Name: "ci", TokenHash: "hash",
+		Scopes: []string{ScopeTasksRead, ScopeTasksWrite}, ExpiresAt: expires,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода GetTokenUser: время использования записывается тем же запросом, что проверяет срок действия.
+func TestGetTokenUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "user_id", "name", "token_hash", "scopes", "expires_at", "last_used_at", "created_at"}
+
+	mock.ExpectQuery(`^UPDATE api_tokens SET last_used_at = \$2 WHERE token_hash = \$1 AND expires_at > \$2 `+
+		`RETURNING id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at$`).
+		WithArgs("hash", now).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(3, 1, "ci", "hash", "tasks:read", now.Add(time.Hour), now, now))
+	mock.ExpectQuery(`^SELECT id, username, password_hash, created_at FROM users WHERE id = \$1$`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password_hash", "created_at"}).
+			AddRow(1, "alice", "bcrypt", now))
+	mock.ExpectQuery(`^UPDATE api_tokens`).
+		WithArgs("missing", now).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	user, token, err := store.GetTokenUser("hash", now)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", user.Username)
+	assert.Equal(t, []string{ScopeTasksRead}, token.Scopes)
+	assert.Equal(t, &now, token.LastUsedAt)
+
+	_, _, err = store.GetTokenUser("missing", now)
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода DeleteAPIToken: чужой или отсутствующий токен не удаляется.
+func TestDeleteAPIToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+
+	mock.ExpectExec(`^DELETE FROM api_tokens WHERE id = \$1 AND user_id = \$2$`).
+		WithArgs(3, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^DELETE FROM api_tokens`).
+		WithArgs(3, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, store.DeleteAPIToken(1, 3))
+	assert.ErrorIs(t, store.DeleteAPIToken(2, 3), ErrTokenNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода GetListRole: роль автора списка определяется без запроса к базе данных.
+func TestGetListRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+
+	mock.ExpectQuery(`^SELECT role FROM list_members WHERE owner_id = \$1 AND user_id = \$2$`).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("viewer"))
+	mock.ExpectQuery(`^SELECT role FROM list_members`).
+		WithArgs(1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}))
+
+	role, err := store.GetListRole(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, RoleAdmin, role)
+	role, err = store.GetListRole(1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, RoleViewer, role)
+	_, err = store.GetListRole(1, 3)
+	assert.ErrorIs(t, err, ErrListNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода SetListMember: роль существующего участника обновляется тем же запросом.
+func TestSetListMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+
+	mock.ExpectExec(`^INSERT INTO list_members \(owner_id, user_id, role\) VALUES \(\$1, \$2, \$3\) `+
+		`ON CONFLICT \(owner_id, user_id\) DO UPDATE SET role = excluded.role$`).
+		WithArgs(1, 2, "editor").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, store.SetListMember(1, 2, RoleEditor))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для фильтра по проекту в методе GetAllTasks.
+func TestGetAllTasksProjectFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	columns := taskRowColumns
+
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \$1 AND deleted_at IS NULL AND project_id = \$2 `+
+		`ORDER BY priority DESC, expectedDate, id$`).
+		WithArgs(testOwner, int64(3)).
+		WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \$1 AND deleted_at IS NULL AND project_id IS NULL ` +
+		`ORDER BY priority DESC, expectedDate, id$`).
+		WithArgs(testOwner).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	_, err = store.GetAllTasks(TaskQuery{Owner: testOwner, Project: "3"})
+	assert.NoError(t, err)
+	_, err = store.GetAllTasks(TaskQuery{Owner: testOwner, Project: NoProject})
+	assert.NoError(t, err)
+	_, err = store.GetAllTasks(TaskQuery{Owner: testOwner, Project: "abc"})
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для фильтра по меткам в методе GetAllTasks: любая из меток - EXISTS, все метки - подсчет совпадений.
+func TestGetAllTasksTagFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	columns := taskRowColumns
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \$1 AND deleted_at IS NULL AND status = \$2 `+
+		`AND EXISTS \(SELECT 1 FROM task_tags tt JOIN tags g ON g\.id = tt\.tag_id `+
+		`WHERE tt\.task_id = tasks\.id AND g\.name IN \(\$3, \$4\)\) ORDER BY expectedDate, id$`).
+		WithArgs(testOwner, int64(StatusInProgress), "bug", "ui").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Task 1", day, day, StatusInProgress, 1, nil, 0, "ui,bug"))
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \$1 AND deleted_at IS NULL `+
+		`AND \(SELECT COUNT\(\*\) FROM task_tags tt JOIN tags g ON g\.id = tt\.tag_id `+
+		`WHERE tt\.task_id = tasks\.id AND g\.name IN \(\$2, \$3\)\) = 2 ORDER BY priority DESC, expectedDate, id$`).
+		WithArgs(testOwner, "bug", "ui").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	page, err := store.GetAllTasks(TaskQuery{
+		Owner: testOwner, Status: "0", Tags: []string{"bug", "ui"}, SortField: "expectedDate",
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, page.Tasks, 1) {
+		assert.Equal(t, []string{"bug", "ui"}, page.Tasks[0].Tags)
+	}
+	_, err = store.GetAllTasks(TaskQuery{Owner: testOwner, Tags: []string{"bug", "ui"}, TagMatch: TagMatchAll})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для замены меток в методе UpdateTask: задача, метки и событие журнала изменяются в одной транзакции.
+func TestUpdateTaskTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	task := Task{ID: 1, Text: "Task", CreatedDate: day, ExpectedDate: day, Status: StatusInProgress, OwnerID: testOwner,
+		Tags: []string{"bug"}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL `).
+		WithArgs(task.ID, testOwner).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns).
+			AddRow(1, "Task", day, day, StatusInProgress, 1, nil, 0, "", testOwner, nil))
+	mock.ExpectExec(`^UPDATE tasks SET (.+), version = \$7 WHERE id = \$8$`).
+		WithArgs(task.Text, "2023-10-01", "2023-10-01", task.Status, nil, PriorityNormal, int64(2), task.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^DELETE FROM task_tags WHERE task_id = \$1$`).
+		WithArgs(task.ID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`^INSERT INTO tags \(owner_id, name\) VALUES \(\$1, \$2\) ON CONFLICT \(owner_id, name\) DO NOTHING$`).
+		WithArgs(testOwner, "bug").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`^INSERT INTO task_tags \(task_id, tag_id\) `+
+		`SELECT \$1, id FROM tags WHERE owner_id = \$2 AND name = \$3$`).
+		WithArgs(task.ID, testOwner, "bug").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(insertEventQuery).
+		WithArgs(task.ID, testOwner, nil, EventUpdated, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	version, err := store.UpdateTask(task)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для методов CreateProject и UpdateProject: занятое имя определяется по результату запроса.
+func TestCreateAndUpdateProject(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+
+	mock.ExpectQuery(`^INSERT INTO projects \(owner_id, name\) VALUES \(\$1, \$2\) `+
+		`ON CONFLICT \(owner_id, name\) DO NOTHING RETURNING id$`).
+		WithArgs(testOwner, "Дом").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(`^INSERT INTO projects`).
+		WithArgs(testOwner, "Дом").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	id, err := store.CreateProject(Project{OwnerID: testOwner, Name: "Дом"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), id)
+	_, err = store.CreateProject(Project{OwnerID: testOwner, Name: "Дом"})
+	assert.ErrorIs(t, err, ErrProjectExists)
+
+	// Проект существует, но имя занято другим проектом.
+	mock.ExpectQuery(`^UPDATE projects SET name = \$1 WHERE id = \$2 AND owner_id = \$3 (.+) RETURNING id$`).
+		WithArgs("Работа", int64(3), testOwner).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`^SELECT id, name, created_at FROM projects WHERE id = \$1 AND owner_id = \$2$`).
+		WithArgs(int64(3), testOwner).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at"}).AddRow(3, "Дом", time.Now()))
+
+	err = store.UpdateProject(Project{ID: 3, OwnerID: testOwner, Name: "Работа"})
+	assert.ErrorIs(t, err, ErrProjectExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Тест для метода DeleteProject: задачи убираются из проекта в той же транзакции, а каждое изменение
+// записывается в журнал.
+func TestDeleteProject(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE project_id = \$1 AND owner_id = \$2 ORDER BY id FOR UPDATE$`).
+		WithArgs(int64(3), testOwner).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns).
+			AddRow(1, "Task", day, day, 4, 1, 3, 0, "", testOwner, nil).
+			AddRow(2, "Task", day, day, 5, 1, 3, 0, "", testOwner, nil))
+	// Статус "testing" есть в рабочем процессе по умолчанию, а "review" - только в проекте.
+	mock.ExpectQuery(workflowsQuery).
+		WillReturnRows(sqlmock.NewRows(workflowColumns).
+			AddRow(0, 0, "in_progress", "В процессе", 10, "#2196f3", false, nil).
+			AddRow(2, 0, "testing", "Тестирование", 20, "#ff9800", false, nil).
+			AddRow(4, 3, "testing", "Тестирование", 20, "#ff9800", false, nil).
+			AddRow(5, 3, "review", "Ревью", 25, "#795548", false, nil))
+	for _, task := range []struct {
+		id, status int64
+		eventType  string
+	}{{id: 1, status: 2, eventType: EventStatusChanged}, {id: 2, status: 0, eventType: EventStatusChanged}} {
+		mock.ExpectExec(`^UPDATE tasks SET project_id = NULL, status = \$1, version = version \+ 1 WHERE id = \$2$`).
+			WithArgs(task.status, task.id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(insertEventQuery).
+			WithArgs(task.id, testOwner, int64(5), task.eventType, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(task.id, 1))
+	}
+	mock.ExpectExec(`^DELETE FROM projects WHERE id = \$1 AND owner_id = \$2$`).
+		WithArgs(int64(3), testOwner).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^DELETE FROM status_transitions WHERE from_status IN ` +
+		`\(SELECT id FROM statuses WHERE project_id = \$1\)$`).
+		WithArgs(int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`^DELETE FROM statuses WHERE project_id = \$1$`).
+		WithArgs(int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE project_id = \$1`).
+		WithArgs(int64(4), testOwner).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns))
+	mock.ExpectQuery(workflowsQuery).WillReturnRows(sqlmock.NewRows(workflowColumns))
+	mock.ExpectExec(`^DELETE FROM projects`).
+		WithArgs(int64(4), testOwner).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.NoError(t, store.DeleteProject(testOwner, 5, 3))
+	assert.ErrorIs(t, store.DeleteProject(testOwner, testOwner, 4), ErrProjectNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,39 @@
+package db
+
+// Тип Priority - приоритет задачи. В базе данных хранится числом, поэтому задачи
+// сортируются по приоритету как по обычной колонке; нулевое значение - обычный приоритет.
+type Priority int
+
+// Приоритеты задачи в порядке возрастания важности.
+const (
+	PriorityLow Priority = iota - 1
+	PriorityNormal
+	PriorityHigh
+	PriorityUrgent
+)
+
+// Имена приоритетов в API в порядке возрастания важности, начиная с PriorityLow.
+var priorityNames = []string{"low", "normal", "high", "urgent"}
+
+// Метод String возвращает имя приоритета в API (напр. "urgent").
+func (p Priority) String() string {
+	if i := int(p - PriorityLow); i >= 0 && i < len(priorityNames) {
+		return priorityNames[i]
+	}
+	return "unknown"
+}
+
+// Функция ParsePriority возвращает приоритет по имени; ok равен false для неизвестного имени.
+func ParsePriority(name string) (priority Priority, ok bool) {
+	for i, known := range priorityNames {
+		if known == name {
+			return PriorityLow + Priority(i), true
+		}
+	}
+	return 0, false
+}
+
+// Функция PriorityNames возвращает имена всех приоритетов в порядке возрастания важности.
+func PriorityNames() []string {
+	return append([]string(nil), priorityNames...)
+}
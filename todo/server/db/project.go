@@ -0,0 +1,54 @@
+package db
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrProjectNotFound возвращается хранилищем, если проекта нет в списке задач пользователя.
+var ErrProjectNotFound = errors.New("project not found")
+
+// ErrProjectExists возвращается при создании или переименовании проекта в уже занятое имя.
+var ErrProjectExists = errors.New("project already exists")
+
+// Структура Project представляет проект - группу задач внутри списка задач пользователя OwnerID.
+type Project struct {
+	ID        int64
+	OwnerID   int64
+	Name      string
+	CreatedAt time.Time
+}
+
+// Вспомогательная структура для сериализации Project.
+type ProjectDTO struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Метод для преобразования Project в ProjectDTO.
+func (p *Project) ToDTO() ProjectDTO {
+	return ProjectDTO{ID: p.ID, Name: p.Name}
+}
+
+// Структура ProjectRequest - тело запроса на создание или переименование проекта.
+type ProjectRequest struct {
+	Name string `json:"name"`
+}
+
+// Интерфейс ProjectStore описывает хранилище проектов. Проекты, как и задачи, принадлежат
+// списку задач пользователя: методы работают только с проектами указанного пользователя owner.
+type ProjectStore interface {
+	// GetProjects возвращает проекты пользователя owner в порядке имен.
+	GetProjects(owner int64) ([]Project, error)
+	// GetProject возвращает проект пользователя owner или ErrProjectNotFound.
+	GetProject(owner, id int64) (Project, error)
+	// CreateProject сохраняет новый проект пользователя project.OwnerID и возвращает его ID.
+	// Если имя уже занято, возвращается ErrProjectExists.
+	CreateProject(project Project) (int64, error)
+	// UpdateProject переименовывает проект пользователя project.OwnerID.
+	// Возвращает ErrProjectNotFound или, если имя уже занято, ErrProjectExists.
+	UpdateProject(project Project) error
+	// DeleteProject удаляет проект пользователя owner от имени пользователя actor или возвращает
+	// ErrProjectNotFound. Задачи проекта, в том числе из корзины, остаются без проекта, а их версия увеличивается.
+	DeleteProject(owner, actor, id int64) error
+}
@@ -0,0 +1,322 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxPageSize - максимальное количество задач на одной странице.
+const MaxPageSize = 500
+
+// ErrInvalidCursor возвращается, если курсор страницы поврежден или выдан для другой сортировки.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInvalidQuery возвращается хранилищем, если параметры выборки задач некорректны.
+var ErrInvalidQuery = errors.New("invalid task query")
+
+// Структура TaskQuery описывает параметры выборки задач: фильтрацию, сортировку и пагинацию.
+type TaskQuery struct {
+	// Owner - идентификатор пользователя, задачи которого выбираются.
+	Owner int64
+	// Status - фильтр по номерам статусов через запятую (пустая строка - без фильтра).
+	// Имена статусов разрешаются через Workflows.StatusIDs до обращения к хранилищу: у проектов
+	// с собственным рабочим процессом статус с тем же именем имеет другой номер.
+	Status string
+	// Project - фильтр по ID проекта: "none" - задачи без проекта, пустая строка - без фильтра.
+	Project string
+	// Tags - фильтр по именам меток (пустой - без фильтра).
+	Tags []string
+	// TagMatch - режим фильтра по меткам: TagMatchAny (по умолчанию) или TagMatchAll.
+	TagMatch string
+	// Search - полнотекстовый поиск по тексту задачи (синтаксис описан в parseSearch).
+	Search string
+	// SortField - поле сортировки из белого списка validSortFields (по умолчанию задачи
+	// упорядочиваются по убыванию приоритета, затем по сроку выполнения, а при поиске - по релевантности).
+	SortField string
+	// SortOrder - направление сортировки: "asc" или "desc". Для сортировки по умолчанию
+	// "desc" обращает весь порядок.
+	SortOrder string
+	// Sort - ключи сортировки через запятую вида "-status,expectedDate,id": поля из белого списка
+	// validSortFields, минус перед полем означает сортировку по убыванию. Заменяет SortField и SortOrder.
+	Sort string
+	// Limit - размер страницы (0 - без ограничения).
+	Limit int
+	// After - курсор, полученный вместе с предыдущей страницей.
+	After string
+	// Deleted - выборка задач из корзины вместо обычного списка.
+	Deleted bool
+}
+
+// Структура TaskPage представляет страницу задач и курсор следующей страницы.
+type TaskPage struct {
+	Tasks []Task
+	// NextCursor пуст, если страница последняя.
+	NextCursor string
+}
+
+// Структура sortKey - ключ сортировки выборки: поле из белого списка validSortFields
+// (или "rank" - релевантность при поиске) и направление.
+type sortKey struct {
+	Field string
+	Desc  bool
+}
+
+// Метод orderKeys возвращает ключи сортировки выборки с учетом порядка по умолчанию.
+// Последний ключ всегда id (если он не задан явно - с направлением предыдущего ключа), чтобы порядок был однозначным
+// и по нему можно было листать страницы курсором. Релевантные задачи всегда идут первыми.
+func (q TaskQuery) orderKeys() []sortKey {
+	desc := q.SortOrder == "desc"
+	var keys []sortKey
+	switch {
+	case q.Sort != "":
+		// Ключи проверены в q.validate. ID задач различны, поэтому ключи после id не влияют на порядок.
+		keys, _ = parseSortKeys(q.Sort)
+		if i := slices.IndexFunc(keys, func(key sortKey) bool { return key.Field == "id" }); i >= 0 {
+			return keys[:i+1]
+		}
+	case q.SortField != "":
+		keys = []sortKey{{Field: q.SortField, Desc: desc}}
+	case q.searching():
+		keys = []sortKey{{Field: "rank", Desc: true}}
+	default:
+		// Сначала важные задачи, а при равном приоритете - те, срок которых наступает раньше.
+		keys = []sortKey{{Field: "priority", Desc: !desc}, {Field: "expectedDate", Desc: desc}}
+	}
+	if n := len(keys); n == 0 || keys[n-1].Field != "id" {
+		keys = append(keys, sortKey{Field: "id", Desc: n > 0 && keys[n-1].Desc})
+	}
+	return keys
+}
+
+// Функция parseSortKeys разбирает ключи сортировки вида "-status,expectedDate,id".
+// Каждое поле должно входить в белый список validSortFields и встречаться один раз.
+func parseSortKeys(spec string) ([]sortKey, error) {
+	var keys []sortKey
+	seen := map[string]bool{}
+	for _, field := range strings.Split(spec, ",") {
+		key := sortKey{Field: strings.TrimSpace(field)}
+		if rest, ok := strings.CutPrefix(key.Field, "-"); ok {
+			key.Field, key.Desc = rest, true
+		}
+		if !validSortFields[key.Field] {
+			return nil, fmt.Errorf("%w: invalid sort key: %q", ErrInvalidQuery, field)
+		}
+		if seen[key.Field] {
+			return nil, fmt.Errorf("%w: duplicate sort key: %s", ErrInvalidQuery, key.Field)
+		}
+		seen[key.Field] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Функция sortSpec записывает ключи сортировки строкой вида "-priority,expectedDate,id",
+// где минус означает сортировку по убыванию.
+func sortSpec(keys []sortKey) string {
+	fields := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key.Desc {
+			fields = append(fields, "-"+key.Field)
+		} else {
+			fields = append(fields, key.Field)
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// Метод searching сообщает, задан ли полнотекстовый поиск.
+func (q TaskQuery) searching() bool {
+	return strings.TrimSpace(q.Search) != ""
+}
+
+// Метод validate проверяет поля и ключи сортировки, размер страницы и фильтры.
+func (q TaskQuery) validate() error {
+	if q.SortField != "" && !validSortFields[q.SortField] {
+		return fmt.Errorf("%w: invalid sort field: %s", ErrInvalidQuery, q.SortField)
+	}
+	if q.Sort != "" {
+		if q.SortField != "" || q.SortOrder != "" {
+			return fmt.Errorf("%w: sort keys cannot be combined with sort field or order", ErrInvalidQuery)
+		}
+		if _, err := parseSortKeys(q.Sort); err != nil {
+			return err
+		}
+	}
+	if q.Limit < 0 || q.Limit > MaxPageSize {
+		return fmt.Errorf("%w: invalid limit: %d", ErrInvalidQuery, q.Limit)
+	}
+	if _, _, err := q.statusFilter(); err != nil {
+		return err
+	}
+	if _, _, err := q.projectFilter(); err != nil {
+		return err
+	}
+	if q.TagMatch != "" && q.TagMatch != TagMatchAny && q.TagMatch != TagMatchAll {
+		return fmt.Errorf("%w: invalid tag match: %q", ErrInvalidQuery, q.TagMatch)
+	}
+	return nil
+}
+
+// Метод statusFilter возвращает статусы из фильтра; ok равен false, если фильтр не задан.
+func (q TaskQuery) statusFilter() (statuses []Status, ok bool, err error) {
+	if q.Status == "" {
+		return nil, false, nil
+	}
+	for _, field := range strings.Split(q.Status, ",") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: invalid status filter: %q", ErrInvalidQuery, q.Status)
+		}
+		statuses = append(statuses, Status(n))
+	}
+	return statuses, true, nil
+}
+
+// Значение фильтра по проекту, выбирающее задачи без проекта.
+const NoProject = "none"
+
+// Метод projectFilter возвращает ID проекта из фильтра (0 - задачи без проекта);
+// ok равен false, если фильтр не задан.
+func (q TaskQuery) projectFilter() (project int64, ok bool, err error) {
+	switch q.Project {
+	case "":
+		return 0, false, nil
+	case NoProject:
+		return 0, true, nil
+	}
+	project, err = strconv.ParseInt(q.Project, 10, 64)
+	if err != nil || project <= 0 {
+		return 0, false, fmt.Errorf("%w: invalid project filter: %q", ErrInvalidQuery, q.Project)
+	}
+	return project, true, nil
+}
+
+// Метод matchAllTags сообщает, должны ли у задачи быть все метки фильтра, а не хотя бы одна.
+func (q TaskQuery) matchAllTags() bool {
+	return q.TagMatch == TagMatchAll
+}
+
+// Структура pageCursor - содержимое курсора: значения ключей сортировки и ID последней задачи страницы.
+// Ключи сортировки сохраняются, чтобы курсор нельзя было применить к другой выборке.
+type pageCursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	ID     int64    `json:"id"`
+}
+
+// Функция encodeCursor создает курсор, указывающий на задачу task в выборке query.
+func encodeCursor(task Task, query TaskQuery) string {
+	keys := query.orderKeys()
+	c := pageCursor{Sort: sortSpec(keys), ID: task.ID}
+	for _, key := range keys[:len(keys)-1] {
+		c.Values = append(c.Values, formatSortValue(task, key.Field))
+	}
+
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Функция decodeCursor разбирает курсор выборки query и возвращает задачу-ориентир,
+// у которой заполнены ID и поля сортировки.
+func decodeCursor(cursor string, query TaskQuery) (Task, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return Task{}, ErrInvalidCursor
+	}
+
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Task{}, ErrInvalidCursor
+	}
+	keys := query.orderKeys()
+	if c.Sort != sortSpec(keys) || len(c.Values) != len(keys)-1 {
+		return Task{}, ErrInvalidCursor
+	}
+
+	task := Task{ID: c.ID}
+	for i, value := range c.Values {
+		if err := parseSortValue(&task, keys[i].Field, value); err != nil {
+			return Task{}, ErrInvalidCursor
+		}
+	}
+	return task, nil
+}
+
+// Функция formatSortValue записывает значение поля сортировки задачи в курсор.
+func formatSortValue(task Task, field string) string {
+	switch field {
+	case "task_text":
+		return task.Text
+	case "createdDate":
+		return task.CreatedDate.Format("2006-01-02")
+	case "expectedDate":
+		return task.ExpectedDate.Format("2006-01-02")
+	case "status":
+		return strconv.Itoa(int(task.Status))
+	case "priority":
+		return strconv.Itoa(int(task.Priority))
+	case "rank":
+		return strconv.FormatFloat(task.Rank, 'g', -1, 64)
+	}
+	return ""
+}
+
+// Функция parseSortValue разбирает значение поля сортировки из курсора в задачу task.
+func parseSortValue(task *Task, field, value string) error {
+	var err error
+	var n int
+	switch field {
+	case "task_text":
+		task.Text = value
+	case "createdDate":
+		task.CreatedDate, err = time.Parse("2006-01-02", value)
+	case "expectedDate":
+		task.ExpectedDate, err = time.Parse("2006-01-02", value)
+	case "status":
+		n, err = strconv.Atoi(value)
+		task.Status = Status(n)
+	case "priority":
+		n, err = strconv.Atoi(value)
+		task.Priority = Priority(n)
+	case "rank":
+		task.Rank, err = strconv.ParseFloat(value, 64)
+	}
+	return err
+}
+
+// Функция newTaskPage обрезает выборку до размера страницы и формирует курсор следующей страницы.
+// Хранилища запрашивают на одну задачу больше лимита: ее наличие означает, что страница не последняя.
+func newTaskPage(tasks []Task, q TaskQuery) TaskPage {
+	if q.Limit == 0 || len(tasks) <= q.Limit {
+		return TaskPage{Tasks: tasks}
+	}
+
+	tasks = tasks[:q.Limit]
+	return TaskPage{Tasks: tasks, NextCursor: encodeCursor(tasks[len(tasks)-1], q)}
+}
+
+// Функция cursorValue возвращает значение поля сортировки задачи-ориентира в виде параметра SQL-запроса.
+func cursorValue(task Task, field string) interface{} {
+	switch field {
+	case "task_text":
+		return task.Text
+	case "createdDate":
+		return task.CreatedDate.Format("2006-01-02")
+	case "expectedDate":
+		return task.ExpectedDate.Format("2006-01-02")
+	case "status":
+		return task.Status
+	case "priority":
+		return task.Priority
+	case "rank":
+		return task.Rank
+	default:
+		return task.ID
+	}
+}
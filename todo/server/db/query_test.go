@@ -0,0 +1,140 @@
+package db
+
+import (
+	"cmp"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Функция collectPages листает выборку страницами по limit задач и возвращает ID в порядке получения.
+func collectPages(t *testing.T, store TaskStore, q TaskQuery) []int64 {
+	t.Helper()
+	var ids []int64
+	for i := 0; ; i++ {
+		if i > 100 {
+			t.Fatal("pagination did not terminate")
+		}
+		page, err := store.GetAllTasks(q)
+		if err != nil {
+			t.Fatalf("could not get page: %v", err)
+		}
+		assert.LessOrEqual(t, len(page.Tasks), q.Limit)
+		ids = append(ids, taskIDs(page.Tasks)...)
+		if page.NextCursor == "" {
+			return ids
+		}
+		q.After = page.NextCursor
+	}
+}
+
+// Функция testPagination проверяет, что постраничный обход совпадает с выборкой без лимита
+// для всех полей сортировки, порядка по умолчанию, направлений и нескольких ключей сортировки.
+func testPagination(t *testing.T, store TaskStore) {
+	t.Helper()
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 7; i++ {
+		_, err := store.CreateTask(Task{
+			OwnerID:      testOwner,
+			Text:         fmt.Sprintf("Task %d", i%3),
+			CreatedDate:  day,
+			ExpectedDate: day.AddDate(0, 0, i%4),
+			Status:       Status(i % 2),
+			Priority:     Priority(i%4) - 1,
+		})
+		assert.NoError(t, err)
+	}
+
+	fields := []string{""}
+	for field := range validSortFields {
+		fields = append(fields, field)
+	}
+	for _, field := range fields {
+		for _, order := range []string{"asc", "desc"} {
+			t.Run(cmp.Or(field, "default")+" "+order, func(t *testing.T) {
+				q := TaskQuery{Owner: testOwner, SortField: field, SortOrder: order}
+				full, err := store.GetAllTasks(q)
+				assert.NoError(t, err)
+				assert.Len(t, full.Tasks, 7)
+				assert.Empty(t, full.NextCursor)
+
+				q.Limit = 2
+				assert.Equal(t, taskIDs(full.Tasks), collectPages(t, store, q))
+			})
+		}
+	}
+
+	for _, sort := range []string{"-status,expectedDate", "priority,-task_text,createdDate", "status,-id,expectedDate"} {
+		t.Run(sort, func(t *testing.T) {
+			q := TaskQuery{Owner: testOwner, Sort: sort}
+			full, err := store.GetAllTasks(q)
+			assert.NoError(t, err)
+			assert.Len(t, full.Tasks, 7)
+
+			q.Limit = 3
+			assert.Equal(t, taskIDs(full.Tasks), collectPages(t, store, q))
+		})
+	}
+
+	t.Run("status filter", func(t *testing.T) {
+		assert.Len(t, collectPages(t, store, TaskQuery{Owner: testOwner, Status: "1", Limit: 1}), 3)
+	})
+}
+
+// Тест для кодирования и разбора курсора.
+func TestCursorRoundTrip(t *testing.T) {
+	q := TaskQuery{Owner: testOwner, SortField: "expectedDate", SortOrder: "desc"}
+	task := Task{ID: 7, ExpectedDate: time.Date(2023, 10, 5, 0, 0, 0, 0, time.UTC)}
+
+	after, err := decodeCursor(encodeCursor(task, q), q)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), after.ID)
+	assert.True(t, after.ExpectedDate.Equal(task.ExpectedDate))
+}
+
+// Тест для отклонения поврежденных и чужих курсоров.
+func TestCursorInvalid(t *testing.T) {
+	q := TaskQuery{Owner: testOwner, SortField: "status"}
+	cursor := encodeCursor(Task{ID: 1, Status: StatusTesting}, q)
+
+	_, err := decodeCursor(cursor, TaskQuery{SortField: "id"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = decodeCursor(cursor, TaskQuery{SortField: "status", SortOrder: "desc"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = decodeCursor("not a cursor!", q)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+// Тест для разбора ключей сортировки: поля проверяются по белому списку и не повторяются.
+func TestParseSortKeys(t *testing.T) {
+	keys, err := parseSortKeys("-status, expectedDate,id")
+	assert.NoError(t, err)
+	assert.Equal(t, []sortKey{{Field: "status", Desc: true}, {Field: "expectedDate"}, {Field: "id"}}, keys)
+
+	for _, spec := range []string{"", "status,", "-rank", "owner_id", "--status", "status,-status"} {
+		_, err := parseSortKeys(spec)
+		assert.ErrorIs(t, err, ErrInvalidQuery, spec)
+	}
+}
+
+// Тест для ключей сортировки выборки: к ключам добавляется id, а ключи после id отбрасываются.
+func TestOrderKeys(t *testing.T) {
+	assert.Equal(t, "-status,expectedDate,id", sortSpec(TaskQuery{Sort: "-status,expectedDate"}.orderKeys()))
+	assert.Equal(t, "expectedDate,-status,-id", sortSpec(TaskQuery{Sort: "expectedDate,-status"}.orderKeys()))
+	assert.Equal(t, "status,id", sortSpec(TaskQuery{Sort: "status,id,-priority"}.orderKeys()))
+	assert.Equal(t, "-priority,expectedDate,id", sortSpec(TaskQuery{}.orderKeys()))
+	assert.Equal(t, "-rank,-id", sortSpec(TaskQuery{Search: "молоко"}.orderKeys()))
+	assert.Error(t, TaskQuery{Sort: "status", SortOrder: "desc"}.validate())
+}
+
+// Тест для проверки размера страницы.
+func TestTaskQueryValidateLimit(t *testing.T) {
+	assert.NoError(t, TaskQuery{Limit: MaxPageSize}.validate())
+	assert.Error(t, TaskQuery{Limit: MaxPageSize + 1}.validate())
+	assert.Error(t, TaskQuery{Limit: -1}.validate())
+}
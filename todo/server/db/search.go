@@ -0,0 +1,157 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Структура searchClause - одно условие поискового запроса: слово или фраза из нескольких слов.
+// Если prefix установлен, последнее слово сопоставляется как префикс.
+type searchClause struct {
+	words  []string
+	prefix bool
+}
+
+// Тип searchQuery - разобранный поисковый запрос; задача подходит, если выполнены все условия.
+type searchQuery []searchClause
+
+// Функция parseSearch разбирает поисковую строку пользователя:
+// слова через пробел объединяются по И, текст в двойных кавычках ищется как фраза,
+// а звездочка в конце слова включает поиск по префиксу (напр. "купить молоко" срочн*).
+// В условия попадают только буквы и цифры, поэтому результат безопасно подставлять в запросы СУБД.
+func parseSearch(input string) searchQuery {
+	var query searchQuery
+	for i, part := range strings.Split(input, `"`) {
+		// Нечетные части строки находятся внутри кавычек.
+		if i%2 == 1 {
+			query = query.add(part)
+			continue
+		}
+		for _, field := range strings.Fields(part) {
+			query = query.add(field)
+		}
+	}
+	return query
+}
+
+// Метод add добавляет условие из фрагмента текста, если в нем есть слова.
+func (q searchQuery) add(fragment string) searchQuery {
+	words := searchWords(fragment)
+	if len(words) == 0 {
+		return q
+	}
+	prefix := strings.HasSuffix(strings.TrimSpace(fragment), "*")
+	return append(q, searchClause{words: words, prefix: prefix})
+}
+
+// Функция searchWords разбивает текст на слова в нижнем регистре, отбрасывая знаки препинания.
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Метод rank оценивает релевантность текста: 0 - текст не подходит,
+// иначе количество вхождений условий запроса в текст.
+func (q searchQuery) rank(text string) float64 {
+	words := searchWords(text)
+	var total float64
+	for _, clause := range q {
+		n := clause.count(words)
+		if n == 0 {
+			return 0
+		}
+		total += float64(n)
+	}
+	return total
+}
+
+// Метод count возвращает количество вхождений условия в последовательность слов.
+func (c searchClause) count(words []string) int {
+	n := 0
+	for start := 0; start+len(c.words) <= len(words); start++ {
+		if c.matchAt(words[start:]) {
+			n++
+		}
+	}
+	return n
+}
+
+// Метод matchAt проверяет, что слова условия идут подряд с начала последовательности.
+func (c searchClause) matchAt(words []string) bool {
+	last := len(c.words) - 1
+	for i, word := range c.words {
+		if i == last && c.prefix {
+			return strings.HasPrefix(words[i], word)
+		}
+		if words[i] != word {
+			return false
+		}
+	}
+	return true
+}
+
+// Интерфейс searchDialect описывает синтаксис полнотекстового поиска конкретной СУБД.
+type searchDialect interface {
+	// query преобразует разобранный запрос в строку запроса СУБД.
+	query(q searchQuery) string
+	// match возвращает SQL-условие отбора задач для запроса в параметре $n.
+	match(n int) string
+	// rank возвращает SQL-выражение релевантности задачи для запроса в параметре $n.
+	rank(n int) string
+}
+
+// Структура postgresSearch - полнотекстовый поиск PostgreSQL по колонке search_vector (tsvector с GIN-индексом).
+type postgresSearch struct{}
+
+// Метод query строит tsquery: фразы через <->, префиксы через :*, условия через &.
+func (postgresSearch) query(q searchQuery) string {
+	clauses := make([]string, 0, len(q))
+	for _, clause := range q {
+		phrase := strings.Join(clause.words, " <-> ")
+		if clause.prefix {
+			phrase += ":*"
+		}
+		clauses = append(clauses, "("+phrase+")")
+	}
+	return strings.Join(clauses, " & ")
+}
+
+func (postgresSearch) match(n int) string {
+	return fmt.Sprintf("search_vector @@ to_tsquery('simple', $%d)", n)
+}
+
+func (postgresSearch) rank(n int) string {
+	return fmt.Sprintf("ts_rank(search_vector, to_tsquery('simple', $%d))", n)
+}
+
+// Структура sqliteSearch - полнотекстовый поиск SQLite по виртуальной таблице FTS5 tasks_fts.
+type sqliteSearch struct{}
+
+// Метод query строит запрос FTS5: слова фразы через +, префикс через *, условия через AND.
+func (sqliteSearch) query(q searchQuery) string {
+	clauses := make([]string, 0, len(q))
+	for _, clause := range q {
+		words := make([]string, 0, len(clause.words))
+		for _, word := range clause.words {
+			words = append(words, `"`+word+`"`)
+		}
+		phrase := strings.Join(words, " + ")
+		if clause.prefix {
+			phrase += "*"
+		}
+		clauses = append(clauses, phrase)
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+func (sqliteSearch) match(n int) string {
+	return fmt.Sprintf("id IN (SELECT rowid FROM tasks_fts WHERE tasks_fts MATCH $%d)", n)
+}
+
+// Метод rank использует bm25 со знаком минус, чтобы более релевантные задачи имели больший ранг.
+func (sqliteSearch) rank(n int) string {
+	return fmt.Sprintf(
+		"COALESCE((SELECT -bm25(tasks_fts) FROM tasks_fts WHERE tasks_fts MATCH $%d AND rowid = tasks.id), 0)", n)
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для разбора поисковой строки.
+func TestParseSearch(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected searchQuery
+	}{
+		{name: "Слова", input: "Купить  молоко", expected: searchQuery{
+			{words: []string{"купить"}}, {words: []string{"молоко"}},
+		}},
+		{name: "Фраза", input: `"купить молоко" срочно`, expected: searchQuery{
+			{words: []string{"купить", "молоко"}}, {words: []string{"срочно"}},
+		}},
+		{name: "Префикс", input: "мол*", expected: searchQuery{
+			{words: []string{"мол"}, prefix: true},
+		}},
+		{name: "Префикс во фразе", input: `"купить мол*"`, expected: searchQuery{
+			{words: []string{"купить", "мол"}, prefix: true},
+		}},
+		{name: "Спецсимволы отбрасываются", input: `a:* & !b | 'c'`, expected: searchQuery{
+			{words: []string{"a"}, prefix: true}, {words: []string{"b"}}, {words: []string{"c"}},
+		}},
+		{name: "Пустой запрос", input: ` " " * `, expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseSearch(tc.input))
+		})
+	}
+}
+
+// Тест для построения запросов к СУБД.
+func TestSearchDialectQuery(t *testing.T) {
+	q := parseSearch(`"купить молоко" срочн*`)
+
+	assert.Equal(t, "(купить <-> молоко) & (срочн:*)", postgresSearch{}.query(q))
+	assert.Equal(t, `"купить" + "молоко" AND "срочн"*`, sqliteSearch{}.query(q))
+}
+
+// Тест для оценки релевантности в памяти.
+func TestSearchRank(t *testing.T) {
+	q := parseSearch(`"купить молоко" мол*`)
+
+	assert.Equal(t, float64(0), q.rank("Молоко купить"))
+	assert.Equal(t, float64(2), q.rank("Купить молоко!"))
+	assert.Equal(t, float64(3), q.rank("Купить молоко, молочные продукты"))
+}
+
+// Функция testSearch проверяет полнотекстовый поиск хранилища: слова, фразы, префиксы,
+// сочетание с фильтром по статусу, ранжирование и пагинацию.
+func testSearch(t *testing.T, store TaskStore) {
+	t.Helper()
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	texts := []struct {
+		text   string
+		status Status
+	}{
+		{"Купить молоко", StatusInProgress},
+		{"Молоко купить срочно, молоко закончилось", StatusInProgress},
+		{"Позвонить в банк", StatusCompleted},
+		{"Купить хлеб и молоко", StatusCompleted},
+		{"Молочная продукция: отчет", StatusInProgress},
+	}
+	ids := make([]int64, 0, len(texts))
+	for _, tt := range texts {
+		id, err := store.CreateTask(Task{OwnerID: testOwner, Text: tt.text, CreatedDate: day, ExpectedDate: day,
+			Status: tt.status})
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	search := func(q TaskQuery) []int64 {
+		page, err := store.GetAllTasks(q)
+		assert.NoError(t, err)
+		return taskIDs(page.Tasks)
+	}
+
+	assert.ElementsMatch(t, []int64{ids[0], ids[1], ids[3]},
+		search(TaskQuery{Owner: testOwner, Search: "молоко купить", SortField: "id"}))
+	assert.Equal(t, []int64{ids[0]}, search(TaskQuery{Owner: testOwner, Search: `"купить молоко"`}))
+	assert.ElementsMatch(t, []int64{ids[0], ids[1], ids[3], ids[4]},
+		search(TaskQuery{Owner: testOwner, Search: "мол*", SortField: "id"}))
+	assert.Equal(t, []int64{ids[3]}, search(TaskQuery{Owner: testOwner, Search: "молоко", Status: "1"}))
+	assert.Empty(t, search(TaskQuery{Owner: testOwner, Search: "самолет"}))
+	assert.Empty(t, search(TaskQuery{Owner: testOwner, Search: "!!!"}))
+
+	// Задача, где слово встречается дважды, релевантнее остальных.
+	assert.Equal(t, ids[1], search(TaskQuery{Owner: testOwner, Search: "молоко"})[0])
+
+	// Явное поле сортировки имеет приоритет над релевантностью.
+	assert.Equal(t, []int64{ids[0], ids[1], ids[3]},
+		search(TaskQuery{Owner: testOwner, Search: "молоко", SortField: "id"}))
+
+	full := search(TaskQuery{Owner: testOwner, Search: "мол*"})
+	assert.Equal(t, full, collectPages(t, store, TaskQuery{Owner: testOwner, Search: "мол*", Limit: 1}))
+
+	// Индекс поиска обновляется при изменении и удалении задач.
+	_, err := store.UpdateTask(Task{OwnerID: testOwner, ID: ids[2], Text: "Позвонить насчет молока", CreatedDate: day,
+		ExpectedDate: day})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{ids[2]}, search(TaskQuery{Owner: testOwner, Search: "молока"}))
+	assert.NoError(t, store.DeleteTask(testOwner, testOwner, int(ids[2]), 0))
+	assert.Empty(t, search(TaskQuery{Owner: testOwner, Search: "молока"}))
+}
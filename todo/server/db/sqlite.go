@@ -0,0 +1,40 @@
+package db
+
+import (
+	"database/sql"
+
+	_ "modernc.org/sqlite" // Импорт драйвера SQLite (без CGO) для использования с database/sql.
+)
+
+// Структура SQLiteStore реализует TaskStore поверх встроенной базы данных SQLite.
+// SQLite понимает те же запросы, что и PostgreSQL ($1-плейсхолдеры, RETURNING),
+// поэтому хранилище переиспользует запросы PostgresStore и отличается только драйвером, схемой
+// и диалектом полнотекстового поиска (FTS5 вместо tsvector).
+type SQLiteStore struct {
+	*PostgresStore
+}
+
+// Функция OpenSQLite открывает (или создает) файл базы данных SQLite и применяет к нему миграции.
+func OpenSQLite(path string) (*SQLiteStore, *sql.DB, error) {
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// SQLite допускает только одного писателя, а база ":memory:" существует в рамках одного соединения.
+	conn.SetMaxOpenConns(1)
+
+	// Блокировка строк не нужна: единственное соединение и так выполняет транзакции по очереди.
+	store := &SQLiteStore{PostgresStore: &PostgresStore{db: conn, search: sqliteSearch{}}}
+	if _, err := store.Migrator().Up(); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	return store, conn, nil
+}
+
+// Метод Migrator возвращает мигратор схемы SQLite.
+func (s *SQLiteStore) Migrator() *Migrator {
+	return NewMigrator(s.db, "sqlite")
+}
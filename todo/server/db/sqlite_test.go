@@ -0,0 +1,215 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Функция newTestSQLiteStore открывает базу SQLite в памяти.
+func newTestSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, conn, err := OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("could not open sqlite: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return store
+}
+
+// Тест для операций CRUD хранилища SQLite.
+func TestSQLiteStoreCRUD(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	id1, err := store.CreateTask(Task{OwnerID: testOwner, Text: "B", CreatedDate: day, ExpectedDate: day.AddDate(0, 0, 2),
+		Status: StatusInProgress})
+	assert.NoError(t, err)
+	id2, err := store.CreateTask(Task{OwnerID: testOwner, Text: "A", CreatedDate: day, ExpectedDate: day.AddDate(0, 0, 1),
+		Status: StatusCompleted})
+	assert.NoError(t, err)
+
+	page, err := store.GetAllTasks(TaskQuery{Owner: testOwner, SortField: "expectedDate"})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{id2, id1}, taskIDs(page.Tasks))
+	assert.True(t, page.Tasks[1].ExpectedDate.Equal(day.AddDate(0, 0, 2)))
+
+	page, err = store.GetAllTasks(TaskQuery{Owner: testOwner, Status: "1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{id2}, taskIDs(page.Tasks))
+
+	updated := page.Tasks[0]
+	updated.Text = "A2"
+	_, err = store.UpdateTask(updated)
+	assert.NoError(t, err)
+
+	found, err := store.GetTaskByID(testOwner, int(id2))
+	assert.NoError(t, err)
+	assert.Equal(t, "A2", found.Text)
+	assert.True(t, found.ExpectedDate.Equal(day.AddDate(0, 0, 1)))
+	_, err = store.GetTaskByID(testOwner, int(id2)+100)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, store.DeleteTask(testOwner, testOwner, int(id1), 0))
+	assert.ErrorIs(t, store.DeleteTask(testOwner, testOwner, int(id1), 0), ErrNotFound)
+	_, err = store.UpdateTask(Task{OwnerID: testOwner, ID: id1})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	page, err = store.GetAllTasks(TaskQuery{Owner: testOwner})
+	assert.NoError(t, err)
+	assert.Len(t, page.Tasks, 1)
+	assert.Equal(t, "A2", page.Tasks[0].Text)
+
+	_, err = store.GetAllTasks(TaskQuery{Owner: testOwner, SortField: "DROP TABLE tasks"})
+	assert.Error(t, err)
+}
+
+// Тест для открытия SQLite по DSN: схема создается один раз, данные сохраняются в файле.
+func TestOpenStoreSQLite(t *testing.T) {
+	dsn := "sqlite://" + filepath.Join(t.TempDir(), "todo.db")
+
+	store, closeStore, err := OpenStore(dsn)
+	assert.NoError(t, err)
+	_, err = store.CreateTask(Task{OwnerID: testOwner, Text: "Persisted", CreatedDate: time.Now(),
+		ExpectedDate: time.Now()})
+	assert.NoError(t, err)
+	assert.NoError(t, closeStore())
+
+	store, closeStore, err = OpenStore(dsn)
+	assert.NoError(t, err)
+	defer closeStore()
+
+	page, err := store.GetAllTasks(TaskQuery{Owner: testOwner})
+	assert.NoError(t, err)
+	assert.Len(t, page.Tasks, 1)
+	assert.Equal(t, "Persisted", page.Tasks[0].Text)
+}
+
+// Тест для выбора хранилища по схеме DSN.
+func TestOpenStoreSchemes(t *testing.T) {
+	store, closeStore, err := OpenStore("memory://")
+	assert.NoError(t, err)
+	assert.IsType(t, &MemoryStore{}, store)
+	assert.NoError(t, closeStore())
+
+	_, _, err = OpenStore("mysql://localhost/todo")
+	assert.Error(t, err)
+
+	_, _, err = OpenStore("todo.db")
+	assert.Error(t, err)
+}
+
+// Тест для пагинации хранилища SQLite.
+func TestSQLiteStorePagination(t *testing.T) {
+	testPagination(t, newTestSQLiteStore(t))
+}
+
+// Тест для полнотекстового поиска хранилища SQLite.
+func TestSQLiteStoreSearch(t *testing.T) {
+	testSearch(t, newTestSQLiteStore(t))
+}
+
+// Тест для оптимистичной блокировки хранилища SQLite.
+func TestSQLiteStoreVersioning(t *testing.T) {
+	testVersioning(t, newTestSQLiteStore(t))
+}
+
+// Тест для статусов рабочего процесса в хранилище SQLite. Миграция заполняет таблицы статусов
+// так же, как DefaultWorkflow.
+func TestSQLiteStoreWorkflow(t *testing.T) {
+	testWorkflow(t, newTestSQLiteStore(t))
+}
+
+// Тест для журнала изменений задач в хранилище SQLite.
+func TestSQLiteStoreHistory(t *testing.T) {
+	testHistory(t, newTestSQLiteStore(t))
+}
+
+// Тест для корзины хранилища SQLite.
+func TestSQLiteStoreTrash(t *testing.T) {
+	testTrash(t, newTestSQLiteStore(t))
+}
+
+// Тест для пользователей и сессий в хранилище SQLite.
+func TestSQLiteStoreUsers(t *testing.T) {
+	testUsers(t, newTestSQLiteStore(t))
+}
+
+// Тест для изоляции задач пользователей в хранилище SQLite.
+func TestSQLiteStoreIsolation(t *testing.T) {
+	testIsolation(t, newTestSQLiteStore(t))
+}
+
+// Тест для персональных токенов доступа в хранилище SQLite.
+func TestSQLiteStoreTokens(t *testing.T) {
+	testTokens(t, newTestSQLiteStore(t))
+}
+
+// Тест для участников общих списков задач в хранилище SQLite.
+func TestSQLiteStoreLists(t *testing.T) {
+	testLists(t, newTestSQLiteStore(t))
+}
+
+// Тест для проектов в хранилище SQLite.
+func TestSQLiteStoreProjects(t *testing.T) {
+	testProjects(t, newTestSQLiteStore(t))
+}
+
+// Тест для меток задач в хранилище SQLite.
+func TestSQLiteStoreTags(t *testing.T) {
+	testTags(t, newTestSQLiteStore(t))
+}
+
+// Тест для приоритета задач в хранилище SQLite.
+func TestSQLiteStorePriority(t *testing.T) {
+	testPriority(t, newTestSQLiteStore(t))
+}
+
+// Тест для передачи задач без автора пользователю в хранилище SQLite.
+func TestSQLiteStoreOwnerlessTasks(t *testing.T) {
+	testOwnerlessTasks(t, newTestSQLiteStore(t))
+}
+
+// Тест для обновления базы, созданной до появления пользователей: после миграций её задачи
+// никому не видны, пока их не передадут пользователю, и передаются вместе с журналом изменений.
+func TestSQLiteStoreOwnerlessUpgrade(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	migrator := store.Migrator()
+	statuses, err := migrator.Status()
+	assert.NoError(t, err)
+
+	// Схема откатывается к версии 0007, последней до появления пользователей.
+	_, err = migrator.Down(len(statuses) - 7)
+	assert.NoError(t, err)
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	_, err = store.db.Exec("INSERT INTO tasks (task_text, createdDate, expectedDate, status) VALUES ($1, $2, $3, $4)",
+		"Старая задача", day, day, int(StatusInProgress))
+	assert.NoError(t, err)
+	_, err = migrator.Up()
+	assert.NoError(t, err)
+
+	alice, err := store.CreateUser(User{Username: "alice", PasswordHash: "hash"})
+	assert.NoError(t, err)
+	page, err := store.GetAllTasks(TaskQuery{Owner: alice})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Tasks)
+
+	claimed, err := store.ClaimOwnerlessTasks(alice)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), claimed)
+	task, err := store.GetTaskByID(alice, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Старая задача", task.Text)
+	assert.Equal(t, alice, task.OwnerID)
+	events, err := store.GetTaskHistory(alice, 1)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, EventCreated, events[0].Type)
+}
+
+// Тест для собственных рабочих процессов проектов в хранилище SQLite.
+func TestSQLiteStoreProjectWorkflows(t *testing.T) {
+	testProjectWorkflows(t, newTestSQLiteStore(t))
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Тип Status - статус задачи. В базе данных хранится числом - идентификатором статуса
+// в таблице statuses. Имена, порядок и допустимые переходы статусов описывает Workflow.
+type Status int
+
+// Константы для встроенных статусов задач (см. DefaultWorkflow).
+const (
+	StatusInProgress Status = iota
+	StatusCompleted
+	StatusTesting
+	StatusReturned
+)
+
+// Тип StatusRef - статус задачи в JSON. Клиенты получают имя статуса (напр. "in_progress"),
+// а передавать могут как имя, так и, для совместимости со старыми клиентами, номер статуса.
+// Ссылка разрешается в Status методом Workflow.Lookup.
+type StatusRef string
+
+// Функция RefOf возвращает ссылку на статус по его номеру.
+func RefOf(status Status) StatusRef {
+	return StatusRef(strconv.Itoa(int(status)))
+}
+
+// Метод UnmarshalJSON принимает статус в виде строки или целого числа.
+func (r *StatusRef) UnmarshalJSON(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(`"`)) {
+		var n int
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("task status must be a name or a number: %s", data)
+		}
+		*r = RefOf(Status(n))
+		return nil
+	}
+
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	*r = StatusRef(name)
+	return nil
+}
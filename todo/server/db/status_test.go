@@ -0,0 +1,95 @@
+package db
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для разбора статуса задачи из JSON.
+func TestStatusRefJSON(t *testing.T) {
+	var dto TaskDTO
+	assert.NoError(t, json.Unmarshal([]byte(`{"status":"returned"}`), &dto))
+	assert.Equal(t, StatusRef("returned"), dto.Status)
+
+	// Числовая форма принимается для совместимости со старыми клиентами.
+	assert.NoError(t, json.Unmarshal([]byte(`{"status":1}`), &dto))
+	assert.Equal(t, StatusRef("1"), dto.Status)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"status":true}`), &dto))
+	assert.Error(t, json.Unmarshal([]byte(`{"status":1.5}`), &dto))
+
+	data, err := json.Marshal(DefaultWorkflow().ToDTO(Task{Status: StatusTesting}))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"status":"testing"`)
+}
+
+// Тест для поиска статуса в рабочем процессе по имени и номеру.
+func TestWorkflowLookup(t *testing.T) {
+	workflow := DefaultWorkflow()
+
+	testCases := []struct {
+		input    string
+		expected Status
+		isError  bool
+	}{
+		{input: "in_progress", expected: StatusInProgress},
+		{input: "completed", expected: StatusCompleted},
+		{input: "testing", expected: StatusTesting},
+		{input: "returned", expected: StatusReturned},
+		{input: "2", expected: StatusTesting},
+		{input: "4", isError: true},
+		{input: "-1", isError: true},
+		{input: "в процессе", isError: true},
+		{input: "0 OR 1=1", isError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			def, ok := workflow.Lookup(tc.input)
+			assert.Equal(t, !tc.isError, ok)
+			if ok {
+				assert.Equal(t, tc.expected, def.ID)
+			}
+		})
+	}
+
+	initial, ok := workflow.Initial()
+	assert.True(t, ok)
+	assert.Equal(t, StatusInProgress, initial.ID)
+	assert.Equal(t, "returned", workflow.Name(StatusReturned))
+	assert.Equal(t, "7", workflow.Name(Status(7)))
+
+	_, ok = Workflow{}.Initial()
+	assert.False(t, ok)
+}
+
+// Тест для переходов между статусами встроенного рабочего процесса.
+func TestWorkflowTransitions(t *testing.T) {
+	workflow := DefaultWorkflow()
+
+	testCases := []struct {
+		from, to Status
+		allowed  bool
+	}{
+		{from: StatusInProgress, to: StatusInProgress, allowed: true},
+		{from: StatusInProgress, to: StatusTesting, allowed: true},
+		{from: StatusInProgress, to: StatusCompleted, allowed: false},
+		{from: StatusInProgress, to: StatusReturned, allowed: false},
+		{from: StatusTesting, to: StatusCompleted, allowed: true},
+		{from: StatusTesting, to: StatusReturned, allowed: true},
+		{from: StatusTesting, to: StatusInProgress, allowed: false},
+		{from: StatusReturned, to: StatusInProgress, allowed: true},
+		{from: StatusReturned, to: StatusCompleted, allowed: false},
+		{from: StatusCompleted, to: StatusReturned, allowed: true},
+		{from: StatusCompleted, to: StatusTesting, allowed: false},
+		{from: StatusInProgress, to: Status(9), allowed: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(workflow.Name(tc.from)+"->"+workflow.Name(tc.to), func(t *testing.T) {
+			assert.Equal(t, tc.allowed, workflow.CanTransition(tc.from, tc.to))
+		})
+	}
+}
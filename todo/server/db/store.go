@@ -0,0 +1,88 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotFound - признак отсутствия задачи. Хранилища возвращают *NotFoundError,
+// которая распознается через errors.Is(err, ErrNotFound).
+var ErrNotFound = errors.New("task not found")
+
+// Структура NotFoundError - ошибка отсутствия задачи с указанным идентификатором.
+type NotFoundError struct {
+	ID int64
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("task %d not found", e.ID)
+}
+
+// Метод Is позволяет проверять ошибку через errors.Is(err, ErrNotFound).
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ErrVersionConflict возвращается хранилищем, если версия задачи не совпала с ожидаемой:
+// задачу успели изменить или удалить с момента ее чтения клиентом.
+var ErrVersionConflict = errors.New("task version conflict")
+
+// Белый список допустимых значений для sortField.
+var validSortFields = map[string]bool{
+	"id":           true,
+	"task_text":    true,
+	"createdDate":  true,
+	"expectedDate": true,
+	"status":       true,
+	"priority":     true,
+}
+
+// Интерфейс TaskStore описывает хранилище задач, с которым работают обработчики.
+// Хранилище задач отвечает и за статусы рабочего процесса, в которых находятся задачи,
+// и за пользователей, которые их создают, и за проекты и метки, по которым задачи сгруппированы.
+// Задачи принадлежат своим авторам: методы получения, изменения и удаления задач работают
+// только с задачами указанного пользователя, а чужие задачи для них не существуют (*NotFoundError).
+// Задачи без автора, созданные до появления пользователей, недоступны никому,
+// пока их не передаст пользователю ClaimOwnerlessTasks.
+// Доступ участников общих списков к чужим задачам проверяют обработчики (ListStore):
+// хранилищу передается ID автора списка.
+type TaskStore interface {
+	StatusStore
+	UserStore
+	ListStore
+	ProjectStore
+	TagStore
+
+	// GetAllTasks возвращает страницу задач пользователя query.Owner с учетом фильтрации,
+	// сортировки и пагинации. Задачи из корзины возвращаются только при query.Deleted, и тогда - только они.
+	GetAllTasks(query TaskQuery) (TaskPage, error)
+	// GetTaskByID возвращает задачу пользователя owner по идентификатору или *NotFoundError.
+	// Задачи из корзины считаются отсутствующими.
+	GetTaskByID(owner int64, id int) (Task, error)
+	// CreateTask сохраняет новую задачу вместе с её автором (task.OwnerID) и возвращает её ID.
+	CreateTask(task Task) (int64, error)
+	// UpdateTask обновляет существующую задачу пользователя task.OwnerID и возвращает её новую версию.
+	// Если task.Version не равен нулю, задача обновляется, только если её текущая версия
+	// совпадает с ним, иначе возвращается ErrVersionConflict. Событие журнала получает пользователя
+	// task.EditorID и, если статус изменился, причину task.Reason.
+	UpdateTask(task Task) (int64, error)
+	// DeleteTask перемещает задачу пользователя owner в корзину от имени пользователя actor
+	// и увеличивает её версию. Если version не равен нулю, задача удаляется, только если её
+	// текущая версия совпадает с ним.
+	DeleteTask(owner, actor int64, id int, version int64) error
+	// RestoreTask возвращает задачу пользователя owner из корзины от имени пользователя actor
+	// и возвращает её новую версию. Если задачи нет в корзине, возвращается *NotFoundError.
+	RestoreTask(owner, actor int64, id int) (int64, error)
+	// PurgeDeletedTasks окончательно удаляет задачи всех пользователей, перемещенные в корзину
+	// раньше before, и возвращает количество удаленных задач.
+	PurgeDeletedTasks(before time.Time) (int64, error)
+	// ClaimOwnerlessTasks передает пользователю owner задачи без автора, созданные до появления
+	// пользователей, вместе с их журналом изменений и возвращает количество переданных задач.
+	// Если пользователя нет, возвращается ErrUserNotFound.
+	ClaimOwnerlessTasks(owner int64) (int64, error)
+	// GetTaskHistory возвращает журнал изменений задачи пользователя owner от старых событий к новым.
+	// История удаленной задачи сохраняется; если задача не существовала или принадлежит
+	// другому пользователю, возвращается *NotFoundError.
+	GetTaskHistory(owner int64, id int) ([]TaskEvent, error)
+}
@@ -0,0 +1,786 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Пользователь, от имени которого тесты работают с задачами.
+const testOwner int64 = 1
+
+// Функция testVersioning проверяет оптимистичную блокировку хранилища: версия растет при каждом
+// изменении, а изменение или удаление с устаревшей версией отклоняется с ErrVersionConflict.
+func testVersioning(t *testing.T, store TaskStore) {
+	t.Helper()
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	id, err := store.CreateTask(Task{OwnerID: testOwner, Text: "Версия", CreatedDate: day, ExpectedDate: day,
+		Status: StatusInProgress})
+	assert.NoError(t, err)
+	task, err := store.GetTaskByID(testOwner, int(id))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), task.Version)
+
+	// Первый клиент сохраняет изменения по прочитанной версии.
+	stale := task
+	task.Text = "Версия 2"
+	version, err := store.UpdateTask(task)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+
+	// Второй клиент с устаревшей версией получает конфликт, а задача не меняется.
+	stale.Text = "Потерянное изменение"
+	_, err = store.UpdateTask(stale)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	assert.ErrorIs(t, store.DeleteTask(testOwner, testOwner, int(id), stale.Version), ErrVersionConflict)
+
+	found, err := store.GetTaskByID(testOwner, int(id))
+	assert.NoError(t, err)
+	assert.Equal(t, "Версия 2", found.Text)
+	assert.Equal(t, int64(2), found.Version)
+
+	// Без версии изменение выполняется безусловно.
+	found.Version = 0
+	version, err = store.UpdateTask(found)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), version)
+
+	assert.NoError(t, store.DeleteTask(testOwner, testOwner, int(id), 3))
+	assert.ErrorIs(t, store.DeleteTask(testOwner, testOwner, int(id), 3), ErrNotFound)
+	_, err = store.UpdateTask(Task{OwnerID: testOwner, ID: id, Version: 3})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// Тест для типизированной ошибки отсутствия задачи.
+func TestNotFoundError(t *testing.T) {
+	var err error = &NotFoundError{ID: 42}
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrVersionConflict)
+	assert.Equal(t, "task 42 not found", err.Error())
+
+	var notFound *NotFoundError
+	assert.ErrorAs(t, fmt.Errorf("update: %w", err), &notFound)
+	assert.Equal(t, int64(42), notFound.ID)
+}
+
+// Функция testWorkflow проверяет хранение статусов: встроенный рабочий процесс, создание,
+// изменение и удаление статуса, а также запрет удаления статуса, в котором есть задачи.
+func testWorkflow(t *testing.T, store TaskStore) {
+	t.Helper()
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	workflow, err := store.GetWorkflow(0)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultWorkflow(), workflow)
+
+	blocked := StatusDef{Name: "blocked", Title: "Заблокировано", Position: 15, Color: "#f44336",
+		Transitions: []string{"in_progress"}}
+	id, err := store.CreateStatus(0, blocked)
+	assert.NoError(t, err)
+	assert.Equal(t, Status(4), id)
+	_, err = store.CreateStatus(0, blocked)
+	assert.ErrorIs(t, err, ErrStatusExists)
+
+	inProgress, _ := workflow.Lookup("in_progress")
+	inProgress.Transitions = []string{"testing", "blocked"}
+	assert.NoError(t, store.UpdateStatus(0, inProgress))
+	assert.ErrorIs(t, store.UpdateStatus(0, StatusDef{Name: "missing"}), ErrStatusNotFound)
+
+	workflow, err = store.GetWorkflow(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"in_progress", "blocked", "testing", "returned", "completed"}, statusNames(workflow))
+	assert.True(t, workflow.CanTransition(StatusInProgress, id))
+	assert.True(t, workflow.CanTransition(id, StatusInProgress))
+	assert.False(t, workflow.CanTransition(id, StatusTesting))
+
+	// Статус с задачами не удаляется, пока задачи не переведены в другой статус.
+	taskID, err := store.CreateTask(Task{OwnerID: testOwner, Text: "Ждет ответа", CreatedDate: day, ExpectedDate: day,
+		Status: id})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, store.DeleteStatus(0, "blocked"), ErrStatusInUse)
+	// Задача в корзине тоже занимает статус: её можно восстановить.
+	assert.NoError(t, store.DeleteTask(testOwner, testOwner, int(taskID), 0))
+	assert.ErrorIs(t, store.DeleteStatus(0, "blocked"), ErrStatusInUse)
+	purged, err := store.PurgeDeletedTasks(time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	assert.NoError(t, store.DeleteStatus(0, "blocked"))
+	assert.ErrorIs(t, store.DeleteStatus(0, "blocked"), ErrStatusNotFound)
+
+	workflow, err = store.GetWorkflow(0)
+	assert.NoError(t, err)
+	def, _ := workflow.Lookup("in_progress")
+	assert.Equal(t, []string{"testing"}, def.Transitions)
+	assert.Len(t, workflow.Statuses, 4)
+}
+
+// Функция statusNames возвращает имена статусов рабочего процесса по порядку.
+func statusNames(workflow Workflow) []string {
+	names := make([]string, 0, len(workflow.Statuses))
+	for _, def := range workflow.Statuses {
+		names = append(names, def.Name)
+	}
+	return names
+}
+
+// Функция testProjectWorkflows проверяет собственные рабочие процессы проектов: при первом изменении
+// проект получает копию рабочего процесса по умолчанию, а его задачи - статусы-копии с теми же именами;
+// рабочий процесс по умолчанию и другие проекты не меняются, имя статуса уникально только в своем
+// рабочем процессе, а после удаления проекта его задачи возвращаются в статусы по умолчанию.
+func testProjectWorkflows(t *testing.T, store TaskStore) {
+	t.Helper()
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	alice, err := store.CreateUser(User{Username: "alice", PasswordHash: "hash"})
+	assert.NoError(t, err)
+	qa, err := store.CreateProject(Project{OwnerID: alice, Name: "QA"})
+	assert.NoError(t, err)
+	home, err := store.CreateProject(Project{OwnerID: alice, Name: "Дом"})
+	assert.NoError(t, err)
+	create := func(project int64, status Status) int64 {
+		id, err := store.CreateTask(Task{OwnerID: alice, Text: "Task", CreatedDate: day, ExpectedDate: day,
+			Status: status, ProjectID: project})
+		assert.NoError(t, err)
+		return id
+	}
+	inTesting := create(qa, StatusTesting)
+	plain := create(0, StatusTesting)
+	homeTask := create(home, StatusTesting)
+
+	// Неудачное изменение не заводит проекту собственный рабочий процесс.
+	assert.ErrorIs(t, store.UpdateStatus(home, StatusDef{Name: "missing"}), ErrStatusNotFound)
+	review := StatusDef{Name: "review", Title: "Ревью", Position: 25, Color: "#795548", Transitions: []string{"testing"}}
+	_, err = store.CreateStatus(qa, review)
+	assert.NoError(t, err)
+	_, err = store.CreateStatus(qa, review)
+	assert.ErrorIs(t, err, ErrStatusExists)
+	// То же имя свободно в рабочем процессе по умолчанию.
+	_, err = store.CreateStatus(0, StatusDef{Name: "review", Title: "Ревью", Position: 25, Color: "#795548"})
+	assert.NoError(t, err)
+	assert.NoError(t, store.DeleteStatus(0, "review"))
+
+	workflows, err := store.GetWorkflows()
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultWorkflow(), workflows.Default)
+	assert.Equal(t, qa, workflows.Scope(qa))
+	assert.Zero(t, workflows.Scope(home))
+	assert.Equal(t, []string{"in_progress", "testing", "review", "returned", "completed"},
+		statusNames(workflows.For(qa)))
+	workflow, err := store.GetWorkflow(qa)
+	assert.NoError(t, err)
+	assert.Equal(t, workflows.For(qa), workflow)
+
+	// Задачи проекта перешли в статусы-копии, остальные задачи остались в статусах по умолчанию.
+	task, err := store.GetTaskByID(alice, int(inTesting))
+	assert.NoError(t, err)
+	assert.NotEqual(t, StatusTesting, task.Status)
+	assert.Equal(t, StatusRef("testing"), workflows.ToDTO(task).Status)
+	for _, id := range []int64{plain, homeTask} {
+		task, err := store.GetTaskByID(alice, int(id))
+		assert.NoError(t, err)
+		assert.Equal(t, StatusTesting, task.Status)
+	}
+	page, err := store.GetAllTasks(TaskQuery{Owner: alice,
+		Status: fmt.Sprintf("%d,%d", StatusTesting, workflows.For(qa).Statuses[1].ID)})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{inTesting, plain, homeTask}, taskIDs(page.Tasks))
+
+	// Статусы проекта изменяются независимо от рабочего процесса по умолчанию.
+	assert.ErrorIs(t, store.DeleteStatus(qa, "testing"), ErrStatusInUse)
+	assert.NoError(t, store.DeleteStatus(qa, "returned"))
+	workflow, err = store.GetWorkflow(qa)
+	assert.NoError(t, err)
+	def, _ := workflow.Lookup("testing")
+	assert.Equal(t, []string{"completed"}, def.Transitions)
+	workflow, err = store.GetWorkflow(0)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultWorkflow(), workflow)
+
+	// После удаления проекта задачи возвращаются в статусы по умолчанию с теми же именами,
+	// а задачи из статусов, которых там нет, - в начальный статус.
+	task.Status = workflows.For(qa).Statuses[2].ID
+	task.Version = 0
+	_, err = store.UpdateTask(task)
+	assert.NoError(t, err)
+	other := create(qa, workflows.For(qa).Statuses[1].ID)
+	assert.NoError(t, store.DeleteProject(alice, alice, qa))
+	task, err = store.GetTaskByID(alice, int(inTesting))
+	assert.NoError(t, err)
+	assert.Equal(t, StatusInProgress, task.Status)
+	task, err = store.GetTaskByID(alice, int(other))
+	assert.NoError(t, err)
+	assert.Equal(t, StatusTesting, task.Status)
+	workflows, err = store.GetWorkflows()
+	assert.NoError(t, err)
+	assert.Empty(t, workflows.Projects)
+}
+
+// Функция testHistory проверяет журнал изменений задачи: создание, изменение полей,
+// смену статуса и удаление, после которого история остается доступной.
+func testHistory(t *testing.T, store TaskStore) {
+	t.Helper()
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := store.GetTaskHistory(testOwner, 42)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	id, err := store.CreateTask(Task{OwnerID: testOwner, Text: "История", CreatedDate: day, ExpectedDate: day,
+		Status: StatusInProgress, EditorID: testOwner})
+	assert.NoError(t, err)
+	task, err := store.GetTaskByID(testOwner, int(id))
+	assert.NoError(t, err)
+
+	task.Text = "История задачи"
+	task.EditorID = testOwner
+	task.Version, err = store.UpdateTask(task)
+	assert.NoError(t, err)
+
+	// Сохранение без изменений полей в журнал не попадает.
+	task.Version, err = store.UpdateTask(task)
+	assert.NoError(t, err)
+
+	// Смену статуса выполняет другой пользователь списка и указывает причину.
+	task.Status, task.EditorID, task.Reason = StatusTesting, 2, "Готово к проверке"
+	task.Version, err = store.UpdateTask(task)
+	assert.NoError(t, err)
+
+	// Причина относится только к своей смене статуса и не переходит на следующие.
+	task.Status, task.Reason = StatusCompleted, ""
+	task.Version, err = store.UpdateTask(task)
+	assert.NoError(t, err)
+	assert.NoError(t, store.DeleteTask(testOwner, 2, int(id), task.Version))
+
+	events, err := store.GetTaskHistory(testOwner, int(id))
+	assert.NoError(t, err)
+	assert.Len(t, events, 5)
+
+	types := make([]string, 0, len(events))
+	for _, event := range events {
+		assert.Equal(t, id, event.TaskID)
+		assert.False(t, event.CreatedAt.IsZero())
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []string{EventCreated, EventUpdated, EventStatusChanged, EventStatusChanged, EventDeleted}, types)
+
+	assert.Nil(t, events[0].Old)
+	assert.Equal(t, &TaskSnapshot{Text: "История", CreatedDate: "2023-10-01", ExpectedDate: "2023-10-01",
+		Status: StatusInProgress, Version: 1}, events[0].New)
+	assert.Equal(t, "История", events[1].Old.Text)
+	assert.Equal(t, "История задачи", events[1].New.Text)
+	assert.Equal(t, StatusInProgress, events[2].Old.Status)
+	assert.Equal(t, &TaskSnapshot{Text: "История задачи", CreatedDate: "2023-10-01", ExpectedDate: "2023-10-01",
+		Status: StatusTesting, Version: 4}, events[2].New)
+	assert.Equal(t, events[2].New, events[3].Old)
+	assert.Equal(t, events[3].New, events[4].Old)
+	assert.Nil(t, events[4].New)
+
+	// Автор задачи остается прежним, а в событиях записан пользователь, выполнивший действие.
+	reasons := make([]string, 0, len(events))
+	for i, actor := range []int64{testOwner, testOwner, 2, 2, 2} {
+		assert.Equal(t, testOwner, events[i].OwnerID)
+		assert.Equal(t, actor, events[i].ActorID)
+		reasons = append(reasons, events[i].Reason)
+	}
+	assert.Equal(t, []string{"", "", "Готово к проверке", "", ""}, reasons)
+}
+
+// Функция testTrash проверяет корзину: удаленная задача пропадает из списка и становится недоступной,
+// но попадает в корзину, откуда её можно восстановить или окончательно удалить по истечении срока хранения.
+func testTrash(t *testing.T, store TaskStore) {
+	t.Helper()
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	kept, err := store.CreateTask(Task{OwnerID: testOwner, Text: "Остается", CreatedDate: day, ExpectedDate: day,
+		Status: StatusInProgress})
+	assert.NoError(t, err)
+	id, err := store.CreateTask(Task{OwnerID: testOwner, Text: "Корзина", CreatedDate: day, ExpectedDate: day,
+		Status: StatusInProgress})
+	assert.NoError(t, err)
+
+	before := time.Now().Add(-time.Second)
+	assert.NoError(t, store.DeleteTask(testOwner, testOwner, int(id), 1))
+
+	page, err := store.GetAllTasks(TaskQuery{Owner: testOwner})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{kept}, taskIDs(page.Tasks))
+	assert.Nil(t, page.Tasks[0].DeletedAt)
+
+	trash, err := store.GetAllTasks(TaskQuery{Owner: testOwner, Deleted: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{id}, taskIDs(trash.Tasks))
+	assert.Equal(t, int64(2), trash.Tasks[0].Version)
+	if assert.NotNil(t, trash.Tasks[0].DeletedAt) {
+		assert.True(t, trash.Tasks[0].DeletedAt.After(before))
+	}
+
+	// Задача в корзине недоступна для чтения, изменения и повторного удаления.
+	_, err = store.GetTaskByID(testOwner, int(id))
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.UpdateTask(Task{OwnerID: testOwner, ID: id, Text: "Изменение", CreatedDate: day, ExpectedDate: day})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.DeleteTask(testOwner, testOwner, int(id), 0), ErrNotFound)
+	_, err = store.RestoreTask(testOwner, testOwner, int(kept))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	version, err := store.RestoreTask(testOwner, testOwner, int(id))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), version)
+	task, err := store.GetTaskByID(testOwner, int(id))
+	assert.NoError(t, err)
+	assert.Equal(t, "Корзина", task.Text)
+
+	// Очистка удаляет только задачи, пролежавшие в корзине дольше срока хранения.
+	assert.NoError(t, store.DeleteTask(testOwner, testOwner, int(id), 0))
+	purged, err := store.PurgeDeletedTasks(before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+	purged, err = store.PurgeDeletedTasks(time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	trash, err = store.GetAllTasks(TaskQuery{Owner: testOwner, Deleted: true})
+	assert.NoError(t, err)
+	assert.Empty(t, trash.Tasks)
+	_, err = store.RestoreTask(testOwner, testOwner, int(id))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	events, err := store.GetTaskHistory(testOwner, int(id))
+	assert.NoError(t, err)
+	types := make([]string, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []string{EventCreated, EventDeleted, EventRestored, EventDeleted, EventPurged}, types)
+	assert.Equal(t, int64(3), events[2].New.Version)
+	assert.Nil(t, events[4].New)
+}
+
+// Функция testIsolation проверяет, что задачи пользователей изолированы: по чужому ID задачу нельзя
+// прочитать, изменить, удалить, восстановить или получить её историю, а задачи без автора не видны никому.
+func testIsolation(t *testing.T, store TaskStore) {
+	t.Helper()
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	const alice, bob int64 = 1, 2
+
+	aliceTask, err := store.CreateTask(Task{OwnerID: alice, Text: "Задача Алисы", CreatedDate: day, ExpectedDate: day})
+	assert.NoError(t, err)
+	bobTask, err := store.CreateTask(Task{OwnerID: bob, Text: "Задача Боба", CreatedDate: day, ExpectedDate: day})
+	assert.NoError(t, err)
+	_, err = store.CreateTask(Task{Text: "Без автора", CreatedDate: day, ExpectedDate: day})
+	assert.NoError(t, err)
+
+	page, err := store.GetAllTasks(TaskQuery{Owner: bob})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{bobTask}, taskIDs(page.Tasks))
+	page, err = store.GetAllTasks(TaskQuery{})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Tasks)
+
+	// Чужая задача для Боба не существует, даже если он угадал её ID и версию.
+	_, err = store.GetTaskByID(bob, int(aliceTask))
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.UpdateTask(Task{OwnerID: bob, ID: aliceTask, Text: "Взлом", CreatedDate: day, ExpectedDate: day})
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.UpdateTask(Task{OwnerID: bob, ID: aliceTask, Text: "Взлом", CreatedDate: day, ExpectedDate: day,
+		Version: 1})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.DeleteTask(bob, bob, int(aliceTask), 0), ErrNotFound)
+	assert.ErrorIs(t, store.DeleteTask(bob, bob, int(aliceTask), 1), ErrNotFound)
+	_, err = store.GetTaskHistory(bob, int(aliceTask))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	task, err := store.GetTaskByID(alice, int(aliceTask))
+	assert.NoError(t, err)
+	assert.Equal(t, "Задача Алисы", task.Text)
+	assert.Equal(t, int64(1), task.Version)
+
+	// Корзина тоже своя у каждого пользователя.
+	assert.NoError(t, store.DeleteTask(alice, alice, int(aliceTask), 0))
+	trash, err := store.GetAllTasks(TaskQuery{Owner: bob, Deleted: true})
+	assert.NoError(t, err)
+	assert.Empty(t, trash.Tasks)
+	_, err = store.RestoreTask(bob, bob, int(aliceTask))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// История окончательно удаленной задачи доступна только её автору.
+	_, err = store.PurgeDeletedTasks(time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	_, err = store.GetTaskHistory(bob, int(aliceTask))
+	assert.ErrorIs(t, err, ErrNotFound)
+	events, err := store.GetTaskHistory(alice, int(aliceTask))
+	assert.NoError(t, err)
+	assert.Len(t, events, 3)
+}
+
+// Функция testUsers проверяет хранение пользователей и сессий: занятое имя, поиск по имени,
+// вход по действующей сессии и отказ по истекшей или удаленной сессии.
+func testUsers(t *testing.T, store TaskStore) {
+	t.Helper()
+	now := time.Now()
+
+	id, err := store.CreateUser(User{Username: "alice", PasswordHash: "hash"})
+	assert.NoError(t, err)
+	_, err = store.CreateUser(User{Username: "alice", PasswordHash: "other"})
+	assert.ErrorIs(t, err, ErrUserExists)
+
+	user, err := store.GetUserByName("alice")
+	assert.NoError(t, err)
+	assert.Equal(t, id, user.ID)
+	assert.Equal(t, "hash", user.PasswordHash)
+	_, err = store.GetUserByName("bob")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	assert.NoError(t, store.CreateSession(Session{TokenHash: "expired", UserID: id, ExpiresAt: now.Add(-time.Minute)}))
+	assert.NoError(t, store.CreateSession(Session{TokenHash: "active", UserID: id, ExpiresAt: now.Add(time.Hour)}))
+
+	user, err = store.GetSessionUser("active", now)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", user.Username)
+	_, err = store.GetSessionUser("expired", now)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	_, err = store.GetSessionUser("active", now.Add(2*time.Hour))
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+
+	assert.NoError(t, store.DeleteSession("active"))
+	assert.NoError(t, store.DeleteSession("active"))
+	_, err = store.GetSessionUser("active", now)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+// Функция testTokens проверяет хранение персональных токенов доступа: вход по действующему
+// токену с отметкой времени использования, отказ по истекшему или отозванному токену
+// и то, что пользователь не видит и не отзывает чужие токены.
+func testTokens(t *testing.T, store TaskStore) {
+	t.Helper()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	alice, err := store.CreateUser(User{Username: "alice", PasswordHash: "hash"})
+	assert.NoError(t, err)
+	bob, err := store.CreateUser(User{Username: "bob", PasswordHash: "hash"})
+	assert.NoError(t, err)
+
+	active, err := store.CreateAPIToken(APIToken{
+		UserID: alice, Name: "ci", TokenHash: "active",
+		Scopes: []string{ScopeTasksRead, ScopeTasksWrite}, ExpiresAt: now.Add(time.Hour),
+	})
+	assert.NoError(t, err)
+	_, err = store.CreateAPIToken(APIToken{
+		UserID: alice, Name: "old", TokenHash: "expired",
+		Scopes: []string{ScopeTasksRead}, ExpiresAt: now.Add(-time.Minute),
+	})
+	assert.NoError(t, err)
+
+	user, token, err := store.GetTokenUser("active", now)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", user.Username)
+	assert.Equal(t, active, token.ID)
+	assert.Equal(t, []string{ScopeTasksRead, ScopeTasksWrite}, token.Scopes)
+	if assert.NotNil(t, token.LastUsedAt) {
+		assert.True(t, now.Equal(*token.LastUsedAt))
+	}
+	_, _, err = store.GetTokenUser("expired", now)
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+	_, _, err = store.GetTokenUser("active", now.Add(2*time.Hour))
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+
+	tokens, err := store.GetAPITokens(alice)
+	assert.NoError(t, err)
+	if assert.Len(t, tokens, 2) {
+		assert.Equal(t, "ci", tokens[0].Name)
+		assert.NotNil(t, tokens[0].LastUsedAt)
+		assert.Nil(t, tokens[1].LastUsedAt)
+	}
+	tokens, err = store.GetAPITokens(bob)
+	assert.NoError(t, err)
+	assert.Empty(t, tokens)
+
+	assert.ErrorIs(t, store.DeleteAPIToken(bob, active), ErrTokenNotFound)
+	assert.NoError(t, store.DeleteAPIToken(alice, active))
+	assert.ErrorIs(t, store.DeleteAPIToken(alice, active), ErrTokenNotFound)
+	_, _, err = store.GetTokenUser("active", now)
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+}
+
+// Функция testLists проверяет участников общих списков задач: роль автора и участников,
+// смену роли, списки, открытые пользователю, и закрытие списка.
+func testLists(t *testing.T, store TaskStore) {
+	t.Helper()
+	alice, err := store.CreateUser(User{Username: "alice", PasswordHash: "hash"})
+	assert.NoError(t, err)
+	bob, err := store.CreateUser(User{Username: "bob", PasswordHash: "hash"})
+	assert.NoError(t, err)
+	carol, err := store.CreateUser(User{Username: "carol", PasswordHash: "hash"})
+	assert.NoError(t, err)
+
+	role, err := store.GetListRole(alice, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, RoleAdmin, role)
+	_, err = store.GetListRole(alice, bob)
+	assert.ErrorIs(t, err, ErrListNotFound)
+
+	assert.NoError(t, store.SetListMember(alice, bob, RoleViewer))
+	assert.NoError(t, store.SetListMember(carol, bob, RoleAdmin))
+	role, err = store.GetListRole(alice, bob)
+	assert.NoError(t, err)
+	assert.Equal(t, RoleViewer, role)
+	assert.NoError(t, store.SetListMember(alice, bob, RoleEditor))
+	role, err = store.GetListRole(alice, bob)
+	assert.NoError(t, err)
+	assert.Equal(t, RoleEditor, role)
+	// Участник чужого списка не получает доступа к спискам других участников.
+	_, err = store.GetListRole(bob, alice)
+	assert.ErrorIs(t, err, ErrListNotFound)
+
+	lists, err := store.GetTaskLists(bob)
+	assert.NoError(t, err)
+	assert.Equal(t, []TaskList{
+		{OwnerID: bob, OwnerName: "bob", Role: RoleAdmin},
+		{OwnerID: alice, OwnerName: "alice", Role: RoleEditor},
+		{OwnerID: carol, OwnerName: "carol", Role: RoleAdmin},
+	}, lists)
+
+	members, err := store.GetListMembers(alice)
+	assert.NoError(t, err)
+	assert.Equal(t, []ListMember{{OwnerID: alice, UserID: bob, Username: "bob", Role: RoleEditor}}, members)
+
+	assert.NoError(t, store.DeleteListMember(alice, bob))
+	assert.ErrorIs(t, store.DeleteListMember(alice, bob), ErrMemberNotFound)
+	_, err = store.GetListRole(alice, bob)
+	assert.ErrorIs(t, err, ErrListNotFound)
+}
+
+// Функция testProjects проверяет проекты: уникальность имени в списке пользователя, фильтр задач
+// по проекту и удаление проекта, после которого его задачи остаются без проекта.
+func testProjects(t *testing.T, store TaskStore) {
+	t.Helper()
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	alice, err := store.CreateUser(User{Username: "alice", PasswordHash: "hash"})
+	assert.NoError(t, err)
+	bob, err := store.CreateUser(User{Username: "bob", PasswordHash: "hash"})
+	assert.NoError(t, err)
+
+	work, err := store.CreateProject(Project{OwnerID: alice, Name: "Работа"})
+	assert.NoError(t, err)
+	home, err := store.CreateProject(Project{OwnerID: alice, Name: "Дом"})
+	assert.NoError(t, err)
+	_, err = store.CreateProject(Project{OwnerID: alice, Name: "Дом"})
+	assert.ErrorIs(t, err, ErrProjectExists)
+	// Имена проектов уникальны только в списке одного пользователя.
+	bobs, err := store.CreateProject(Project{OwnerID: bob, Name: "Дом"})
+	assert.NoError(t, err)
+
+	projects, err := store.GetProjects(alice)
+	assert.NoError(t, err)
+	if assert.Len(t, projects, 2) {
+		assert.Equal(t, []string{"Дом", "Работа"}, []string{projects[0].Name, projects[1].Name})
+	}
+	_, err = store.GetProject(alice, bobs)
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+
+	assert.ErrorIs(t, store.UpdateProject(Project{ID: work, OwnerID: alice, Name: "Дом"}), ErrProjectExists)
+	assert.ErrorIs(t, store.UpdateProject(Project{ID: bobs, OwnerID: alice, Name: "Чужой"}), ErrProjectNotFound)
+	assert.NoError(t, store.UpdateProject(Project{ID: work, OwnerID: alice, Name: "Офис"}))
+	project, err := store.GetProject(alice, work)
+	assert.NoError(t, err)
+	assert.Equal(t, "Офис", project.Name)
+
+	inProject, err := store.CreateTask(Task{OwnerID: alice, Text: "Отчет", CreatedDate: day, ExpectedDate: day,
+		Status: StatusInProgress, ProjectID: work})
+	assert.NoError(t, err)
+	withoutProject, err := store.CreateTask(Task{OwnerID: alice, Text: "Без проекта", CreatedDate: day, ExpectedDate: day,
+		Status: StatusInProgress})
+	assert.NoError(t, err)
+
+	page, err := store.GetAllTasks(TaskQuery{Owner: alice, Project: fmt.Sprint(work)})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{inProject}, taskIDs(page.Tasks))
+	assert.Equal(t, work, page.Tasks[0].ProjectID)
+	page, err = store.GetAllTasks(TaskQuery{Owner: alice, Project: NoProject})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{withoutProject}, taskIDs(page.Tasks))
+	page, err = store.GetAllTasks(TaskQuery{Owner: alice, Project: fmt.Sprint(home)})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Tasks)
+
+	// После удаления проекта задача остается в списке без проекта, с новой версией и записью в журнале.
+	assert.NoError(t, store.DeleteProject(alice, alice, work))
+	assert.ErrorIs(t, store.DeleteProject(alice, alice, work), ErrProjectNotFound)
+	assert.ErrorIs(t, store.DeleteProject(alice, alice, bobs), ErrProjectNotFound)
+	task, err := store.GetTaskByID(alice, int(inProject))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), task.ProjectID)
+	assert.Equal(t, int64(2), task.Version)
+
+	events, err := store.GetTaskHistory(alice, int(inProject))
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, EventUpdated, events[1].Type)
+		assert.Equal(t, &work, events[1].Old.ProjectID)
+		assert.Nil(t, events[1].New.ProjectID)
+	}
+}
+
+// Функция testTags проверяет метки задач: назначение и замену меток, фильтр задач по любой
+// или по всем меткам вместе с фильтром по статусу и сортировкой, а также подсказки меток.
+func testTags(t *testing.T, store TaskStore) {
+	t.Helper()
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	alice, err := store.CreateUser(User{Username: "alice", PasswordHash: "hash"})
+	assert.NoError(t, err)
+	bob, err := store.CreateUser(User{Username: "bob", PasswordHash: "hash"})
+	assert.NoError(t, err)
+
+	create := func(owner int64, text string, expected time.Time, tags ...string) int64 {
+		id, err := store.CreateTask(Task{OwnerID: owner, Text: text, CreatedDate: day, ExpectedDate: expected,
+			Status: StatusInProgress, Tags: tags})
+		assert.NoError(t, err)
+		return id
+	}
+	bugUI := create(alice, "Кнопка", day.AddDate(0, 0, 2), "ui", "bug")
+	bug := create(alice, "Падение", day.AddDate(0, 0, 1), "bug")
+	plain := create(alice, "Без меток", day)
+	create(bob, "Чужая", day, "bug", "backend")
+
+	task, err := store.GetTaskByID(alice, int(bugUI))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bug", "ui"}, task.Tags)
+
+	// ids возвращает задачи alice, отобранные фильтром query.
+	ids := func(query TaskQuery) []int64 {
+		query.Owner = alice
+		page, err := store.GetAllTasks(query)
+		assert.NoError(t, err)
+		return taskIDs(page.Tasks)
+	}
+	assert.Equal(t, []int64{bug, bugUI}, ids(TaskQuery{Tags: []string{"bug", "ui"}}))
+	assert.Equal(t, []int64{bugUI}, ids(TaskQuery{Tags: []string{"bug", "ui"}, TagMatch: TagMatchAll}))
+	assert.Equal(t, []int64{bug, bugUI}, ids(TaskQuery{Tags: []string{"bug"}, Status: "0", SortField: "expectedDate"}))
+	assert.Empty(t, ids(TaskQuery{Tags: []string{"backend"}}))
+	_, err = store.GetAllTasks(TaskQuery{Owner: alice, Tags: []string{"bug"}, TagMatch: "some"})
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+
+	// Без меток (nil) обновление не изменяет их, а пустой список снимает все метки.
+	_, err = store.UpdateTask(Task{ID: plain, OwnerID: alice, Text: "Без меток", CreatedDate: day, ExpectedDate: day,
+		Status: StatusInProgress, Tags: []string{"docs"}})
+	assert.NoError(t, err)
+	_, err = store.UpdateTask(Task{ID: plain, OwnerID: alice, Text: "Документация", CreatedDate: day, ExpectedDate: day,
+		Status: StatusInProgress})
+	assert.NoError(t, err)
+	task, err = store.GetTaskByID(alice, int(plain))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docs"}, task.Tags)
+	_, err = store.UpdateTask(Task{ID: bug, OwnerID: alice, Text: "Падение", CreatedDate: day, ExpectedDate: day,
+		Status: StatusInProgress, Tags: []string{}})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{bugUI}, ids(TaskQuery{Tags: []string{"bug"}}))
+
+	// Подсказки учитывают только задачи пользователя вне корзины.
+	_, err = store.UpdateTask(Task{ID: bug, OwnerID: alice, Text: "Падение", CreatedDate: day, ExpectedDate: day,
+		Status: StatusInProgress, Tags: []string{"bug", "build"}})
+	assert.NoError(t, err)
+	tags, err := store.GetTags(alice, "b", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []Tag{{Name: "bug", Count: 2}, {Name: "build", Count: 1}}, tags)
+
+	// Смена одних только меток попадает в журнал, поэтому в истории нет пропусков версий.
+	events, err := store.GetTaskHistory(alice, int(bug))
+	assert.NoError(t, err)
+	if assert.Len(t, events, 3) {
+		assert.Equal(t, "bug", events[0].New.Tags)
+		assert.Equal(t, EventUpdated, events[2].Type)
+		assert.Equal(t, "", events[2].Old.Tags)
+		assert.Equal(t, "bug,build", events[2].New.Tags)
+		assert.Equal(t, []int64{1, 2, 3}, []int64{events[0].New.Version, events[1].New.Version, events[2].New.Version})
+	}
+	tags, err = store.GetTags(alice, "", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []Tag{{Name: "bug", Count: 2}}, tags)
+	assert.NoError(t, store.DeleteTask(alice, alice, int(bug), 0))
+	tags, err = store.GetTags(alice, "", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []Tag{{Name: "bug", Count: 1}, {Name: "docs", Count: 1}, {Name: "ui", Count: 1}}, tags)
+	tags, err = store.GetTags(alice, "%", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, tags)
+}
+
+// Функция testPriority проверяет приоритет задач: сохранение и изменение приоритета,
+// порядок по умолчанию (сначала важные, затем ближайшие по сроку), его обращение и сортировку по приоритету.
+func testPriority(t *testing.T, store TaskStore) {
+	t.Helper()
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	create := func(priority Priority, expected time.Time) int64 {
+		id, err := store.CreateTask(Task{OwnerID: testOwner, Text: "Task", CreatedDate: day, ExpectedDate: expected,
+			Status: StatusInProgress, Priority: priority})
+		assert.NoError(t, err)
+		return id
+	}
+	later := create(PriorityNormal, day.AddDate(0, 0, 2))
+	low := create(PriorityLow, day)
+	urgent := create(PriorityUrgent, day.AddDate(0, 0, 5))
+	sooner := create(PriorityNormal, day.AddDate(0, 0, 1))
+
+	task, err := store.GetTaskByID(testOwner, int(urgent))
+	assert.NoError(t, err)
+	assert.Equal(t, PriorityUrgent, task.Priority)
+
+	// ids возвращает задачи в порядке выборки query.
+	ids := func(query TaskQuery) []int64 {
+		query.Owner = testOwner
+		page, err := store.GetAllTasks(query)
+		assert.NoError(t, err)
+		return taskIDs(page.Tasks)
+	}
+	assert.Equal(t, []int64{urgent, sooner, later, low}, ids(TaskQuery{}))
+	assert.Equal(t, []int64{low, later, sooner, urgent}, ids(TaskQuery{SortOrder: "desc"}))
+	assert.Equal(t, []int64{low, later, sooner, urgent}, ids(TaskQuery{SortField: "priority"}))
+
+	task, err = store.GetTaskByID(testOwner, int(low))
+	assert.NoError(t, err)
+	task.Priority = PriorityHigh
+	_, err = store.UpdateTask(task)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{urgent, low, sooner, later}, ids(TaskQuery{}))
+}
+
+// Функция testOwnerlessTasks проверяет передачу задач без автора, созданных до появления пользователей:
+// их получает только существующий пользователь вместе с журналом, а задачи других авторов не меняются.
+func testOwnerlessTasks(t *testing.T, store TaskStore) {
+	t.Helper()
+	day := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	alice, err := store.CreateUser(User{Username: "alice", PasswordHash: "hash"})
+	assert.NoError(t, err)
+	bob, err := store.CreateUser(User{Username: "bob", PasswordHash: "hash"})
+	assert.NoError(t, err)
+	legacy, err := store.CreateTask(Task{Text: "Без автора", CreatedDate: day, ExpectedDate: day})
+	assert.NoError(t, err)
+	bobTask, err := store.CreateTask(Task{OwnerID: bob, Text: "Задача Боба", CreatedDate: day, ExpectedDate: day})
+	assert.NoError(t, err)
+
+	_, err = store.ClaimOwnerlessTasks(bob + 100)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	_, err = store.GetTaskByID(alice, int(legacy))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	claimed, err := store.ClaimOwnerlessTasks(alice)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), claimed)
+	claimed, err = store.ClaimOwnerlessTasks(bob)
+	assert.NoError(t, err)
+	assert.Zero(t, claimed)
+
+	page, err := store.GetAllTasks(TaskQuery{Owner: alice})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{legacy}, taskIDs(page.Tasks))
+	events, err := store.GetTaskHistory(alice, int(legacy))
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	page, err = store.GetAllTasks(TaskQuery{Owner: bob})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{bobTask}, taskIDs(page.Tasks))
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"slices"
+	"strings"
+)
+
+// Режимы фильтра задач по меткам (TaskQuery.TagMatch).
+const (
+	// TagMatchAny выбирает задачи, у которых есть хотя бы одна из меток фильтра (по умолчанию).
+	TagMatchAny = "any"
+	// TagMatchAll выбирает задачи, у которых есть все метки фильтра.
+	TagMatchAll = "all"
+)
+
+// Структура Tag - метка задач с количеством задач списка, которым она назначена.
+type Tag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Интерфейс TagStore описывает подсказки меток. Метки, как и проекты, принадлежат списку задач
+// пользователя; отдельно они не создаются, а появляются при назначении задаче (Task.Tags).
+type TagStore interface {
+	// GetTags возвращает не более limit меток пользователя owner, начинающихся с prefix,
+	// в порядке убывания количества задач (без корзины), а при равенстве - в порядке имен.
+	// Метки, которые не назначены ни одной задаче, не возвращаются.
+	GetTags(owner int64, prefix string, limit int) ([]Tag, error)
+}
+
+// Функция splitTags разбирает метки задачи, объединенные запросом через запятую, в порядке имен.
+func splitTags(joined string) []string {
+	if joined == "" {
+		return nil
+	}
+	tags := strings.Split(joined, ",")
+	slices.Sort(tags)
+	return tags
+}
+
+// Функция escapeLike экранирует спецсимволы шаблона LIKE, чтобы префикс сопоставлялся буквально.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
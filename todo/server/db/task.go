@@ -0,0 +1,119 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Структура Task представляет задачу.
+type Task struct {
+	ID           int64     `json:"id"`
+	Text         string    `json:"text"`
+	CreatedDate  time.Time `json:"createdDate"`
+	ExpectedDate time.Time `json:"expectedDate"`
+	Status       Status    `json:"status"`
+	Priority     Priority  `json:"priority"`
+	// Version - номер версии задачи, увеличивается при каждом изменении.
+	Version int64 `json:"version"`
+	// Rank - релевантность задачи при полнотекстовом поиске (заполняется только при поиске).
+	Rank float64 `json:"-"`
+	// DeletedAt - время перемещения задачи в корзину (заполняется только при выборке из корзины).
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	// OwnerID - ID пользователя, создавшего задачу (0 - автор неизвестен).
+	OwnerID int64 `json:"-"`
+	// ProjectID - ID проекта задачи (0 - задача не входит в проект).
+	ProjectID int64 `json:"projectId"`
+	// Tags - имена меток задачи в порядке имен. При обновлении nil оставляет метки задачи без изменений.
+	Tags []string `json:"tags"`
+	// EditorID - ID пользователя, который создает или изменяет задачу; записывается в журнал изменений
+	// (0 - пользователь неизвестен). Из хранилища не считывается.
+	EditorID int64 `json:"-"`
+	// Reason - причина смены статуса, которая записывается в журнал вместе со сменой статуса.
+	// Из хранилища не считывается.
+	Reason string `json:"-"`
+}
+
+// Вспомогательная структура для сериализации Task.
+type TaskDTO struct {
+	ID           int64     `json:"id"`
+	Text         string    `json:"text"`
+	CreatedDate  string    `json:"createdDate"`
+	ExpectedDate string    `json:"expectedDate"`
+	Status       StatusRef `json:"status"`
+	// Priority - имя приоритета: "low", "normal", "high" или "urgent".
+	Priority string `json:"priority"`
+	// ProjectID - ID проекта задачи или null, если задача не входит в проект.
+	ProjectID *int64 `json:"projectId"`
+	// Tags - метки задачи; в ответах всегда массив. Если поле не передано при обновлении, метки не изменяются.
+	Tags    []string `json:"tags"`
+	Version int64    `json:"version"`
+	// DeletedAt - время удаления в формате RFC 3339, только у задач из корзины.
+	DeletedAt string `json:"deletedAt,omitempty"`
+	// Reason - необязательная причина смены статуса при изменении задачи; в ответах не возвращается.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Метод для преобразования Task в TaskDTO. Статус записывается номером,
+// имя статуса подставляет Workflow.ToDTO.
+func (t *Task) ToDTO() TaskDTO {
+	dto := TaskDTO{
+		ID:           t.ID,
+		Text:         t.Text,
+		CreatedDate:  t.CreatedDate.Format("2006-01-02"),
+		ExpectedDate: t.ExpectedDate.Format("2006-01-02"),
+		Status:       RefOf(t.Status),
+		Priority:     t.Priority.String(),
+		Tags:         []string{},
+		Version:      t.Version,
+	}
+	if t.Tags != nil {
+		dto.Tags = t.Tags
+	}
+	if t.ProjectID != 0 {
+		projectID := t.ProjectID
+		dto.ProjectID = &projectID
+	}
+	if t.DeletedAt != nil {
+		dto.DeletedAt = t.DeletedAt.UTC().Format(time.RFC3339)
+	}
+	return dto
+}
+
+// Метод для преобразования TaskDTO в Task. Статус должен быть задан номером,
+// имена статусов разрешаются через Workflow.Lookup.
+func (dto *TaskDTO) ToTask() (Task, error) {
+	createdDate, err := time.Parse("2006-01-02", dto.CreatedDate)
+	if err != nil {
+		return Task{}, err
+	}
+	expectedDate, err := time.Parse("2006-01-02", dto.ExpectedDate)
+	if err != nil {
+		return Task{}, err
+	}
+	status, err := strconv.Atoi(string(dto.Status))
+	if err != nil {
+		return Task{}, err
+	}
+	priority := PriorityNormal
+	if dto.Priority != "" {
+		var ok bool
+		if priority, ok = ParsePriority(dto.Priority); !ok {
+			return Task{}, fmt.Errorf("unknown task priority: %q", dto.Priority)
+		}
+	}
+	task := Task{
+		ID:           dto.ID,
+		Text:         dto.Text,
+		CreatedDate:  createdDate,
+		ExpectedDate: expectedDate,
+		Status:       Status(status),
+		Priority:     priority,
+		Tags:         dto.Tags,
+		Version:      dto.Version,
+	}
+	if dto.ProjectID != nil {
+		task.ProjectID = *dto.ProjectID
+	}
+	return task, nil
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskToDTO(t *testing.T) {
+	task := Task{
+		ID:           1,
+		Text:         "Test task",
+		CreatedDate:  time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC),
+		ExpectedDate: time.Date(2023, 10, 10, 0, 0, 0, 0, time.UTC),
+		Status:       StatusInProgress,
+	}
+
+	dto := task.ToDTO()
+
+	if dto.ID != task.ID {
+		t.Errorf("expected ID %d, got %d", task.ID, dto.ID)
+	}
+	if dto.Text != task.Text {
+		t.Errorf("expected Text %s, got %s", task.Text, dto.Text)
+	}
+	if dto.CreatedDate != "2023-10-01" {
+		t.Errorf("expected CreatedDate 2023-10-01, got %s", dto.CreatedDate)
+	}
+	if dto.ExpectedDate != "2023-10-10" {
+		t.Errorf("expected ExpectedDate 2023-10-10, got %s", dto.ExpectedDate)
+	}
+	if dto.Status != "0" {
+		t.Errorf("expected Status 0, got %s", dto.Status)
+	}
+}
+
+func TestTaskDTOToTask(t *testing.T) {
+	dto := TaskDTO{
+		ID:           1,
+		Text:         "Test task",
+		CreatedDate:  "2023-10-01",
+		ExpectedDate: "2023-10-10",
+		Status:       "0",
+	}
+
+	task, err := dto.ToTask()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedCreatedDate := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	expectedExpectedDate := time.Date(2023, 10, 10, 0, 0, 0, 0, time.UTC)
+
+	if task.ID != dto.ID {
+		t.Errorf("expected ID %d, got %d", dto.ID, task.ID)
+	}
+	if task.Text != dto.Text {
+		t.Errorf("expected Text %s, got %s", dto.Text, task.Text)
+	}
+	if !task.CreatedDate.Equal(expectedCreatedDate) {
+		t.Errorf("expected CreatedDate %v, got %v", expectedCreatedDate, task.CreatedDate)
+	}
+	if !task.ExpectedDate.Equal(expectedExpectedDate) {
+		t.Errorf("expected ExpectedDate %v, got %v", expectedExpectedDate, task.ExpectedDate)
+	}
+	if task.Status != StatusInProgress {
+		t.Errorf("expected Status %d, got %d", StatusInProgress, task.Status)
+	}
+}
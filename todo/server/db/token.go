@@ -0,0 +1,78 @@
+package db
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrTokenNotFound возвращается хранилищем, если токена нет, он отозван или срок его действия истек.
+var ErrTokenNotFound = errors.New("token not found")
+
+// Разрешения (scopes) персональных токенов доступа.
+const (
+	// ScopeTasksRead разрешает чтение задач и статусов.
+	ScopeTasksRead = "tasks:read"
+	// ScopeTasksWrite разрешает создание, изменение и удаление задач и статусов.
+	ScopeTasksWrite = "tasks:write"
+)
+
+// Список всех разрешений, которые можно выдать токену.
+var Scopes = []string{ScopeTasksRead, ScopeTasksWrite}
+
+// Структура APIToken представляет персональный токен доступа к API.
+type APIToken struct {
+	ID     int64
+	UserID int64
+	Name   string
+	// TokenHash - SHA-256 токена; сам токен показывается пользователю только при создании.
+	TokenHash  string
+	Scopes     []string
+	ExpiresAt  time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// Метод HasScope сообщает, выдано ли токену разрешение scope.
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Вспомогательная структура для сериализации APIToken. Хеш токена в API не передается,
+// а сам токен (Token) заполняется только в ответе на создание.
+type APITokenDTO struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  string   `json:"expiresAt"`
+	LastUsedAt string   `json:"lastUsedAt,omitempty"`
+	CreatedAt  string   `json:"createdAt"`
+	Token      string   `json:"token,omitempty"`
+}
+
+// Метод для преобразования APIToken в APITokenDTO. Время передается в формате RFC 3339.
+func (t *APIToken) ToDTO() APITokenDTO {
+	dto := APITokenDTO{
+		ID:        t.ID,
+		Name:      t.Name,
+		Scopes:    t.Scopes,
+		ExpiresAt: t.ExpiresAt.UTC().Format(time.RFC3339),
+		CreatedAt: t.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if t.LastUsedAt != nil {
+		dto.LastUsedAt = t.LastUsedAt.UTC().Format(time.RFC3339)
+	}
+	return dto
+}
+
+// Структура APITokenRequest - тело запроса на создание токена.
+// Если срок действия не указан, он задается при проверке запроса.
+type APITokenRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUserExists возвращается при регистрации пользователя с уже занятым именем.
+var ErrUserExists = errors.New("user already exists")
+
+// ErrUserNotFound возвращается хранилищем, если пользователя с указанным именем нет.
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrSessionNotFound возвращается хранилищем, если сессии нет или срок её действия истек.
+var ErrSessionNotFound = errors.New("session not found")
+
+// Структура User представляет пользователя.
+type User struct {
+	ID       int64
+	Username string
+	// PasswordHash - хеш пароля bcrypt; сам пароль не хранится.
+	PasswordHash string
+	CreatedAt    time.Time
+}
+
+// Вспомогательная структура для сериализации User. Хеш пароля в API не передается.
+type UserDTO struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// Метод для преобразования User в UserDTO.
+func (u *User) ToDTO() UserDTO {
+	return UserDTO{ID: u.ID, Username: u.Username}
+}
+
+// Структура Credentials - имя пользователя и пароль из запросов регистрации и входа.
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Структура Session - сессия пользователя после входа.
+type Session struct {
+	// TokenHash - SHA-256 токена сессии; сам токен известен только клиенту (в cookie).
+	TokenHash string
+	UserID    int64
+	ExpiresAt time.Time
+}
+
+// Интерфейс UserStore описывает хранилище пользователей, их сессий и персональных токенов доступа.
+type UserStore interface {
+	// CreateUser сохраняет нового пользователя и возвращает его ID.
+	// Если имя уже занято, возвращается ErrUserExists.
+	CreateUser(user User) (int64, error)
+	// GetUserByName возвращает пользователя по имени или ErrUserNotFound.
+	GetUserByName(username string) (User, error)
+	// CreateSession сохраняет сессию пользователя, удаляя его сессии с истекшим сроком.
+	CreateSession(session Session) error
+	// GetSessionUser возвращает пользователя сессии, действующей на момент now,
+	// или ErrSessionNotFound.
+	GetSessionUser(tokenHash string, now time.Time) (User, error)
+	// DeleteSession удаляет сессию. Удаление несуществующей сессии не считается ошибкой.
+	DeleteSession(tokenHash string) error
+	// CreateAPIToken сохраняет персональный токен доступа и возвращает его ID.
+	CreateAPIToken(token APIToken) (int64, error)
+	// GetAPITokens возвращает токены пользователя, в том числе с истекшим сроком, в порядке создания.
+	GetAPITokens(userID int64) ([]APIToken, error)
+	// DeleteAPIToken отзывает токен пользователя или возвращает ErrTokenNotFound.
+	DeleteAPIToken(userID, id int64) error
+	// GetTokenUser возвращает владельца и сам токен, действующий на момент now, и записывает
+	// now как время последнего использования токена. Если токена нет, возвращается ErrTokenNotFound.
+	GetTokenUser(tokenHash string, now time.Time) (User, APIToken, error)
+}
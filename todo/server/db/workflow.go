@@ -0,0 +1,251 @@
+package db
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+)
+
+// ErrStatusNotFound возвращается хранилищем, если статуса с указанным именем нет.
+var ErrStatusNotFound = errors.New("status not found")
+
+// ErrStatusExists возвращается при создании статуса с уже занятым именем.
+var ErrStatusExists = errors.New("status already exists")
+
+// ErrStatusInUse возвращается при удалении статуса, в котором находятся задачи.
+var ErrStatusInUse = errors.New("status is in use")
+
+// Структура StatusDef описывает статус рабочего процесса задач.
+type StatusDef struct {
+	// ID - номер статуса, который хранится в задаче.
+	ID Status `json:"id"`
+	// Name - неизменяемое имя статуса в API (напр. "in_progress").
+	Name string `json:"name"`
+	// Title - название статуса для интерфейса.
+	Title string `json:"title"`
+	// Position - порядок статуса в списках; первый по порядку статус - начальный.
+	Position int `json:"position"`
+	// Color - цвет статуса в интерфейсе в формате #rrggbb.
+	Color string `json:"color"`
+	// Terminal - признак завершающего статуса: работа над задачей в нем окончена.
+	Terminal bool `json:"terminal"`
+	// Transitions - имена статусов, в которые можно перевести задачу из этого статуса.
+	Transitions []string `json:"transitions"`
+}
+
+// Структура Workflow - набор статусов задач, упорядоченный по Position.
+// Рабочий процесс хранится в базе данных и изменяется через API, поэтому проверки
+// статусов задач выполняются по нему, а не по списку констант.
+type Workflow struct {
+	Statuses []StatusDef
+}
+
+// Функция DefaultWorkflow возвращает встроенный рабочий процесс: задача завершается только
+// после тестирования, а возвращенная задача снова попадает в работу.
+// Миграция 0005_create_statuses заполняет таблицы статусов теми же данными.
+func DefaultWorkflow() Workflow {
+	return Workflow{Statuses: []StatusDef{
+		{ID: StatusInProgress, Name: "in_progress", Title: "В процессе", Position: 10, Color: "#2196f3",
+			Transitions: []string{"testing"}},
+		{ID: StatusTesting, Name: "testing", Title: "Тестирование", Position: 20, Color: "#ff9800",
+			Transitions: []string{"returned", "completed"}},
+		{ID: StatusReturned, Name: "returned", Title: "Возвращено", Position: 30, Color: "#9c27b0",
+			Transitions: []string{"in_progress"}},
+		{ID: StatusCompleted, Name: "completed", Title: "Завершено", Position: 40, Color: "#4caf50", Terminal: true,
+			Transitions: []string{"returned"}},
+	}}
+}
+
+// Метод Lookup находит статус по имени ("testing") или по номеру ("2").
+func (w Workflow) Lookup(ref string) (StatusDef, bool) {
+	for _, def := range w.Statuses {
+		if def.Name == ref {
+			return def, true
+		}
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		return w.ByID(Status(n))
+	}
+	return StatusDef{}, false
+}
+
+// Метод ByID находит статус по его номеру.
+func (w Workflow) ByID(id Status) (StatusDef, bool) {
+	for _, def := range w.Statuses {
+		if def.ID == id {
+			return def, true
+		}
+	}
+	return StatusDef{}, false
+}
+
+// Метод Initial возвращает статус, который получают новые задачи без указанного статуса, - первый по порядку.
+func (w Workflow) Initial() (StatusDef, bool) {
+	if len(w.Statuses) == 0 {
+		return StatusDef{}, false
+	}
+	return w.Statuses[0], true
+}
+
+// Метод CanTransition проверяет, разрешен ли переход задачи из статуса from в статус to.
+// Сохранение задачи без смены статуса разрешено всегда.
+func (w Workflow) CanTransition(from, to Status) bool {
+	if from == to {
+		return true
+	}
+	fromDef, ok := w.ByID(from)
+	if !ok {
+		return false
+	}
+	toDef, ok := w.ByID(to)
+	return ok && slices.Contains(fromDef.Transitions, toDef.Name)
+}
+
+// Метод Name возвращает имя статуса или его номер, если статуса нет в рабочем процессе.
+func (w Workflow) Name(status Status) string {
+	if def, ok := w.ByID(status); ok {
+		return def.Name
+	}
+	return strconv.Itoa(int(status))
+}
+
+// Метод ToDTO преобразует задачу в TaskDTO, подставляя имя её статуса.
+func (w Workflow) ToDTO(task Task) TaskDTO {
+	dto := task.ToDTO()
+	dto.Status = StatusRef(w.Name(task.Status))
+	return dto
+}
+
+// Метод sort упорядочивает статусы по Position, а при равенстве - по номеру.
+func (w Workflow) sort() {
+	slices.SortStableFunc(w.Statuses, func(a, b StatusDef) int {
+		if a.Position != b.Position {
+			return a.Position - b.Position
+		}
+		return int(a.ID) - int(b.ID)
+	})
+}
+
+// Метод clone возвращает копию рабочего процесса, не разделяющую срезы с исходным.
+// Переходы упорядочиваются по порядку статусов, как их возвращает PostgresStore.
+func (w Workflow) clone() Workflow {
+	order := func(name string) int {
+		return slices.IndexFunc(w.Statuses, func(def StatusDef) bool { return def.Name == name })
+	}
+	statuses := make([]StatusDef, len(w.Statuses))
+	for i, def := range w.Statuses {
+		def.Transitions = slices.Clone(def.Transitions)
+		slices.SortStableFunc(def.Transitions, func(a, b string) int { return order(a) - order(b) })
+		statuses[i] = def
+	}
+	return Workflow{Statuses: statuses}
+}
+
+// Структура Workflows - рабочие процессы задач: рабочий процесс по умолчанию и собственные
+// рабочие процессы проектов. Номера статусов уникальны во всех рабочих процессах, поэтому номер
+// статуса задачи однозначно определяет статус, в каком бы проекте задача ни находилась.
+type Workflows struct {
+	// Default - рабочий процесс задач без проекта и проектов без собственного рабочего процесса.
+	Default Workflow
+	// Projects - собственные рабочие процессы проектов по их ID.
+	Projects map[int64]Workflow
+}
+
+// Метод For возвращает рабочий процесс задач проекта project (0 - задач без проекта).
+func (w Workflows) For(project int64) Workflow {
+	if scope := w.Scope(project); scope != 0 {
+		return w.Projects[scope]
+	}
+	return w.Default
+}
+
+// Метод Scope возвращает проект, рабочий процесс которого действует для задач проекта project:
+// сам project, если у него есть собственный рабочий процесс, иначе 0 (рабочий процесс по умолчанию).
+func (w Workflows) Scope(project int64) int64 {
+	if _, ok := w.Projects[project]; ok && project != 0 {
+		return project
+	}
+	return 0
+}
+
+// Метод All возвращает статусы всех рабочих процессов. Имена статусов в нем могут повторяться,
+// поэтому он подходит только для поиска статусов по номеру, напр. в журнале изменений задачи,
+// которая могла переходить между проектами.
+func (w Workflows) All() Workflow {
+	projects := make([]int64, 0, len(w.Projects))
+	for project := range w.Projects {
+		projects = append(projects, project)
+	}
+	slices.Sort(projects)
+	all := slices.Clone(w.Default.Statuses)
+	for _, project := range projects {
+		all = append(all, w.Projects[project].Statuses...)
+	}
+	return Workflow{Statuses: all}
+}
+
+// Метод StatusIDs возвращает номера статусов с именем или номером ref во всех рабочих процессах.
+func (w Workflows) StatusIDs(ref string) []Status {
+	var ids []Status
+	for _, def := range w.All().Statuses {
+		if def.Name == ref || strconv.Itoa(int(def.ID)) == ref {
+			ids = append(ids, def.ID)
+		}
+	}
+	return ids
+}
+
+// Метод ToDTO преобразует задачу в TaskDTO по рабочему процессу её проекта.
+func (w Workflows) ToDTO(task Task) TaskDTO {
+	return w.For(task.ProjectID).ToDTO(task)
+}
+
+// Метод moveStatus возвращает статус, который получает задача в статусе status при переносе
+// в проект project: статус рабочего процесса проекта с тем же именем или, если такого нет, начальный.
+func (w Workflows) moveStatus(status Status, project int64) Status {
+	target := w.For(project)
+	if _, ok := target.ByID(status); ok {
+		return status
+	}
+	if def, ok := w.All().ByID(status); ok {
+		if moved, ok := target.Lookup(def.Name); ok {
+			return moved.ID
+		}
+	}
+	if initial, ok := target.Initial(); ok {
+		return initial.ID
+	}
+	return status
+}
+
+// Метод clone возвращает копию рабочих процессов, не разделяющую срезы и словари с исходной.
+func (w Workflows) clone() Workflows {
+	projects := make(map[int64]Workflow, len(w.Projects))
+	for project, workflow := range w.Projects {
+		projects[project] = workflow.clone()
+	}
+	return Workflows{Default: w.Default.clone(), Projects: projects}
+}
+
+// Интерфейс StatusStore описывает хранилище статусов рабочих процессов. Рабочий процесс
+// по умолчанию действует для задач без проекта и изменяется только администраторами сервера
+// (см. handlers.StatusHandler). Проект может завести собственный рабочий процесс: при первом
+// изменении статусов проекта хранилище копирует в него рабочий процесс по умолчанию и переводит
+// задачи проекта в статусы-копии с теми же именами. Методы изменения принимают проект project
+// (0 - рабочий процесс по умолчанию); существование проекта проверяют обработчики.
+type StatusStore interface {
+	// GetWorkflows возвращает рабочий процесс по умолчанию и собственные рабочие процессы проектов
+	// со статусами, упорядоченными по Position.
+	GetWorkflows() (Workflows, error)
+	// GetWorkflow возвращает рабочий процесс задач проекта project (0 - задач без проекта).
+	GetWorkflow(project int64) (Workflow, error)
+	// CreateStatus сохраняет новый статус в рабочем процессе проекта project и возвращает его номер.
+	// Если имя уже занято в этом рабочем процессе, возвращается ErrStatusExists.
+	CreateStatus(project int64, def StatusDef) (Status, error)
+	// UpdateStatus изменяет статус с именем def.Name (кроме имени и номера) и его переходы
+	// в рабочем процессе проекта project. Если статуса нет, возвращается ErrStatusNotFound.
+	UpdateStatus(project int64, def StatusDef) error
+	// DeleteStatus удаляет статус рабочего процесса проекта project и переходы в него.
+	// Если в статусе есть задачи, возвращается ErrStatusInUse.
+	DeleteStatus(project int64, name string) error
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Mr-Cheen1/todo_list/server/auth"
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/Mr-Cheen1/todo_list/server/validation"
+)
+
+// Имя cookie с токеном сессии.
+const sessionCookie = "session"
+
+// Срок действия сессии после входа.
+const sessionTTL = 7 * 24 * time.Hour
+
+// Ошибка запроса без действующей сессии.
+var errUnauthenticated = errors.New("authentication required")
+
+// Ошибка входа с неверным именем пользователя или паролем.
+var errInvalidCredentials = errors.New("invalid username or password")
+
+// Структура AuthHandler содержит обработчики регистрации, входа и выхода пользователей,
+// управления персональными токенами доступа и промежуточный обработчик, пропускающий к API
+// только аутентифицированные запросы.
+type AuthHandler struct {
+	store db.UserStore
+}
+
+// Функция NewAuthHandler создает обработчики, работающие с переданным хранилищем пользователей.
+func NewAuthHandler(store db.UserStore) *AuthHandler {
+	return &AuthHandler{store: store}
+}
+
+// Метод Register регистрирует маршруты API аутентификации и персональных токенов в mux.
+// Регистрация, вход и выход доступны без сессии, поэтому их нельзя оборачивать в RequireUser.
+// Токенами управляют только через сессию: запрос с токеном не может выпустить новый токен.
+func (h *AuthHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/auth/register", h.RegisterUser)
+	mux.HandleFunc("POST /api/auth/login", h.Login)
+	mux.HandleFunc("POST /api/auth/logout", h.Logout)
+	mux.Handle("GET /api/auth/me", h.RequireUser(http.HandlerFunc(h.Me)))
+	mux.Handle("GET /api/tokens", h.RequireUser(requireSession(h.ListTokens)))
+	mux.Handle("POST /api/tokens", h.RequireUser(requireSession(h.CreateToken)))
+	mux.Handle("DELETE /api/tokens/{id}", h.RequireUser(requireSession(h.RevokeToken)))
+}
+
+// Обработчик для регистрации пользователя. После регистрации пользователь сразу входит в систему.
+func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
+	var creds db.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		writeError(w, r, badRequest("Invalid credentials JSON: %v", err))
+		return
+	}
+
+	creds, err := validation.NewUser(creds)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	hash, err := auth.HashPassword(creds.Password)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	user := db.User{Username: creds.Username, PasswordHash: hash}
+	user.ID, err = h.store.CreateUser(user)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.startSession(w, r, user); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, user.ToDTO())
+}
+
+// Обработчик для входа по имени пользователя и паролю. Токен сессии передается в cookie.
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var creds db.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		writeError(w, r, badRequest("Invalid credentials JSON: %v", err))
+		return
+	}
+
+	user, err := h.store.GetUserByName(validation.NormalizeUsername(creds.Username))
+	if err != nil && !errors.Is(err, db.ErrUserNotFound) {
+		writeError(w, r, err)
+		return
+	}
+	// Для неизвестного пользователя хеш пуст, но пароль все равно проверяется,
+	// чтобы по времени ответа нельзя было подобрать существующие имена.
+	if !auth.CheckPassword(user.PasswordHash, creds.Password) {
+		writeError(w, r, errInvalidCredentials)
+		return
+	}
+
+	if err := h.startSession(w, r, user); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, user.ToDTO())
+}
+
+// Обработчик для выхода: сессия удаляется, а cookie сбрасывается.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		if err := h.store.DeleteSession(auth.HashToken(cookie.Value)); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name: sessionCookie, Path: "/", MaxAge: -1,
+		HttpOnly: true, Secure: r.TLS != nil, SameSite: http.SameSiteLaxMode,
+	})
+	w.WriteHeader(http.StatusOK)
+}
+
+// Обработчик для получения текущего пользователя.
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFrom(r.Context())
+	writeJSON(w, http.StatusOK, user.ToDTO())
+}
+
+// Метод RequireUser пропускает к next только запросы с действующей сессией или персональным
+// токеном доступа в заголовке "Authorization: Bearer", передавая пользователя (и токен)
+// через контекст запроса (auth.UserFrom, auth.TokenFrom). Остальным отвечает 401 Unauthorized.
+// Если заголовок Authorization передан, cookie сессии не проверяется.
+func (h *AuthHandler) RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if header := r.Header.Get("Authorization"); header != "" {
+			h.serveWithToken(w, r, header, next)
+			return
+		}
+		cookie, err := r.Cookie(sessionCookie)
+		if err != nil {
+			writeError(w, r, errUnauthenticated)
+			return
+		}
+		user, err := h.store.GetSessionUser(auth.HashToken(cookie.Value), time.Now())
+		if errors.Is(err, db.ErrSessionNotFound) {
+			writeError(w, r, errUnauthenticated)
+			return
+		}
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), user)))
+	})
+}
+
+// Метод serveWithToken аутентифицирует запрос по персональному токену доступа из заголовка
+// Authorization. Каждое успешное обращение обновляет время последнего использования токена.
+func (h *AuthHandler) serveWithToken(w http.ResponseWriter, r *http.Request, header string, next http.Handler) {
+	scheme, token, _ := strings.Cut(header, " ")
+	token = strings.TrimSpace(token)
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_request"`)
+		writeError(w, r, errUnauthenticated)
+		return
+	}
+	user, apiToken, err := h.store.GetTokenUser(auth.HashToken(token), time.Now())
+	if errors.Is(err, db.ErrTokenNotFound) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		writeError(w, r, errUnauthenticated)
+		return
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	ctx := auth.WithToken(auth.WithUser(r.Context(), user), apiToken)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// Метод startSession создает сессию пользователя и передает её токен в cookie.
+// Cookie недоступна скриптам страницы и не отправляется с запросами с других сайтов.
+func (h *AuthHandler) startSession(w http.ResponseWriter, r *http.Request, user db.User) error {
+	token, hash, err := auth.NewToken()
+	if err != nil {
+		return err
+	}
+
+	expires := time.Now().Add(sessionTTL)
+	if err := h.store.CreateSession(db.Session{TokenHash: hash, UserID: user.ID, ExpiresAt: expires}); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name: sessionCookie, Value: token, Path: "/", Expires: expires,
+		HttpOnly: true, Secure: r.TLS != nil, SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для регистрации, входа и выхода: доступ к защищенному маршруту дает только действующая сессия.
+func TestAuthHandlers(t *testing.T) {
+	store := db.NewMemoryStore()
+	mux := http.NewServeMux()
+	NewAuthHandler(store).Register(mux)
+
+	// serve выполняет запрос с cookie сессии (если она передана) и возвращает ответ.
+	serve := func(method, path, body string, session *http.Cookie) *httptest.ResponseRecorder {
+		req, err := http.NewRequestWithContext(context.Background(), method, path, strings.NewReader(body))
+		assert.NoError(t, err)
+		if session != nil {
+			req.AddCookie(session)
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+	// cookie возвращает cookie сессии из ответа.
+	cookie := func(rr *httptest.ResponseRecorder) *http.Cookie {
+		for _, c := range rr.Result().Cookies() {
+			if c.Name == sessionCookie {
+				return c
+			}
+		}
+		return nil
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve("GET", "/api/auth/me", "", nil).Code)
+
+	rr := serve("POST", "/api/auth/register", `{"username":"Alice","password":"password"}`, nil)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.JSONEq(t, `{"id":1,"username":"alice"}`, rr.Body.String())
+	session := cookie(rr)
+	if assert.NotNil(t, session) {
+		assert.True(t, session.HttpOnly)
+		assert.Equal(t, http.SameSiteLaxMode, session.SameSite)
+	}
+
+	rr = serve("GET", "/api/auth/me", "", session)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"id":1,"username":"alice"}`, rr.Body.String())
+
+	rr = serve("POST", "/api/auth/register", `{"username":"alice","password":"password"}`, nil)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	rr = serve("POST", "/api/auth/register", `{"username":"al","password":"short"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = serve("POST", "/api/auth/login", `{"username":"alice","password":"wrong password"}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	assert.Equal(t, "Invalid username or password", problem.Title)
+	assert.Nil(t, cookie(rr))
+	rr = serve("POST", "/api/auth/login", `{"username":"bob","password":"password"}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = serve("POST", "/api/auth/login", `{"username":"ALICE","password":"password"}`, nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	second := cookie(rr)
+	assert.NotNil(t, second)
+
+	// Выход завершает только свою сессию.
+	rr = serve("POST", "/api/auth/logout", "", session)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, -1, cookie(rr).MaxAge)
+	assert.Equal(t, http.StatusUnauthorized, serve("GET", "/api/auth/me", "", session).Code)
+	assert.Equal(t, http.StatusOK, serve("GET", "/api/auth/me", "", second).Code)
+}
+
+// Тест для ответа на запрос без сессии или с неизвестной сессией.
+func TestRequireUser(t *testing.T) {
+	h := NewAuthHandler(db.NewMemoryStore())
+	handler := h.RequireUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler must not be called without a session")
+	}))
+
+	for _, session := range []*http.Cookie{nil, {Name: sessionCookie, Value: "unknown"}} {
+		req, err := http.NewRequestWithContext(context.Background(), "GET", "/api/tasks", nil)
+		assert.NoError(t, err)
+		if session != nil {
+			req.AddCookie(session)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Body.String(), `"type":"/problems/unauthorized"`)
+	}
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/Mr-Cheen1/todo_list/server/validation"
+)
+
+// Типы проблем RFC 7807. Значение type стабильно и не зависит от текста сообщения,
+// поэтому клиенты могут обрабатывать ошибки по нему.
+const (
+	problemBadRequest         = "/problems/bad-request"
+	problemValidation         = "/problems/validation-error"
+	problemUnauthorized       = "/problems/unauthorized"
+	problemForbidden          = "/problems/forbidden"
+	problemNotFound           = "/problems/not-found"
+	problemMethodNotAllowed   = "/problems/method-not-allowed"
+	problemVersionConflict    = "/problems/version-conflict"
+	problemPreconditionFailed = "/problems/precondition-failed"
+	problemConflict           = "/problems/conflict"
+	problemInternal           = "/problems/internal-error"
+)
+
+// Структура Problem - описание ошибки в формате RFC 7807 (application/problem+json).
+// Для ошибок проверки задачи или статуса дополнительно передается список нарушений по полям.
+type Problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Errors   validation.Errors `json:"errors,omitempty"`
+}
+
+// Структура requestError - ошибка в самом запросе клиента (некорректный ID, параметр или тело).
+// Её текст предназначен для клиента и возвращается в поле detail.
+type requestError struct {
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+// Функция badRequest создает ошибку запроса клиента с сообщением по формату.
+func badRequest(format string, args ...interface{}) error {
+	return &requestError{message: fmt.Sprintf(format, args...)}
+}
+
+// Ошибки маршрутизации: путь не совпадает ни с одним маршрутом API или маршрут не поддерживает метод запроса.
+var (
+	errRouteNotFound    = errors.New("route not found")
+	errMethodNotAllowed = errors.New("method not allowed")
+)
+
+// Функция problemFor сопоставляет ошибку с HTTP-статусом и описанием проблемы.
+// Неизвестные ошибки считаются внутренними: их текст не попадает в ответ.
+func problemFor(err error) Problem {
+	var reqErr *requestError
+	var fieldErrs validation.Errors
+	var notFound *db.NotFoundError
+	var scopeErr *scopeError
+	var roleErr *roleError
+	switch {
+	case errors.As(err, &reqErr):
+		return Problem{Type: problemBadRequest, Title: "Bad request", Status: http.StatusBadRequest, Detail: reqErr.message}
+	case errors.As(err, &fieldErrs):
+		return Problem{
+			Type: problemValidation, Title: "Validation failed", Status: http.StatusBadRequest,
+			Detail: fieldErrs.Error(), Errors: fieldErrs,
+		}
+	case errors.Is(err, db.ErrInvalidCursor):
+		return Problem{Type: problemBadRequest, Title: "Bad request", Status: http.StatusBadRequest, Detail: "Invalid cursor"}
+	case errors.Is(err, db.ErrInvalidQuery):
+		return Problem{Type: problemBadRequest, Title: "Bad request", Status: http.StatusBadRequest, Detail: err.Error()}
+	case errors.As(err, &notFound):
+		return Problem{
+			Type: problemNotFound, Title: "Task not found", Status: http.StatusNotFound,
+			Detail: fmt.Sprintf("Task %d does not exist", notFound.ID),
+		}
+	case errors.Is(err, db.ErrNotFound):
+		return Problem{Type: problemNotFound, Title: "Task not found", Status: http.StatusNotFound}
+	case errors.Is(err, errRouteNotFound):
+		return Problem{
+			Type: problemNotFound, Title: "Not found", Status: http.StatusNotFound,
+			Detail: "The path does not match any API route",
+		}
+	case errors.Is(err, errMethodNotAllowed):
+		return Problem{
+			Type: problemMethodNotAllowed, Title: "Method not allowed", Status: http.StatusMethodNotAllowed,
+			Detail: "The Allow header lists the methods supported by this route",
+		}
+	case errors.Is(err, db.ErrStatusNotFound):
+		return Problem{Type: problemNotFound, Title: "Status not found", Status: http.StatusNotFound}
+	case errors.Is(err, db.ErrStatusExists):
+		return Problem{Type: problemConflict, Title: "Status already exists", Status: http.StatusConflict}
+	case errors.Is(err, db.ErrStatusInUse):
+		return Problem{
+			Type: problemConflict, Title: "Status is in use", Status: http.StatusConflict,
+			Detail: "Move tasks to another status before deleting it",
+		}
+	case errors.Is(err, errUnauthenticated):
+		return Problem{
+			Type: problemUnauthorized, Title: "Authentication required", Status: http.StatusUnauthorized,
+			Detail: "Log in to access the API",
+		}
+	case errors.Is(err, errInvalidCredentials):
+		return Problem{Type: problemUnauthorized, Title: "Invalid username or password", Status: http.StatusUnauthorized}
+	case errors.As(err, &scopeErr):
+		return Problem{
+			Type: problemForbidden, Title: "Insufficient token scope", Status: http.StatusForbidden,
+			Detail: fmt.Sprintf("The token does not have the %q scope", scopeErr.scope),
+		}
+	case errors.Is(err, errSessionRequired):
+		return Problem{
+			Type: problemForbidden, Title: "Session required", Status: http.StatusForbidden,
+			Detail: "Log in to manage access tokens, list members or the workflow",
+		}
+	case errors.Is(err, errAdminRequired):
+		return Problem{
+			Type: problemForbidden, Title: "Administrator required", Status: http.StatusForbidden,
+			Detail: "Only server administrators can change the workflow",
+		}
+	case errors.As(err, &roleErr):
+		return Problem{
+			Type: problemForbidden, Title: "Insufficient role", Status: http.StatusForbidden,
+			Detail: fmt.Sprintf("This action requires the %q role in the task list", roleErr.required),
+		}
+	case errors.Is(err, db.ErrListNotFound):
+		return Problem{Type: problemNotFound, Title: "Task list not found", Status: http.StatusNotFound}
+	case errors.Is(err, db.ErrMemberNotFound):
+		return Problem{Type: problemNotFound, Title: "List member not found", Status: http.StatusNotFound}
+	case errors.Is(err, db.ErrUserNotFound):
+		return Problem{Type: problemNotFound, Title: "User not found", Status: http.StatusNotFound}
+	case errors.Is(err, errOwnerMember):
+		return Problem{
+			Type: problemConflict, Title: "List owner cannot be a member", Status: http.StatusConflict,
+			Detail: "The owner always has the admin role in their own task list",
+		}
+	case errors.Is(err, db.ErrProjectNotFound):
+		return Problem{Type: problemNotFound, Title: "Project not found", Status: http.StatusNotFound}
+	case errors.Is(err, db.ErrProjectExists):
+		return Problem{Type: problemConflict, Title: "Project already exists", Status: http.StatusConflict}
+	case errors.Is(err, db.ErrTokenNotFound):
+		return Problem{Type: problemNotFound, Title: "Token not found", Status: http.StatusNotFound}
+	case errors.Is(err, db.ErrUserExists):
+		return Problem{Type: problemConflict, Title: "User already exists", Status: http.StatusConflict}
+	case errors.Is(err, db.ErrVersionConflict):
+		return Problem{
+			Type: problemVersionConflict, Title: "Task has been modified by another request",
+			Status: http.StatusPreconditionFailed,
+			Detail: "Reload the task and retry the request with its current version",
+		}
+	case errors.Is(err, errInvalidIfMatch):
+		return Problem{
+			Type: problemPreconditionFailed, Title: "Precondition failed", Status: http.StatusPreconditionFailed,
+			Detail: "If-Match must be \"*\" or a strong ETag of the task",
+		}
+	}
+	return Problem{Type: problemInternal, Title: "Internal server error", Status: http.StatusInternalServerError}
+}
+
+// Функция writeError отправляет ошибку клиенту в формате application/problem+json.
+// Внутренние ошибки записываются в журнал вместе с методом и путем запроса.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	problem := problemFor(err)
+	if problem.Status == http.StatusInternalServerError {
+		log.Printf("Internal error on %s %s: %v", r.Method, r.URL.Path, err)
+	}
+	problem.Instance = r.URL.Path
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
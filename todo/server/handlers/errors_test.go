@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/Mr-Cheen1/todo_list/server/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для сопоставления ошибок с HTTP-статусами и типами проблем.
+func TestProblemFor(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    error
+		status int
+		typ    string
+	}{
+		{name: "Ошибка запроса", err: badRequest("Invalid task ID"), status: http.StatusBadRequest, typ: problemBadRequest},
+		{name: "Ошибка проверки", err: validation.Errors{{Field: "text"}}, status: http.StatusBadRequest,
+			typ: problemValidation},
+		{name: "Некорректный курсор", err: db.ErrInvalidCursor, status: http.StatusBadRequest, typ: problemBadRequest},
+		{name: "Некорректная выборка", err: fmt.Errorf("%w: invalid sort field: x", db.ErrInvalidQuery),
+			status: http.StatusBadRequest, typ: problemBadRequest},
+		{name: "Задача не найдена", err: fmt.Errorf("get: %w", db.ErrNotFound), status: http.StatusNotFound,
+			typ: problemNotFound},
+		{name: "Конфликт версий", err: db.ErrVersionConflict, status: http.StatusPreconditionFailed,
+			typ: problemVersionConflict},
+		{name: "Некорректный If-Match", err: errInvalidIfMatch, status: http.StatusPreconditionFailed,
+			typ: problemPreconditionFailed},
+		{name: "Нет разрешения токена", err: &scopeError{scope: db.ScopeTasksWrite}, status: http.StatusForbidden,
+			typ: problemForbidden},
+		{name: "Нужна сессия", err: errSessionRequired, status: http.StatusForbidden, typ: problemForbidden},
+		{name: "Недостаточно прав в списке", err: &roleError{required: db.RoleEditor}, status: http.StatusForbidden,
+			typ: problemForbidden},
+		{name: "Список не найден", err: db.ErrListNotFound, status: http.StatusNotFound, typ: problemNotFound},
+		{name: "Токен не найден", err: db.ErrTokenNotFound, status: http.StatusNotFound, typ: problemNotFound},
+		{name: "Проект не найден", err: db.ErrProjectNotFound, status: http.StatusNotFound, typ: problemNotFound},
+		{name: "Имя проекта занято", err: db.ErrProjectExists, status: http.StatusConflict, typ: problemConflict},
+		{name: "Внутренняя ошибка", err: errors.New("pq: connection refused"), status: http.StatusInternalServerError,
+			typ: problemInternal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			problem := problemFor(tc.err)
+			assert.Equal(t, tc.status, problem.Status)
+			assert.Equal(t, tc.typ, problem.Type)
+		})
+	}
+}
+
+// Тестовое хранилище, все операции которого завершаются ошибкой драйвера.
+type failingStore struct {
+	db.TaskStore
+}
+
+func (failingStore) GetTaskByID(int64, int) (db.Task, error) {
+	return db.Task{}, errors.New("pq: password authentication failed for user \"postgres\"")
+}
+
+// Тест для того, что внутренние ошибки не раскрываются клиенту.
+func TestWriteErrorHidesInternalDetails(t *testing.T) {
+	mux := http.NewServeMux()
+	NewTaskHandler(failingStore{}).Register(mux)
+
+	req, err := http.NewRequestWithContext(context.Background(), "GET", "/api/tasks/1", nil)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+	assert.NotContains(t, rr.Body.String(), "password")
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	assert.Equal(t, Problem{
+		Type: problemInternal, Title: "Internal server error", Status: http.StatusInternalServerError,
+		Instance: "/api/tasks/1",
+	}, problem)
+}
+
+// Тест для ответа на некорректный идентификатор задачи.
+func TestWriteErrorBadRequest(t *testing.T) {
+	mux := http.NewServeMux()
+	NewTaskHandler(db.NewMemoryStore()).Register(mux)
+
+	req, err := http.NewRequestWithContext(context.Background(), "DELETE", "/api/tasks/delete", nil)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "Missing task ID", problem.Detail)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Ошибка некорректного значения заголовка If-Match.
+var errInvalidIfMatch = errors.New("invalid If-Match header")
+
+// Функция setETag устанавливает заголовок ETag по версии задачи.
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// Функция ifMatchVersion возвращает версию задачи из заголовка If-Match.
+// Ноль означает, что заголовок не передан или равен "*", то есть версия не проверяется.
+// Слабые ETag (W/"...") не подходят для If-Match и считаются некорректными.
+func ifMatchVersion(r *http.Request) (int64, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+	unquoted, err := strconv.Unquote(value)
+	if err != nil || !strings.HasPrefix(value, `"`) {
+		return 0, errInvalidIfMatch
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version < 1 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/Mr-Cheen1/todo_list/server/validation"
+)
+
+// Ошибка добавления автора списка в участники собственного списка.
+var errOwnerMember = errors.New("list owner cannot be a member")
+
+// Структура roleError - ошибка запроса участника списка, роли которого недостаточно для действия.
+type roleError struct {
+	required db.Role
+}
+
+func (e *roleError) Error() string {
+	return "role " + string(e.required) + " required"
+}
+
+// Тип ключа контекста со списком задач, к которому обращается запрос.
+type listContextKey struct{}
+
+// Функция requireRole пропускает к next только запросы участников списка задач с ролью не ниже role
+// и передает через контекст запроса автора списка, задачи которого доступны обработчику (ownerID).
+// Список задается параметром пути {list} или параметром запроса list (ID автора списка);
+// без него запрос обращается к собственному списку пользователя.
+// Если список не открыт пользователю, ответ - 404 Not Found, если роли недостаточно - 403 Forbidden.
+func requireRole(store db.ListStore, role db.Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		owner, err := listOwner(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		// Автор - администратор своего списка, поэтому для собственного списка хранилище не запрашивается.
+		current := db.RoleAdmin
+		if owner != userID(r) {
+			current, err = store.GetListRole(owner, userID(r))
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+		}
+		if !current.Allows(role) {
+			writeError(w, r, &roleError{required: role})
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), listContextKey{}, owner)))
+	}
+}
+
+// Функция listOwner возвращает ID автора списка задач, к которому обращается запрос.
+func listOwner(r *http.Request) (int64, error) {
+	listStr := r.PathValue("list")
+	if listStr == "" {
+		listStr = r.URL.Query().Get("list")
+	}
+	if listStr == "" {
+		return userID(r), nil
+	}
+	owner, err := strconv.ParseInt(listStr, 10, 64)
+	if err != nil {
+		return 0, badRequest("Invalid list ID: %q", listStr)
+	}
+	return owner, nil
+}
+
+// Структура ListHandler содержит обработчики HTTP-запросов для общих списков задач и их участников.
+type ListHandler struct {
+	store db.TaskStore
+}
+
+// Функция NewListHandler создает обработчики, работающие с переданным хранилищем.
+func NewListHandler(store db.TaskStore) *ListHandler {
+	return &ListHandler{store: store}
+}
+
+// Метод Register регистрирует маршруты API общих списков в mux.
+// Участников списка видят все его участники, а изменяют только администраторы.
+func (h *ListHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/lists", h.GetLists)
+	mux.HandleFunc("GET /api/lists/{list}/members", requireRole(h.store, db.RoleViewer, h.GetMembers))
+	mux.HandleFunc("PUT /api/lists/{list}/members/{username}", requireRole(h.store, db.RoleAdmin, h.SetMember))
+	mux.HandleFunc("DELETE /api/lists/{list}/members/{username}", requireRole(h.store, db.RoleAdmin, h.DeleteMember))
+}
+
+// Обработчик для получения списков задач, доступных текущему пользователю, с его ролью в каждом.
+func (h *ListHandler) GetLists(w http.ResponseWriter, r *http.Request) {
+	lists, err := h.store.GetTaskLists(userID(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, lists)
+}
+
+// Обработчик для получения участников списка задач.
+func (h *ListHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	members, err := h.store.GetListMembers(ownerID(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	dtos := make([]db.ListMemberDTO, 0, len(members))
+	for _, member := range members {
+		dtos = append(dtos, member.ToDTO())
+	}
+	writeJSON(w, http.StatusOK, dtos)
+}
+
+// Обработчик для открытия списка задач пользователю или смены его роли.
+func (h *ListHandler) SetMember(w http.ResponseWriter, r *http.Request) {
+	var req db.ListMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, badRequest("Invalid member JSON: %v", err))
+		return
+	}
+	role, err := validation.NewListMember(req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	user, err := h.store.GetUserByName(validation.NormalizeUsername(r.PathValue("username")))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if user.ID == ownerID(r) {
+		writeError(w, r, errOwnerMember)
+		return
+	}
+	if err := h.store.SetListMember(ownerID(r), user.ID, role); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	member := db.ListMember{OwnerID: ownerID(r), UserID: user.ID, Username: user.Username, Role: role}
+	writeJSON(w, http.StatusOK, member.ToDTO())
+}
+
+// Обработчик для закрытия списка задач для пользователя.
+func (h *ListHandler) DeleteMember(w http.ResponseWriter, r *http.Request) {
+	user, err := h.store.GetUserByName(validation.NormalizeUsername(r.PathValue("username")))
+	if errors.Is(err, db.ErrUserNotFound) {
+		writeError(w, r, db.ErrMemberNotFound)
+		return
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := h.store.DeleteListMember(ownerID(r), user.ID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
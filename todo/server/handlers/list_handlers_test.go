@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mr-Cheen1/todo_list/server/auth"
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для ролей в общем списке задач: наблюдатель только читает задачи, редактор изменяет их,
+// а администратор управляет участниками списка.
+func TestListRoles(t *testing.T) {
+	store := db.NewMemoryStore()
+	users := map[string]int64{}
+	for _, name := range []string{"alice", "bob", "carol", "dave", "eve"} {
+		id, err := store.CreateUser(db.User{Username: name})
+		assert.NoError(t, err)
+		users[name] = id
+	}
+	alice := users["alice"]
+	assert.NoError(t, store.SetListMember(alice, users["bob"], db.RoleViewer))
+	assert.NoError(t, store.SetListMember(alice, users["carol"], db.RoleEditor))
+	assert.NoError(t, store.SetListMember(alice, users["dave"], db.RoleAdmin))
+	day := time.Date(2023, time.April, 4, 0, 0, 0, 0, time.UTC)
+	id, err := store.CreateTask(db.Task{OwnerID: alice, Text: "Shared", CreatedDate: day, ExpectedDate: day})
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	NewTaskHandler(store).Register(mux)
+	NewListHandler(store).Register(mux)
+
+	// serve выполняет запрос от имени пользователя name.
+	serve := func(name, method, path, body string) *httptest.ResponseRecorder {
+		ctx := auth.WithUser(context.Background(), db.User{ID: users[name], Username: name})
+		req, err := http.NewRequestWithContext(ctx, method, path, strings.NewReader(body))
+		assert.NoError(t, err)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+	list := fmt.Sprintf("list=%d", alice)
+	task := fmt.Sprintf("/api/tasks/%d?%s", id, list)
+	create := `{"text":"New","createdDate":"2023-04-04","expectedDate":"2023-04-04"}`
+
+	testCases := []struct {
+		name   string
+		user   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{name: "Наблюдатель читает задачи", user: "bob", method: "GET", path: "/api/tasks?" + list, status: http.StatusOK},
+		{name: "Наблюдатель читает задачу", user: "bob", method: "GET", path: task, status: http.StatusOK},
+		{name: "Наблюдатель читает историю", user: "bob", method: "GET",
+			path: fmt.Sprintf("/api/tasks/%d/history?%s", id, list), status: http.StatusOK},
+		{name: "Наблюдатель не создает задачи", user: "bob", method: "POST", path: "/api/tasks?" + list, body: create,
+			status: http.StatusForbidden},
+		{name: "Наблюдатель не изменяет задачи", user: "bob", method: "PATCH", path: task, body: `{"text":"Changed"}`,
+			status: http.StatusForbidden},
+		{name: "Наблюдатель не удаляет задачи", user: "bob", method: "DELETE",
+			path: fmt.Sprintf("/api/tasks/delete?id=%d&%s", id, list), status: http.StatusForbidden},
+		{name: "Наблюдатель не управляет участниками", user: "bob", method: "PUT", path: "/api/lists/1/members/eve",
+			body: `{"role":"viewer"}`, status: http.StatusForbidden},
+		{name: "Не участник не видит список", user: "eve", method: "GET", path: "/api/tasks?" + list,
+			status: http.StatusNotFound},
+		{name: "Некорректный ID списка", user: "bob", method: "GET", path: "/api/tasks?list=abc",
+			status: http.StatusBadRequest},
+		{name: "Редактор создает задачи", user: "carol", method: "POST", path: "/api/tasks?" + list, body: create,
+			status: http.StatusCreated},
+		{name: "Редактор изменяет задачи", user: "carol", method: "PATCH", path: task, body: `{"text":"Changed"}`,
+			status: http.StatusOK},
+		{name: "Редактор не управляет участниками", user: "carol", method: "DELETE", path: "/api/lists/1/members/bob",
+			status: http.StatusForbidden},
+		{name: "Участники видят участников", user: "bob", method: "GET", path: "/api/lists/1/members", status: http.StatusOK},
+		{name: "Администратор открывает список", user: "dave", method: "PUT", path: "/api/lists/1/members/Eve",
+			body: `{"role":"viewer"}`, status: http.StatusOK},
+		{name: "Неизвестная роль", user: "dave", method: "PUT", path: "/api/lists/1/members/eve", body: `{"role":"owner"}`,
+			status: http.StatusBadRequest},
+		{name: "Неизвестный пользователь", user: "dave", method: "PUT", path: "/api/lists/1/members/frank",
+			body: `{"role":"viewer"}`, status: http.StatusNotFound},
+		{name: "Автор не становится участником", user: "dave", method: "PUT", path: "/api/lists/1/members/alice",
+			body: `{"role":"viewer"}`, status: http.StatusConflict},
+		{name: "Новый участник видит список", user: "eve", method: "GET", path: "/api/tasks?" + list, status: http.StatusOK},
+		{name: "Администратор закрывает список", user: "dave", method: "DELETE", path: "/api/lists/1/members/eve",
+			status: http.StatusOK},
+		{name: "Закрытие без участника", user: "dave", method: "DELETE", path: "/api/lists/1/members/eve",
+			status: http.StatusNotFound},
+		{name: "Редактор удаляет задачи", user: "carol", method: "DELETE", path: task, status: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := serve(tc.user, tc.method, tc.path, tc.body)
+			assert.Equal(t, tc.status, rr.Code, rr.Body.String())
+			if tc.status == http.StatusForbidden {
+				assert.Contains(t, rr.Body.String(), `"type":"/problems/forbidden"`)
+			}
+		})
+	}
+
+	// Задачи, созданные редактором, принадлежат списку автора.
+	page, err := store.GetAllTasks(db.TaskQuery{Owner: alice})
+	assert.NoError(t, err)
+	if assert.Len(t, page.Tasks, 1) {
+		assert.Equal(t, "New", page.Tasks[0].Text)
+	}
+
+	var lists []db.TaskList
+	assert.NoError(t, json.Unmarshal(serve("bob", "GET", "/api/lists", "").Body.Bytes(), &lists))
+	assert.Equal(t, []db.TaskList{
+		{OwnerID: users["bob"], OwnerName: "bob", Role: db.RoleAdmin},
+		{OwnerID: alice, OwnerName: "alice", Role: db.RoleViewer},
+	}, lists)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/Mr-Cheen1/todo_list/server/validation"
+)
+
+// Структура ProjectHandler содержит обработчики HTTP-запросов для проектов списка задач.
+type ProjectHandler struct {
+	store db.TaskStore
+}
+
+// Функция NewProjectHandler создает обработчики, работающие с переданным хранилищем.
+func NewProjectHandler(store db.TaskStore) *ProjectHandler {
+	return &ProjectHandler{store: store}
+}
+
+// Метод Register регистрирует маршруты API проектов в mux.
+// Проекты принадлежат списку задач, поэтому доступ к ним определяется ролью в списке, как и для задач:
+// чтение - viewer, изменение - editor.
+func (h *ProjectHandler) Register(mux *http.ServeMux) {
+	viewer := func(next http.HandlerFunc) http.HandlerFunc { return requireRole(h.store, db.RoleViewer, next) }
+	editor := func(next http.HandlerFunc) http.HandlerFunc { return requireRole(h.store, db.RoleEditor, next) }
+
+	mux.HandleFunc("GET /api/projects", viewer(h.GetProjects))
+	mux.HandleFunc("POST /api/projects", editor(h.CreateProject))
+	mux.HandleFunc("GET /api/projects/{id}", viewer(h.GetProject))
+	mux.HandleFunc("PUT /api/projects/{id}", editor(h.UpdateProject))
+	mux.HandleFunc("DELETE /api/projects/{id}", editor(h.DeleteProject))
+}
+
+// Обработчик для получения проектов списка задач в порядке имен.
+func (h *ProjectHandler) GetProjects(w http.ResponseWriter, r *http.Request) {
+	projects, err := h.store.GetProjects(ownerID(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	dtos := make([]db.ProjectDTO, 0, len(projects))
+	for _, project := range projects {
+		dtos = append(dtos, project.ToDTO())
+	}
+	writeJSON(w, http.StatusOK, dtos)
+}
+
+// Обработчик для получения проекта по идентификатору.
+func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
+	id, err := projectID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	project, err := h.store.GetProject(ownerID(r), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, project.ToDTO())
+}
+
+// Обработчик для создания проекта. Имя проекта уникально в списке задач.
+func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	var req db.ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, badRequest("Invalid project JSON: %v", err))
+		return
+	}
+	project, err := validation.NewProject(req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	project.OwnerID = ownerID(r)
+	project.ID, err = h.store.CreateProject(project)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/projects/%d", project.ID))
+	writeJSON(w, http.StatusCreated, project.ToDTO())
+}
+
+// Обработчик для переименования проекта.
+func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	id, err := projectID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var req db.ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, badRequest("Invalid project JSON: %v", err))
+		return
+	}
+	project, err := validation.NewProject(req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	project.ID = id
+	project.OwnerID = ownerID(r)
+	if err := h.store.UpdateProject(project); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, project.ToDTO())
+}
+
+// Обработчик для удаления проекта. Задачи проекта не удаляются, а остаются в списке без проекта.
+func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	id, err := projectID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := h.store.DeleteProject(ownerID(r), userID(r), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Функция projectID извлекает идентификатор проекта из пути (/api/projects/{id}).
+func projectID(r *http.Request) (int64, error) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return 0, badRequest("Invalid project ID: %q", idStr)
+	}
+	return id, nil
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для проектов: создание и переименование с уникальным именем, задачи в проекте,
+// фильтр задач по проекту и удаление проекта, после которого задачи остаются без проекта.
+func TestProjects(t *testing.T) {
+	f := newListFixture(t, func(store db.TaskStore, mux *http.ServeMux) {
+		NewProjectHandler(store).Register(mux)
+	})
+	alice, asAlice := f.alice.ID, f.asAlice
+
+	rr := asAlice("POST", "/api/projects", `{"name":" Работа "}`)
+	assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var project db.ProjectDTO
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &project))
+	assert.Equal(t, "Работа", project.Name)
+	assert.Equal(t, fmt.Sprintf("/api/projects/%d", project.ID), rr.Header().Get("Location"))
+	projectPath := rr.Header().Get("Location")
+	taskInProject := fmt.Sprintf(`{"text":"Отчет","createdDate":"2023-10-01","expectedDate":"2023-10-01","projectId":%d}`,
+		project.ID)
+
+	testCases := []struct {
+		name   string
+		user   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{name: "Имя занято", method: "POST", path: "/api/projects", body: `{"name":"Работа"}`, status: http.StatusConflict},
+		{name: "Пустое имя", method: "POST", path: "/api/projects", body: `{"name":""}`, status: http.StatusBadRequest},
+		{name: "Переименование", method: "PUT", path: projectPath, body: `{"name":"Офис"}`, status: http.StatusOK},
+		{name: "Нет проекта", method: "GET", path: "/api/projects/42", status: http.StatusNotFound},
+		{name: "Некорректный ID проекта", method: "DELETE", path: "/api/projects/abc", status: http.StatusBadRequest},
+		{name: "Задача в несуществующем проекте", method: "POST", path: "/api/tasks", status: http.StatusBadRequest,
+			body: `{"text":"Task","createdDate":"2023-10-01","expectedDate":"2023-10-01","projectId":42}`},
+		{name: "Задача в проекте", method: "POST", path: "/api/tasks", body: taskInProject, status: http.StatusCreated},
+		{name: "Задача без проекта", method: "POST", path: "/api/tasks",
+			body: `{"text":"Без проекта","createdDate":"2023-10-01","expectedDate":"2023-10-01"}`, status: http.StatusCreated},
+		{name: "Некорректный фильтр по проекту", method: "GET", path: "/api/tasks?project=abc",
+			status: http.StatusBadRequest},
+		{name: "Наблюдатель видит проекты", user: "bob", method: "GET",
+			path: fmt.Sprintf("/api/projects?list=%d", alice), status: http.StatusOK},
+		{name: "Наблюдатель не создает проекты", user: "bob", method: "POST",
+			path: fmt.Sprintf("/api/projects?list=%d", alice), body: `{"name":"Чужой"}`, status: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := f.serve(f.user(tc.user), tc.method, tc.path, tc.body)
+			assert.Equal(t, tc.status, rr.Code, rr.Body.String())
+		})
+	}
+
+	// tasks возвращает тексты задач, отобранных фильтром по проекту.
+	tasks := func(filter string) []string {
+		var dtos []db.TaskDTO
+		assert.NoError(t, json.Unmarshal(asAlice("GET", "/api/tasks?project="+filter, "").Body.Bytes(), &dtos))
+		texts := []string{}
+		for _, dto := range dtos {
+			texts = append(texts, dto.Text)
+		}
+		return texts
+	}
+	assert.Equal(t, []string{"Отчет"}, tasks(fmt.Sprint(project.ID)))
+	assert.Equal(t, []string{"Без проекта"}, tasks(db.NoProject))
+
+	// PATCH с null убирает задачу из проекта.
+	rr = asAlice("PATCH", "/api/tasks/1", `{"projectId":null}`)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), `"projectId":null`)
+	rr = asAlice("PATCH", "/api/tasks/1", fmt.Sprintf(`{"projectId":%d}`, project.ID))
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	assert.Equal(t, http.StatusOK, asAlice("DELETE", projectPath, "").Code)
+	assert.Equal(t, http.StatusNotFound, asAlice("DELETE", projectPath, "").Code)
+	assert.Equal(t, []string{"Отчет", "Без проекта"}, tasks(db.NoProject))
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Mr-Cheen1/todo_list/server/auth"
+	"github.com/Mr-Cheen1/todo_list/server/db"
+)
+
+// Метод Register регистрирует маршруты API задач в mux.
+// Неподходящий HTTP-метод для известного пути ServeMux отклоняет с кодом 405 и заголовком Allow
+// (в формате application/problem+json, если mux обернут в Problems).
+// Каждый маршрут требует роли в списке задач (см. requireRole): чтение - viewer, изменение - editor.
+func (h *TaskHandler) Register(mux *http.ServeMux) {
+	viewer := func(next http.HandlerFunc) http.HandlerFunc { return requireRole(h.store, db.RoleViewer, next) }
+	editor := func(next http.HandlerFunc) http.HandlerFunc { return requireRole(h.store, db.RoleEditor, next) }
+
+	mux.HandleFunc("GET /api/tasks", viewer(h.GetTasks))
+	mux.HandleFunc("POST /api/tasks", editor(h.CreateTask))
+	mux.HandleFunc("GET /api/tasks/{id}", viewer(h.GetTask))
+	mux.HandleFunc("GET /api/tasks/{id}/history", viewer(h.GetTaskHistory))
+	mux.HandleFunc("PUT /api/tasks/{id}", editor(h.UpdateTask))
+	mux.HandleFunc("PATCH /api/tasks/{id}", editor(h.PatchTask))
+	mux.HandleFunc("DELETE /api/tasks/{id}", editor(h.DeleteTask))
+	mux.HandleFunc("POST /api/tasks/{id}/restore", editor(h.RestoreTask))
+	mux.HandleFunc("GET /api/trash", viewer(h.GetTrash))
+
+	// Устаревшие маршруты с действием в пути оставлены для совместимости со старыми клиентами.
+	mux.HandleFunc("POST /api/tasks/create", deprecated("/api/tasks", editor(h.CreateTask)))
+	mux.HandleFunc("PUT /api/tasks/update", deprecated("/api/tasks/{id}", editor(h.UpdateTask)))
+	mux.HandleFunc("DELETE /api/tasks/delete", deprecated("/api/tasks/{id}", editor(h.DeleteTask)))
+}
+
+// Функция Problems оборачивает mux так, что его собственные ответы на неизвестный путь (404)
+// и неподходящий метод (405 с заголовком Allow) отправляются в формате application/problem+json,
+// как и ошибки обработчиков.
+func Problems(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Ответ с ошибкой формирует сам ServeMux, когда запросу не соответствует ни один шаблон.
+		if _, pattern := mux.Handler(r); pattern == "" {
+			w = &routeErrorWriter{ResponseWriter: w, r: r}
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// Структура routeErrorWriter заменяет текстовый ответ ServeMux с кодом 404 или 405 описанием проблемы.
+// Заголовок Allow, который ServeMux устанавливает до WriteHeader, сохраняется.
+type routeErrorWriter struct {
+	http.ResponseWriter
+	r        *http.Request
+	replaced bool
+}
+
+// Метод WriteHeader отправляет описание проблемы вместо ответа ServeMux.
+func (w *routeErrorWriter) WriteHeader(status int) {
+	var err error
+	switch status {
+	case http.StatusNotFound:
+		err = errRouteNotFound
+	case http.StatusMethodNotAllowed:
+		err = errMethodNotAllowed
+	default:
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.replaced = true
+	writeError(w.ResponseWriter, w.r, err)
+}
+
+// Метод Write отбрасывает текст ошибки ServeMux, если вместо него отправлено описание проблемы.
+func (w *routeErrorWriter) Write(b []byte) (int, error) {
+	if w.replaced {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Функция deprecated помечает ответы устаревшего маршрута заголовками Deprecation и Link
+// с указанием маршрута, который следует использовать вместо него.
+func deprecated(successor string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("Deprecated route used: %s %s", r.Method, r.URL.Path)
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		next(w, r)
+	}
+}
+
+// Функция taskID извлекает идентификатор задачи из пути (/api/tasks/{id})
+// или, для устаревших маршрутов, из параметра запроса id.
+func taskID(r *http.Request) (int, error) {
+	idStr := r.PathValue("id")
+	if idStr == "" {
+		idStr = r.URL.Query().Get("id")
+	}
+	if idStr == "" {
+		return 0, badRequest("Missing task ID")
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, badRequest("Invalid task ID: %q", idStr)
+	}
+	return id, nil
+}
+
+// Функция ownerID возвращает ID автора списка, задачи которого доступны запросу: списка,
+// выбранного в requireRole, или собственного списка текущего пользователя.
+// Запросу без пользователя (вне RequireUser) не принадлежит ни одна задача.
+func ownerID(r *http.Request) int64 {
+	if owner, ok := r.Context().Value(listContextKey{}).(int64); ok {
+		return owner
+	}
+	return userID(r)
+}
+
+// Функция userID возвращает ID текущего пользователя или 0 для запроса без пользователя.
+func userID(r *http.Request) int64 {
+	user, _ := auth.UserFrom(r.Context())
+	return user.ID
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Mr-Cheen1/todo_list/server/auth"
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/Mr-Cheen1/todo_list/server/validation"
+)
+
+// Ошибка изменения рабочего процесса пользователем, который не является администратором сервера.
+var errAdminRequired = errors.New("server administrator required")
+
+// Структура StatusHandler содержит обработчики HTTP-запросов для настройки статусов рабочих процессов.
+type StatusHandler struct {
+	store db.TaskStore
+	// admins - идентификаторы пользователей, которым разрешено изменять рабочий процесс.
+	admins []int64
+}
+
+// Функция NewStatusHandler создает обработчики, работающие с переданным хранилищем.
+// Изменять рабочий процесс по умолчанию могут только администраторы сервера admins
+// (по идентификаторам пользователей, а не по именам, которые при открытой регистрации может занять любой).
+func NewStatusHandler(store db.TaskStore, admins []int64) *StatusHandler {
+	return &StatusHandler{store: store, admins: admins}
+}
+
+// Метод Register регистрирует маршруты API статусов в mux. Рабочий процесс по умолчанию
+// (/api/statuses) действует для задач без проекта во всех списках задач, поэтому читать его может
+// любой пользователь, а изменять - только администратор сервера. Рабочий процесс проекта
+// (/api/projects/{id}/statuses) принадлежит списку задач: читать его может viewer списка,
+// а изменять - admin списка. Пока статусы проекта не изменялись, для него действует рабочий процесс
+// по умолчанию, а первое изменение создает собственный рабочий процесс проекта (см. db.StatusStore).
+func (h *StatusHandler) Register(mux *http.ServeMux) {
+	viewer := func(next http.HandlerFunc) http.HandlerFunc { return requireRole(h.store, db.RoleViewer, next) }
+	admin := func(next http.HandlerFunc) http.HandlerFunc { return requireRole(h.store, db.RoleAdmin, next) }
+
+	mux.HandleFunc("GET /api/statuses", h.GetStatuses)
+	mux.HandleFunc("POST /api/statuses", h.requireAdmin(h.CreateStatus))
+	mux.HandleFunc("PUT /api/statuses/{name}", h.requireAdmin(h.UpdateStatus))
+	mux.HandleFunc("DELETE /api/statuses/{name}", h.requireAdmin(h.DeleteStatus))
+
+	mux.HandleFunc("GET /api/projects/{id}/statuses", viewer(h.GetStatuses))
+	mux.HandleFunc("POST /api/projects/{id}/statuses", admin(h.CreateStatus))
+	mux.HandleFunc("PUT /api/projects/{id}/statuses/{name}", admin(h.UpdateStatus))
+	mux.HandleFunc("DELETE /api/projects/{id}/statuses/{name}", admin(h.DeleteStatus))
+}
+
+// Метод requireAdmin пропускает к next только запросы администраторов сервера,
+// остальные получают 403 Forbidden.
+func (h *StatusHandler) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := auth.UserFrom(r.Context())
+		if !ok || !slices.Contains(h.admins, user.ID) {
+			writeError(w, r, errAdminRequired)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// Обработчик для получения статусов рабочего процесса в порядке их следования.
+// Для проекта без собственного рабочего процесса возвращается рабочий процесс по умолчанию.
+func (h *StatusHandler) GetStatuses(w http.ResponseWriter, r *http.Request) {
+	project, err := h.project(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	workflow, err := h.store.GetWorkflow(project)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	statuses := workflow.Statuses
+	if statuses == nil {
+		statuses = []db.StatusDef{}
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+// Обработчик для создания статуса. Переходы в новый статус задаются изменением других статусов.
+func (h *StatusHandler) CreateStatus(w http.ResponseWriter, r *http.Request) {
+	var def db.StatusDef
+	if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+		writeError(w, r, badRequest("Invalid status JSON: %v", err))
+		return
+	}
+
+	project, err := h.project(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	workflow, err := h.store.GetWorkflow(project)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	def, err = validation.NewStatus(workflow, def)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	def.ID, err = h.store.CreateStatus(project, def)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+def.Name)
+	writeJSON(w, http.StatusCreated, def)
+}
+
+// Обработчик для изменения статуса: названия, порядка, цвета, признака завершения и переходов.
+// Имя статуса неизменно, потому что по нему статус передается в API задач.
+func (h *StatusHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	var def db.StatusDef
+	if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+		writeError(w, r, badRequest("Invalid status JSON: %v", err))
+		return
+	}
+	if def.Name != "" && def.Name != name {
+		writeError(w, r, badRequest("Status name cannot be changed"))
+		return
+	}
+	def.Name = name
+
+	project, err := h.project(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	workflow, err := h.store.GetWorkflow(project)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	current, ok := workflow.Lookup(name)
+	if !ok || current.Name != name {
+		writeError(w, r, db.ErrStatusNotFound)
+		return
+	}
+
+	def, err = validation.UpdatedStatus(workflow, def)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.store.UpdateStatus(project, def); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Первое изменение статуса проекта копирует рабочий процесс по умолчанию с новыми номерами статусов.
+	if project != 0 {
+		workflow, err = h.store.GetWorkflow(project)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		current, _ = workflow.Lookup(name)
+	}
+	def.ID = current.ID
+	writeJSON(w, http.StatusOK, def)
+}
+
+// Обработчик для удаления статуса. Статус, в котором есть задачи, не удаляется (409 Conflict).
+func (h *StatusHandler) DeleteStatus(w http.ResponseWriter, r *http.Request) {
+	project, err := h.project(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.store.DeleteStatus(project, r.PathValue("name")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Метод project возвращает проект, к рабочему процессу которого обращается запрос
+// (/api/projects/{id}/statuses), или 0 для рабочего процесса по умолчанию (/api/statuses).
+// Проект должен быть в списке задач, выбранном в requireRole.
+func (h *StatusHandler) project(r *http.Request) (int64, error) {
+	if r.PathValue("id") == "" {
+		return 0, nil
+	}
+	id, err := projectID(r)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := h.store.GetProject(ownerID(r), id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
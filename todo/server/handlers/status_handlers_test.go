@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mr-Cheen1/todo_list/server/auth"
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для API статусов: новый статус сразу участвует в проверке переходов задач.
+func TestStatusHandlers(t *testing.T) {
+	store := db.NewMemoryStore()
+	day := time.Date(2023, time.April, 4, 0, 0, 0, 0, time.UTC)
+	id, err := store.CreateTask(db.Task{OwnerID: testUserID, Text: "Workflow", CreatedDate: day, ExpectedDate: day,
+		Status: db.StatusInProgress})
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	NewTaskHandler(store).Register(mux)
+	NewStatusHandler(store, []int64{testUserID}).Register(mux)
+	taskPath := fmt.Sprintf("/api/tasks/%d", id)
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   int
+	}{
+		{name: "Переход в неизвестный статус", method: "PATCH", path: taskPath, body: `{"status":"blocked"}`,
+			code: http.StatusBadRequest},
+		{name: "Создание статуса", method: "POST", path: "/api/statuses",
+			body: `{"name":"blocked","title":"Заблокировано","position":15,"color":"#f44336","transitions":["in_progress"]}`,
+			code: http.StatusCreated},
+		{name: "Повторное создание", method: "POST", path: "/api/statuses",
+			body: `{"name":"blocked","title":"Заблокировано","position":15,"color":"#f44336"}`, code: http.StatusConflict},
+		{name: "Некорректный статус", method: "POST", path: "/api/statuses", body: `{"name":"In review","color":"red"}`,
+			code: http.StatusBadRequest},
+		{name: "Переход еще не разрешен", method: "PATCH", path: taskPath, body: `{"status":"blocked"}`,
+			code: http.StatusBadRequest},
+		{name: "Разрешение перехода", method: "PUT", path: "/api/statuses/in_progress",
+			body: `{"title":"В работе","position":10,"color":"#2196f3","transitions":["testing","blocked"]}`,
+			code: http.StatusOK},
+		{name: "Смена имени", method: "PUT", path: "/api/statuses/in_progress",
+			body: `{"name":"doing","title":"В работе","color":"#2196f3"}`, code: http.StatusBadRequest},
+		{name: "Неизвестный статус", method: "PUT", path: "/api/statuses/missing", body: `{"title":"Нет","color":"#000000"}`,
+			code: http.StatusNotFound},
+		{name: "Переход в новый статус", method: "PATCH", path: taskPath, body: `{"status":"blocked"}`, code: http.StatusOK},
+		{name: "Фильтр по новому статусу", method: "GET", path: "/api/tasks?status=blocked", code: http.StatusOK},
+		{name: "Фильтр по неизвестному статусу", method: "GET", path: "/api/tasks?status=done", code: http.StatusBadRequest},
+		{name: "Удаление статуса с задачами", method: "DELETE", path: "/api/statuses/blocked", code: http.StatusConflict},
+		{name: "Возврат задачи в работу", method: "PATCH", path: taskPath, body: `{"status":"in_progress"}`,
+			code: http.StatusOK},
+		{name: "Удаление статуса", method: "DELETE", path: "/api/statuses/blocked", code: http.StatusOK},
+		{name: "Удаление удаленного статуса", method: "DELETE", path: "/api/statuses/blocked", code: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(userCtx, tc.method, tc.path, strings.NewReader(tc.body))
+			assert.NoError(t, err)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.code, rr.Code, rr.Body.String())
+		})
+	}
+
+	req, err := http.NewRequestWithContext(userCtx, "GET", "/api/statuses", nil)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	var statuses []db.StatusDef
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &statuses))
+	assert.Len(t, statuses, 4)
+	assert.Equal(t, db.StatusDef{
+		ID: db.StatusInProgress, Name: "in_progress", Title: "В работе", Position: 10, Color: "#2196f3",
+		Transitions: []string{"testing"},
+	}, statuses[0])
+}
+
+// Тест для защиты рабочего процесса: статусы читают все пользователи, а изменяют только
+// администраторы сервера, даже если у пользователя есть доступ к чужому списку задач.
+func TestStatusHandlersAdminOnly(t *testing.T) {
+	store := db.NewMemoryStore()
+	alice, err := store.CreateUser(db.User{Username: "alice"})
+	assert.NoError(t, err)
+	bob, err := store.CreateUser(db.User{Username: "bob"})
+	assert.NoError(t, err)
+	assert.NoError(t, store.SetListMember(alice, bob, db.RoleAdmin))
+
+	mux := http.NewServeMux()
+	NewStatusHandler(store, []int64{alice}).Register(mux)
+	bobCtx := auth.WithUser(context.Background(), db.User{ID: bob, Username: "bob"})
+
+	for _, tc := range []struct{ method, path, body string }{
+		{method: "POST", path: "/api/statuses", body: `{"name":"blocked","title":"Заблокировано","color":"#f44336"}`},
+		{method: "PUT", path: "/api/statuses/testing", body: `{"title":"Тестирование","color":"#ff9800","transitions":[]}`},
+		{method: "DELETE", path: "/api/statuses/returned"},
+	} {
+		req, err := http.NewRequestWithContext(bobCtx, tc.method, tc.path, strings.NewReader(tc.body))
+		assert.NoError(t, err)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code, tc.method+" "+tc.path)
+		assert.Contains(t, rr.Body.String(), "/problems/forbidden")
+	}
+
+	req, err := http.NewRequestWithContext(bobCtx, "GET", "/api/statuses", nil)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	workflow, err := store.GetWorkflow(0)
+	assert.NoError(t, err)
+	assert.Len(t, workflow.Statuses, 4)
+	status, _ := workflow.Lookup("testing")
+	assert.Equal(t, []string{"returned", "completed"}, status.Transitions)
+}
+
+// Тест для рабочего процесса проекта: его изменяет администратор списка задач, а не сервера,
+// задачи проекта переходят по его статусам, а рабочий процесс по умолчанию не меняется.
+func TestProjectStatusHandlers(t *testing.T) {
+	store := db.NewMemoryStore()
+	alice, err := store.CreateUser(db.User{Username: "alice"})
+	assert.NoError(t, err)
+	bob, err := store.CreateUser(db.User{Username: "bob"})
+	assert.NoError(t, err)
+	assert.NoError(t, store.SetListMember(alice, bob, db.RoleEditor))
+	project, err := store.CreateProject(db.Project{OwnerID: alice, Name: "Release"})
+	assert.NoError(t, err)
+	day := time.Date(2023, time.April, 4, 0, 0, 0, 0, time.UTC)
+	id, err := store.CreateTask(db.Task{OwnerID: alice, Text: "Workflow", CreatedDate: day, ExpectedDate: day,
+		Status: db.StatusInProgress, ProjectID: project})
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	NewTaskHandler(store).Register(mux)
+	NewStatusHandler(store, nil).Register(mux)
+	aliceCtx := auth.WithUser(context.Background(), db.User{ID: alice, Username: "alice"})
+	bobCtx := auth.WithUser(context.Background(), db.User{ID: bob, Username: "bob"})
+	statusesPath := fmt.Sprintf("/api/projects/%d/statuses", project)
+	taskPath := fmt.Sprintf("/api/tasks/%d", id)
+	bobPath := func(path string) string { return fmt.Sprintf("%s?list=%d", path, alice) }
+
+	testCases := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		path   string
+		body   string
+		code   int
+	}{
+		{name: "Изменение рабочего процесса по умолчанию", ctx: aliceCtx, method: "POST", path: "/api/statuses",
+			body: `{"name":"blocked","title":"Заблокировано","color":"#f44336"}`, code: http.StatusForbidden},
+		{name: "Чтение редактором", ctx: bobCtx, method: "GET", path: bobPath(statusesPath), code: http.StatusOK},
+		{name: "Изменение редактором", ctx: bobCtx, method: "POST", path: bobPath(statusesPath),
+			body: `{"name":"blocked","title":"Заблокировано","color":"#f44336"}`, code: http.StatusForbidden},
+		{name: "Неизвестный проект", ctx: aliceCtx, method: "GET", path: "/api/projects/99/statuses",
+			code: http.StatusNotFound},
+		{name: "Создание статуса проекта", ctx: aliceCtx, method: "POST", path: statusesPath,
+			body: `{"name":"blocked","title":"Заблокировано","position":15,"color":"#f44336"}`, code: http.StatusCreated},
+		{name: "Разрешение перехода", ctx: aliceCtx, method: "PUT", path: statusesPath + "/in_progress",
+			body: `{"title":"В работе","position":10,"color":"#2196f3","transitions":["blocked"]}`, code: http.StatusOK},
+		{name: "Переход в статус проекта", ctx: aliceCtx, method: "PATCH", path: taskPath, body: `{"status":"blocked"}`,
+			code: http.StatusOK},
+		{name: "Фильтр по статусу проекта", ctx: aliceCtx, method: "GET",
+			path: fmt.Sprintf("/api/tasks?project=%d&status=blocked", project), code: http.StatusOK},
+		{name: "Фильтр задач без проекта", ctx: aliceCtx, method: "GET", path: "/api/tasks?project=none&status=blocked",
+			code: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(tc.ctx, tc.method, tc.path, strings.NewReader(tc.body))
+			assert.NoError(t, err)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.code, rr.Code, rr.Body.String())
+		})
+	}
+
+	workflow, err := store.GetWorkflow(0)
+	assert.NoError(t, err)
+	assert.Len(t, workflow.Statuses, 4)
+
+	workflow, err = store.GetWorkflow(project)
+	assert.NoError(t, err)
+	assert.Len(t, workflow.Statuses, 5)
+	task, err := store.GetTaskByID(alice, int(id))
+	assert.NoError(t, err)
+	blocked, _ := workflow.Lookup("blocked")
+	assert.Equal(t, blocked.ID, task.Status)
+}
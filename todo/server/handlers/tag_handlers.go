@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/Mr-Cheen1/todo_list/server/validation"
+)
+
+// Количество подсказок меток по умолчанию и наибольшее допустимое значение параметра limit.
+const (
+	defaultTagLimit = 10
+	maxTagLimit     = 100
+)
+
+// Структура TagHandler содержит обработчики HTTP-запросов для меток задач.
+type TagHandler struct {
+	store db.TaskStore
+}
+
+// Функция NewTagHandler создает обработчики, работающие с переданным хранилищем.
+func NewTagHandler(store db.TaskStore) *TagHandler {
+	return &TagHandler{store: store}
+}
+
+// Метод Register регистрирует маршруты API меток в mux. Метки назначаются через поле tags задачи,
+// поэтому отдельно доступны только подсказки, для чтения которых достаточно роли viewer.
+func (h *TagHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/tags", requireRole(h.store, db.RoleViewer, h.GetTags))
+}
+
+// Обработчик для автодополнения меток: возвращает метки списка задач, начинающиеся с параметра prefix,
+// начиная с самых используемых. Параметр limit ограничивает количество меток (по умолчанию 10).
+func (h *TagHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	limit := defaultTagLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxTagLimit {
+			writeError(w, r, badRequest("Limit must be between 1 and %d", maxTagLimit))
+			return
+		}
+	}
+
+	tags, err := h.store.GetTags(ownerID(r), validation.NormalizeTag(r.URL.Query().Get("prefix")), limit)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tags)
+}
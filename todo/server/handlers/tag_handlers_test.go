@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для меток задач: назначение меток при создании и изменении задачи, фильтр списка задач
+// по меткам и подсказки меток для автодополнения.
+func TestTags(t *testing.T) {
+	f := newListFixture(t, func(store db.TaskStore, mux *http.ServeMux) {
+		NewTagHandler(store).Register(mux)
+	})
+	alice, asAlice := f.alice.ID, f.asAlice
+
+	rr := asAlice("POST", "/api/tasks",
+		`{"text":"Кнопка","createdDate":"2023-10-01","expectedDate":"2023-10-01","tags":["UI"," bug","ui"]}`)
+	assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), `"tags":["bug","ui"]`)
+	rr = asAlice("POST", "/api/tasks",
+		`{"text":"Падение","createdDate":"2023-10-01","expectedDate":"2023-10-01","tags":["bug"]}`)
+	assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	testCases := []struct {
+		name   string
+		user   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{name: "Метка с запятой", method: "POST", path: "/api/tasks", status: http.StatusBadRequest,
+			body: `{"text":"Task","createdDate":"2023-10-01","expectedDate":"2023-10-01","tags":["a,b"]}`},
+		{name: "Пустая метка в фильтре", method: "GET", path: "/api/tasks?tags=bug,", status: http.StatusBadRequest},
+		{name: "Неизвестный режим фильтра", method: "GET", path: "/api/tasks?tags=bug&tagMatch=some",
+			status: http.StatusBadRequest},
+		{name: "Некорректный limit подсказок", method: "GET", path: "/api/tags?limit=0", status: http.StatusBadRequest},
+		{name: "Наблюдатель видит подсказки", user: "bob", method: "GET",
+			path: fmt.Sprintf("/api/tags?list=%d", alice), status: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := f.serve(f.user(tc.user), tc.method, tc.path, tc.body)
+			assert.Equal(t, tc.status, rr.Code, rr.Body.String())
+		})
+	}
+
+	// tasks возвращает тексты задач, отобранных фильтром по меткам.
+	tasks := func(params string) []string {
+		var dtos []db.TaskDTO
+		assert.NoError(t, json.Unmarshal(asAlice("GET", "/api/tasks?"+params, "").Body.Bytes(), &dtos))
+		texts := []string{}
+		for _, dto := range dtos {
+			texts = append(texts, dto.Text)
+		}
+		return texts
+	}
+	assert.Equal(t, []string{"Кнопка", "Падение"}, tasks("tags=UI,bug"))
+	assert.Equal(t, []string{"Кнопка"}, tasks("tags=ui,bug&tagMatch=all"))
+	assert.Equal(t, []string{"Падение", "Кнопка"}, tasks("tags=bug&sortField=task_text&sort=desc"))
+
+	// PUT без поля tags оставляет метки задачи, а PATCH заменяет их.
+	rr = asAlice("PUT", "/api/tasks/1",
+		`{"text":"Кнопка входа","createdDate":"2023-10-01","expectedDate":"2023-10-01","status":"in_progress"}`)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), `"tags":["bug","ui"]`)
+	rr = asAlice("PATCH", "/api/tasks/1", `{"tags":["ui"]}`)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), `"tags":["ui"]`)
+
+	var tags []db.Tag
+	assert.NoError(t, json.Unmarshal(asAlice("GET", "/api/tags?prefix=U", "").Body.Bytes(), &tags))
+	assert.Equal(t, []db.Tag{{Name: "ui", Count: 1}}, tags)
+	assert.NoError(t, json.Unmarshal(asAlice("GET", "/api/tags", "").Body.Bytes(), &tags))
+	assert.Equal(t, []db.Tag{{Name: "bug", Count: 1}, {Name: "ui", Count: 1}}, tags)
+}
@@ -2,197 +2,452 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/Mr-Cheen1/todo_list/server/validation"
 )
 
+// Структура TaskHandler содержит обработчики HTTP-запросов для работы с задачами.
+type TaskHandler struct {
+	store db.TaskStore
+}
+
+// Функция NewTaskHandler создает обработчики, работающие с переданным хранилищем задач.
+func NewTaskHandler(store db.TaskStore) *TaskHandler {
+	return &TaskHandler{store: store}
+}
+
 // Обработчик для получения списка задач.
-func GetTasks(w http.ResponseWriter, r *http.Request) {
-	statusFilter := r.URL.Query().Get("status")
-	sortOrder := r.URL.Query().Get("sort")
-	sortField := r.URL.Query().Get("sortField")
-	tasks, err := db.GetAllTasks(statusFilter, sortOrder, sortField)
+// Без параметра sortField задачи упорядочиваются по убыванию приоритета, а затем по сроку выполнения.
+// Параметр sort принимает направление сортировки (asc или desc) или ключи сортировки через запятую
+// вида "-status,expectedDate,id", где минус означает сортировку по убыванию.
+// Параметр q включает полнотекстовый поиск по тексту задачи (без sortField результаты
+// упорядочиваются по релевантности). Параметры limit и after включают пагинацию: курсор
+// следующей страницы возвращается в заголовке X-Next-Cursor и передается в параметре after.
+// Параметр status принимает имя или номер статуса, а параметр project - ID проекта или "none"
+// для задач без проекта. Имя статуса ищется в рабочем процессе выбранного проекта,
+// а без фильтра по проекту - во всех рабочих процессах.
+// Параметр tags принимает имена меток через запятую: задача подходит, если у нее есть хотя бы одна
+// из меток, а при tagMatch=all - все метки.
+func (h *TaskHandler) GetTasks(w http.ResponseWriter, r *http.Request) {
+	h.listTasks(w, r, false)
+}
+
+// Обработчик для получения задач из корзины. Параметры те же, что и у списка задач,
+// а у каждой задачи дополнительно указано время удаления deletedAt.
+func (h *TaskHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	h.listTasks(w, r, true)
+}
+
+// Метод listTasks отправляет страницу задач текущего пользователя из списка или, если deleted установлен, из корзины.
+func (h *TaskHandler) listTasks(w http.ResponseWriter, r *http.Request, deleted bool) {
+	query := db.TaskQuery{
+		Owner:     ownerID(r),
+		Deleted:   deleted,
+		Status:    r.URL.Query().Get("status"),
+		Project:   r.URL.Query().Get("project"),
+		Search:    r.URL.Query().Get("q"),
+		SortField: r.URL.Query().Get("sortField"),
+		After:     r.URL.Query().Get("after"),
+		TagMatch:  r.URL.Query().Get("tagMatch"),
+	}
+	if sort := r.URL.Query().Get("sort"); sort == "asc" || sort == "desc" {
+		query.SortOrder = sort
+	} else {
+		query.Sort = sort
+	}
+
+	if tagsStr := r.URL.Query().Get("tags"); tagsStr != "" {
+		tags, err := validation.TagFilter(tagsStr)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		query.Tags = tags
+	}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > db.MaxPageSize {
+			writeError(w, r, badRequest("Limit must be between 1 and %d", db.MaxPageSize))
+			return
+		}
+		query.Limit = limit
+	}
+
+	workflows, err := h.store.GetWorkflows()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if query.Status != "" {
+		ids := statusFilter(workflows, query.Status, query.Project)
+		if len(ids) == 0 {
+			writeError(w, r, badRequest("Unknown task status: %q", query.Status))
+			return
+		}
+		query.Status = strings.Join(ids, ",")
+	}
+
+	page, err := h.store.GetAllTasks(query)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	taskDTOs := make([]db.TaskDTO, 0, len(tasks))
-	for _, task := range tasks {
-		taskDTOs = append(taskDTOs, task.ToDTO())
+	taskDTOs := make([]db.TaskDTO, 0, len(page.Tasks))
+	for _, task := range page.Tasks {
+		taskDTOs = append(taskDTOs, workflows.ToDTO(task))
 	}
 
-	json.NewEncoder(w).Encode(taskDTOs)
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
+	writeJSON(w, http.StatusOK, taskDTOs)
 }
 
-// Обработчик для создания новой задачи.
-func CreateTask(w http.ResponseWriter, r *http.Request) {
-	var taskDTO db.TaskDTO
-	if err := json.NewDecoder(r.Body).Decode(&taskDTO); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+// Обработчик для получения одной задачи по идентификатору. Чужие задачи считаются отсутствующими.
+func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	task, err := taskDTO.ToTask()
+	task, err := h.store.GetTaskByID(ownerID(r), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
-	task.Text = strings.TrimSpace(task.Text)
-	if task.Text == "" {
-		http.Error(w, "Task text cannot be empty", http.StatusBadRequest)
+	workflows, err := h.store.GetWorkflows()
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	if task.ExpectedDate.Before(task.CreatedDate) {
-		http.Error(w, "Expected date cannot be earlier than created date", http.StatusBadRequest)
+	setETag(w, task.Version)
+	writeJSON(w, http.StatusOK, workflows.ToDTO(task))
+}
+
+// Обработчик для получения журнала изменений задачи: создания, изменений полей, смен статуса,
+// удаления и восстановления. История удаленной задачи остается доступной и после очистки корзины.
+func (h *TaskHandler) GetTaskHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	if len(task.Text) > 255 {
-		log.Println("Task text is too long")
-		http.Error(w, "Task text cannot exceed 255 characters", http.StatusBadRequest)
+	events, err := h.store.GetTaskHistory(ownerID(r), id)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	if task.Status != db.StatusInProgress &&
-		task.Status != db.StatusCompleted &&
-		task.Status != db.StatusTesting &&
-		task.Status != db.StatusReturned {
-		http.Error(w, "Incorrect task status", http.StatusBadRequest)
+	workflows, err := h.store.GetWorkflows()
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	if task.CreatedDate.IsZero() {
-		http.Error(w, "Task created date is required", http.StatusBadRequest)
+	// Задача могла переходить между проектами, поэтому статусы событий ищутся во всех рабочих процессах.
+	all := workflows.All()
+	eventDTOs := make([]db.TaskEventDTO, 0, len(events))
+	for _, event := range events {
+		eventDTOs = append(eventDTOs, all.EventDTO(event))
+	}
+	writeJSON(w, http.StatusOK, eventDTOs)
+}
+
+// Обработчик для создания новой задачи. Автором задачи записывается текущий пользователь.
+func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
+	var taskDTO db.TaskDTO
+	if err := json.NewDecoder(r.Body).Decode(&taskDTO); err != nil {
+		writeError(w, r, badRequest("Invalid task JSON: %v", err))
+		return
+	}
+
+	workflows, err := h.store.GetWorkflows()
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	if task.ExpectedDate.IsZero() {
-		http.Error(w, "Task expected date is required", http.StatusBadRequest)
+	task, err := validation.NewTask(workflows, taskDTO)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	task.OwnerID = ownerID(r)
+	task.EditorID = userID(r)
+	if err := h.checkProject(task, 0); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	id, err := db.CreateTask(task)
+	id, err := h.store.CreateTask(task)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	task.ID = id
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(task.ToDTO())
+	task.Version = 1
+	setETag(w, task.Version)
+	w.Header().Set("Location", fmt.Sprintf("/api/tasks/%d", id))
+	writeJSON(w, http.StatusCreated, workflows.ToDTO(task))
 }
 
 // Обработчик для обновления существующей задачи.
-func UpdateTask(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Query().Get("id")
-	id, err := strconv.Atoi(idStr)
+// Ожидаемая версия задачи передается в заголовке If-Match или в поле version,
+// при несовпадении с текущей версией возвращается 412 Precondition Failed.
+// Смена статуса проверяется по переходам рабочего процесса относительно текущего состояния задачи,
+// поэтому без явной версии задача сохраняется, только если не изменилась после чтения.
+func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
 	if err != nil {
-		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	var taskDTO db.TaskDTO
-	err = json.NewDecoder(r.Body).Decode(&taskDTO)
+	if err := json.NewDecoder(r.Body).Decode(&taskDTO); err != nil {
+		writeError(w, r, badRequest("Invalid task JSON: %v", err))
+		return
+	}
+
+	current, err := h.store.GetTaskByID(ownerID(r), id)
 	if err != nil {
-		http.Error(w, "Error decoding task: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
-	task, err := taskDTO.ToTask()
+	workflows, err := h.store.GetWorkflows()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
-	task.ID = int64(id)
+
+	task, err := validation.UpdatedTask(workflows, current, taskDTO)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	task.ID = current.ID
+	task.OwnerID = current.OwnerID
+	task.EditorID = userID(r)
+	if err := h.checkProject(task, current.ProjectID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	task.Tags = changedTags(task.Tags, current.Tags)
+	switch {
+	case version != 0:
+		task.Version = version
+	case task.Version == 0:
+		task.Version = current.Version
+	}
 
 	log.Printf("Updating task: %+v", task)
 
-	task.Text = strings.TrimSpace(task.Text)
-	if task.Text == "" {
-		http.Error(w, "Task text cannot be empty", http.StatusBadRequest)
+	task.Version, err = h.store.UpdateTask(task)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
+	if task.Tags == nil {
+		task.Tags = current.Tags
+	}
 
-	if task.ExpectedDate.Before(task.CreatedDate) {
-		http.Error(w, "Expected date cannot be earlier than created date", http.StatusBadRequest)
+	log.Printf("Task updated successfully: %+v", task)
+
+	setETag(w, task.Version)
+	writeJSON(w, http.StatusOK, workflows.ToDTO(task))
+}
+
+// Обработчик для частичного обновления задачи (JSON Merge Patch, RFC 7396).
+// Тело запроса накладывается на текущее состояние задачи: поля, которых нет в запросе,
+// не изменяются, а результат проверяется так же, как при полном обновлении, включая смену статуса.
+// Задача сохраняется, только если её версия не изменилась с момента чтения (или совпадает
+// с версией из If-Match или поля version), иначе возвращается 412 Precondition Failed.
+func (h *TaskHandler) PatchTask(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	if len(task.Text) > 255 {
-		log.Println("Task text is too long")
-		http.Error(w, "Task text cannot exceed 255 characters", http.StatusBadRequest)
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	if task.Status != db.StatusInProgress &&
-		task.Status != db.StatusCompleted &&
-		task.Status != db.StatusTesting &&
-		task.Status != db.StatusReturned {
-		http.Error(w, "Incorrect task status", http.StatusBadRequest)
+	current, err := h.store.GetTaskByID(ownerID(r), id)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	if task.CreatedDate.IsZero() {
-		http.Error(w, "Task created date is required", http.StatusBadRequest)
+	workflows, err := h.store.GetWorkflows()
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	if task.ExpectedDate.IsZero() {
-		http.Error(w, "Task expected date is required", http.StatusBadRequest)
+	// Декодирование поверх текущего DTO заменяет только переданные в запросе поля.
+	taskDTO := workflows.ToDTO(current)
+	if err := json.NewDecoder(r.Body).Decode(&taskDTO); err != nil {
+		writeError(w, r, badRequest("Invalid task JSON: %v", err))
 		return
 	}
 
-	err = db.UpdateTask(task)
+	task, err := validation.UpdatedTask(workflows, current, taskDTO)
 	if err != nil {
-		http.Error(w, "Error updating task: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
+	task.ID = current.ID
+	task.OwnerID = current.OwnerID
+	task.EditorID = userID(r)
+	if err := h.checkProject(task, current.ProjectID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	task.Tags = changedTags(task.Tags, current.Tags)
+	if version != 0 {
+		task.Version = version
+	}
 
-	log.Printf("Task updated successfully: %+v", task)
+	task.Version, err = h.store.UpdateTask(task)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if task.Tags == nil {
+		task.Tags = current.Tags
+	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(task.ToDTO())
+	setETag(w, task.Version)
+	writeJSON(w, http.StatusOK, workflows.ToDTO(task))
 }
 
-// Обработчик для удаления задачи.
-func DeleteTask(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Query().Get("id")
-	if idStr == "" {
-		http.Error(w, "Missing id parameter", http.StatusBadRequest)
+// Обработчик для удаления задачи. Задача перемещается в корзину и может быть восстановлена,
+// пока не истечет срок хранения корзины.
+// Если передан заголовок If-Match, задача удаляется, только если её версия совпадает с ним.
+func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	id, err := strconv.Atoi(idStr)
+	version, err := ifMatchVersion(r)
 	if err != nil {
-		http.Error(w, "Invalid id parameter", http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
-	result, err := db.DB.Exec("DELETE FROM tasks WHERE id = $1", id)
+	if err := h.store.DeleteTask(ownerID(r), userID(r), id, version); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Обработчик для восстановления задачи из корзины. Возвращает восстановленную задачу.
+func (h *TaskHandler) RestoreTask(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
 	if err != nil {
-		log.Printf("Error deleting task: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	if _, err := h.store.RestoreTask(ownerID(r), userID(r), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	task, err := h.store.GetTaskByID(ownerID(r), id)
 	if err != nil {
-		log.Printf("Error getting rows affected: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	if rowsAffected == 0 {
-		http.Error(w, "Task not found", http.StatusNotFound)
+	workflows, err := h.store.GetWorkflows()
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	setETag(w, task.Version)
+	writeJSON(w, http.StatusOK, workflows.ToDTO(task))
+}
+
+// Метод checkProject проверяет, что проект задачи есть в списке задач её автора.
+// Проект current, в котором задача уже находится, не проверяется.
+func (h *TaskHandler) checkProject(task db.Task, current int64) error {
+	if task.ProjectID == 0 || task.ProjectID == current {
+		return nil
+	}
+	_, err := h.store.GetProject(task.OwnerID, task.ProjectID)
+	if errors.Is(err, db.ErrProjectNotFound) {
+		return validation.UnknownProject()
+	}
+	return err
+}
+
+// Функция statusFilter возвращает номера статусов с именем или номером ref для фильтра задач:
+// в рабочем процессе проекта project, если выбран один проект или задачи без проекта,
+// иначе во всех рабочих процессах.
+func statusFilter(workflows db.Workflows, ref, project string) []string {
+	if project == db.NoProject {
+		project = "0"
+	}
+	var statuses []db.Status
+	if id, err := strconv.ParseInt(project, 10, 64); err == nil {
+		if def, ok := workflows.For(id).Lookup(ref); ok {
+			statuses = append(statuses, def.ID)
+		}
+	} else {
+		statuses = workflows.StatusIDs(ref)
+	}
+
+	ids := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		ids = append(ids, strconv.Itoa(int(status)))
+	}
+	return ids
+}
+
+// Функция changedTags возвращает новые метки задачи или nil, если они не отличаются от текущих current:
+// хранилище не перезаписывает метки, равные nil.
+func changedTags(tags, current []string) []string {
+	if slices.Equal(tags, current) {
+		return nil
+	}
+	return tags
+}
+
+// Функция writeJSON отправляет ответ с указанным статусом и телом в формате JSON.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
 }
@@ -6,15 +6,81 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Mr-Cheen1/todo_list/server/auth"
 	"github.com/Mr-Cheen1/todo_list/server/db"
 	"github.com/stretchr/testify/assert"
 )
 
+// Пользователь, от имени которого тесты работают с задачами.
+const testUserID int64 = 1
+
+// Контекст запросов пользователя testUserID, прошедшего аутентификацию.
+var userCtx = auth.WithUser(context.Background(), db.User{ID: testUserID, Username: "alice"})
+
+// Колонки строк задач, которые возвращает хранилище PostgresStore.
+var taskRowColumns = []string{
+	"id", "task_text", "createdDate", "expectedDate", "status", "version", "project_id", "priority", "tags",
+}
+
+// Колонки строк задач, которые PostgresStore считывает с блокировкой перед изменением.
+var lockedRowColumns = append(slices.Clone(taskRowColumns), "owner_id", "deleted_at")
+
+// Структура listFixture содержит общий список задач alice, открытый bob как наблюдателю,
+// и mux с маршрутами задач и маршрутами, которые регистрирует тест.
+type listFixture struct {
+	t          *testing.T
+	mux        *http.ServeMux
+	alice, bob db.User
+}
+
+// Функция newListFixture создает пользователей alice и bob, открывает bob список задач alice
+// и регистрирует в mux маршруты задач и маршруты, добавленные функцией register.
+func newListFixture(t *testing.T, register func(store db.TaskStore, mux *http.ServeMux)) *listFixture {
+	t.Helper()
+	store := db.NewMemoryStore()
+	alice, err := store.CreateUser(db.User{Username: "alice"})
+	assert.NoError(t, err)
+	bob, err := store.CreateUser(db.User{Username: "bob"})
+	assert.NoError(t, err)
+	assert.NoError(t, store.SetListMember(alice, bob, db.RoleViewer))
+
+	mux := http.NewServeMux()
+	NewTaskHandler(store).Register(mux)
+	register(store, mux)
+	return &listFixture{
+		t: t, mux: mux, alice: db.User{ID: alice, Username: "alice"}, bob: db.User{ID: bob, Username: "bob"},
+	}
+}
+
+// Метод serve выполняет запрос от имени пользователя user.
+func (f *listFixture) serve(user db.User, method, path, body string) *httptest.ResponseRecorder {
+	ctx := auth.WithUser(context.Background(), user)
+	req, err := http.NewRequestWithContext(ctx, method, path, strings.NewReader(body))
+	assert.NoError(f.t, err)
+	rr := httptest.NewRecorder()
+	f.mux.ServeHTTP(rr, req)
+	return rr
+}
+
+// Метод asAlice выполняет запрос от имени владельца списка alice.
+func (f *listFixture) asAlice(method, path, body string) *httptest.ResponseRecorder {
+	return f.serve(f.alice, method, path, body)
+}
+
+// Метод user возвращает пользователя по имени из тестового случая; по умолчанию запрос выполняет alice.
+func (f *listFixture) user(name string) db.User {
+	if name == "bob" {
+		return f.bob
+	}
+	return f.alice
+}
+
 // Тест для обработчика GetTasks.
 func TestGetTasks(t *testing.T) {
 	mockDB, mock, err := sqlmock.New()
@@ -23,16 +89,17 @@ func TestGetTasks(t *testing.T) {
 	}
 	defer mockDB.Close()
 
-	db.DB = mockDB
+	h := NewTaskHandler(db.NewPostgresStore(mockDB))
 
-	rows := sqlmock.NewRows([]string{"id", "text", "createdDate", "expectedDate", "status"}).
-		AddRow(1, "Test Task", time.Now(), time.Now().Add(24*time.Hour), db.StatusInProgress)
-	mock.ExpectQuery("^SELECT (.+) FROM tasks").WillReturnRows(rows)
+	rows := sqlmock.NewRows(taskRowColumns).
+		AddRow(1, "Test Task", time.Now(), time.Now().Add(24*time.Hour), db.StatusInProgress, 1, nil, 0, "")
+	expectWorkflow(mock)
+	mock.ExpectQuery("^SELECT (.+) FROM tasks").WithArgs(testUserID, int64(db.StatusInProgress)).WillReturnRows(rows)
 
 	req, err := http.NewRequestWithContext(
-		context.Background(),
+		userCtx,
 		"GET",
-		"/tasks?status=active&sort=asc&sortField=createdDate",
+		"/tasks?status=in_progress&sort=asc&sortField=createdDate",
 		nil,
 	)
 	if err != nil {
@@ -40,7 +107,7 @@ func TestGetTasks(t *testing.T) {
 	}
 
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(GetTasks)
+	handler := http.HandlerFunc(h.GetTasks)
 
 	handler.ServeHTTP(rr, req)
 
@@ -72,18 +139,25 @@ func TestCreateTask(t *testing.T) {
 	defer mockDB.Close()
 
 	// Ожидаем, что запрос INSERT вернет ID 1
+	expectWorkflow(mock)
+	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO tasks").
-		WithArgs(taskText, createdDate.Format("2006-01-02"), expectedDate.Format("2006-01-02"), taskStatus).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		WithArgs(taskText, createdDate.Format("2006-01-02"), expectedDate.Format("2006-01-02"), taskStatus, testUserID, nil,
+			db.PriorityNormal).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1))
+	mock.ExpectExec("INSERT INTO task_events").
+		WithArgs(int64(1), testUserID, testUserID, db.EventCreated, nil, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
-	db.DB = mockDB
+	h := NewTaskHandler(db.NewPostgresStore(mockDB))
 
-	req, err := http.NewRequestWithContext(context.Background(), "POST", "/api/tasks/create", strings.NewReader(taskJSON))
+	req, err := http.NewRequestWithContext(userCtx, "POST", "/api/tasks/create", strings.NewReader(taskJSON))
 	assert.NoError(t, err)
 
 	rr := httptest.NewRecorder()
 
-	handler := http.HandlerFunc(CreateTask)
+	handler := http.HandlerFunc(h.CreateTask)
 	handler.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusCreated, rr.Code)
@@ -93,7 +167,7 @@ func TestCreateTask(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), createdTask.ID) // Проверяем, что ID равен 1
 	assert.Equal(t, taskText, createdTask.Text)
-	assert.Equal(t, taskStatus, createdTask.Status)
+	assert.Equal(t, db.StatusRef("in_progress"), createdTask.Status)
 	assert.Equal(t, createdDate.Format("2006-01-02"), createdTask.CreatedDate)
 	assert.Equal(t, expectedDate.Format("2006-01-02"), createdTask.ExpectedDate)
 
@@ -121,29 +195,57 @@ func TestUpdateTask(t *testing.T) {
 	assert.NoError(t, err)
 	defer mockDB.Close()
 
-	mock.ExpectExec(`UPDATE tasks SET task_text = \$1, createdDate = \$2, 
-		expectedDate = \$3, status = \$4 WHERE id = \$5`).
+	mock.ExpectQuery(`SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2`).
+		WithArgs(1, testUserID).
+		WillReturnRows(sqlmock.NewRows(taskRowColumns).
+			AddRow(1, "Task", fixedTime, expectedTime, db.StatusInProgress, 1, nil, 0, ""))
+	expectWorkflow(mock)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL `+
+		`ORDER BY id FOR UPDATE`).
+		WithArgs(taskToUpdate.ID, testUserID).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns).
+			AddRow(1, "Task", fixedTime, expectedTime, db.StatusInProgress, 1, nil, 0, "", testUserID, nil))
+	mock.ExpectExec(`UPDATE tasks SET task_text = \$1, createdDate = \$2, expectedDate = \$3, status = \$4, `+
+		`project_id = \$5, priority = \$6, version = \$7 WHERE id = \$8`).
 		WithArgs(taskToUpdate.Text, taskToUpdate.CreatedDate.Format("2006-01-02"),
-			taskToUpdate.ExpectedDate.Format("2006-01-02"), taskToUpdate.Status, taskToUpdate.ID).
+			taskToUpdate.ExpectedDate.Format("2006-01-02"), taskToUpdate.Status, nil, db.PriorityNormal, int64(2),
+			taskToUpdate.ID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO task_events").
+		WithArgs(taskToUpdate.ID, testUserID, testUserID, db.EventUpdated, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
-	db.DB = mockDB
+	h := NewTaskHandler(db.NewPostgresStore(mockDB))
 
-	req, err := http.NewRequestWithContext(context.Background(), "PUT",
+	req, err := http.NewRequestWithContext(userCtx, "PUT",
 		"/api/tasks/update?id=1", strings.NewReader(taskJSON))
 	assert.NoError(t, err)
 	req.Header.Set("Content-Type", "application/json")
 
 	rr := httptest.NewRecorder()
 
-	handler := http.HandlerFunc(UpdateTask)
+	handler := http.HandlerFunc(h.UpdateTask)
 	handler.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+// Функция expectWorkflow ожидает запрос статусов и возвращает встроенный рабочий процесс.
+func expectWorkflow(mock sqlmock.Sqlmock) {
+	rows := sqlmock.NewRows([]string{"id", "project_id", "name", "title", "position", "color", "terminal", "next"})
+	for _, def := range db.DefaultWorkflow().Statuses {
+		for _, next := range def.Transitions {
+			rows.AddRow(int(def.ID), 0, def.Name, def.Title, def.Position, def.Color, def.Terminal, next)
+		}
+	}
+	mock.ExpectQuery(`^SELECT (.+) FROM statuses s`).WillReturnRows(rows)
+}
+
 // Тест для обработчика DeleteTask.
 func TestDeleteTask(t *testing.T) {
 	taskID := 1
@@ -152,20 +254,588 @@ func TestDeleteTask(t *testing.T) {
 	assert.NoError(t, err)
 	defer mockDB.Close()
 
-	mock.ExpectExec("DELETE FROM tasks WHERE id = \\$1").WithArgs(taskID).WillReturnResult(sqlmock.NewResult(0, 1))
-
-	db.DB = mockDB
+	day := time.Date(2023, time.April, 4, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL `).
+		WithArgs(int64(taskID), testUserID).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns).
+			AddRow(taskID, "Task", day, day, db.StatusInProgress, 1, nil, 0, "", testUserID, nil))
+	mock.ExpectExec(`^UPDATE tasks SET deleted_at = \$1, version = version \+ 1 WHERE id = \$2$`).
+		WithArgs(sqlmock.AnyArg(), taskID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO task_events").
+		WithArgs(int64(taskID), testUserID, testUserID, db.EventDeleted, nil, sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	h := NewTaskHandler(db.NewPostgresStore(mockDB))
 
 	deleteURL := fmt.Sprintf("/api/tasks/delete?id=%d", taskID)
-	req, err := http.NewRequestWithContext(context.Background(), "DELETE", deleteURL, nil)
+	req, err := http.NewRequestWithContext(userCtx, "DELETE", deleteURL, nil)
 	assert.NoError(t, err)
 
 	rr := httptest.NewRecorder()
 
-	handler := http.HandlerFunc(DeleteTask)
+	handler := http.HandlerFunc(h.DeleteTask)
 	handler.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusOK, rr.Code)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+// Тест для пагинации в обработчике GetTasks.
+func TestGetTasksPagination(t *testing.T) {
+	store := db.NewMemoryStore()
+	day := time.Date(2023, time.April, 4, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		_, err := store.CreateTask(db.Task{OwnerID: testUserID, Text: fmt.Sprintf("Task %d", i), CreatedDate: day,
+			ExpectedDate: day})
+		assert.NoError(t, err)
+	}
+	h := NewTaskHandler(store)
+
+	req, err := http.NewRequestWithContext(userCtx, "GET", "/api/tasks?limit=2", nil)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(h.GetTasks).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var taskDTOs []db.TaskDTO
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &taskDTOs))
+	assert.Len(t, taskDTOs, 2)
+	cursor := rr.Header().Get("X-Next-Cursor")
+	assert.NotEmpty(t, cursor)
+
+	req, err = http.NewRequestWithContext(userCtx, "GET", "/api/tasks?limit=2&after="+cursor, nil)
+	assert.NoError(t, err)
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(h.GetTasks).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &taskDTOs))
+	assert.Len(t, taskDTOs, 1)
+	assert.Equal(t, "Task 2", taskDTOs[0].Text)
+	assert.Empty(t, rr.Header().Get("X-Next-Cursor"))
+}
+
+// Тест для некорректных параметров пагинации в обработчике GetTasks.
+func TestGetTasksInvalidPagination(t *testing.T) {
+	h := NewTaskHandler(db.NewMemoryStore())
+
+	for _, query := range []string{"limit=0", "limit=abc", "limit=100000", "after=garbage"} {
+		req, err := http.NewRequestWithContext(userCtx, "GET", "/api/tasks?"+query, nil)
+		assert.NoError(t, err)
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(h.GetTasks).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
+
+// Тест для полнотекстового поиска в обработчике GetTasks.
+func TestGetTasksSearch(t *testing.T) {
+	store := db.NewMemoryStore()
+	day := time.Date(2023, time.April, 4, 0, 0, 0, 0, time.UTC)
+	for _, text := range []string{"Купить молоко", "Позвонить в банк", "Молочный отчет"} {
+		_, err := store.CreateTask(db.Task{OwnerID: testUserID, Text: text, CreatedDate: day, ExpectedDate: day,
+			Status: db.StatusInProgress})
+		assert.NoError(t, err)
+	}
+	h := NewTaskHandler(store)
+
+	req, err := http.NewRequestWithContext(userCtx, "GET", "/api/tasks?status=0&q=%D0%BC%D0%BE%D0%BB*", nil)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(h.GetTasks).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var taskDTOs []db.TaskDTO
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &taskDTOs))
+	assert.Len(t, taskDTOs, 2)
+	assert.NotContains(t, rr.Body.String(), "банк")
+}
+
+// Тест для сортировки по нескольким ключам в обработчике GetTasks.
+func TestGetTasksMultiSort(t *testing.T) {
+	store := db.NewMemoryStore()
+	day := time.Date(2023, time.April, 4, 0, 0, 0, 0, time.UTC)
+	for _, task := range []db.Task{
+		{Text: "Позже", Status: db.StatusInProgress, ExpectedDate: day.AddDate(0, 0, 2)},
+		{Text: "Проверка", Status: db.StatusTesting, ExpectedDate: day.AddDate(0, 0, 3)},
+		{Text: "Раньше", Status: db.StatusInProgress, ExpectedDate: day.AddDate(0, 0, 1)},
+		{Text: "Тоже раньше", Status: db.StatusInProgress, ExpectedDate: day.AddDate(0, 0, 1)},
+	} {
+		task.OwnerID, task.CreatedDate = testUserID, day
+		_, err := store.CreateTask(task)
+		assert.NoError(t, err)
+	}
+	h := NewTaskHandler(store)
+
+	// get выполняет запрос списка задач с параметрами params.
+	get := func(params string) *httptest.ResponseRecorder {
+		req, err := http.NewRequestWithContext(userCtx, "GET", "/api/tasks?"+params, nil)
+		assert.NoError(t, err)
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(h.GetTasks).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("sort=-status,expectedDate,-id")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var taskDTOs []db.TaskDTO
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &taskDTOs))
+	texts := make([]string, 0, len(taskDTOs))
+	for _, dto := range taskDTOs {
+		texts = append(texts, dto.Text)
+	}
+	assert.Equal(t, []string{"Проверка", "Тоже раньше", "Раньше", "Позже"}, texts)
+
+	for _, params := range []string{
+		"sort=-status,owner_id", "sort=status,-status", "sort=status,", "sortField=status&sort=-status,id",
+	} {
+		assert.Equal(t, http.StatusBadRequest, get(params).Code, params)
+	}
+}
+
+// Тест для обработчика GetTask.
+func TestGetTask(t *testing.T) {
+	store := db.NewMemoryStore()
+	day := time.Date(2023, time.April, 4, 0, 0, 0, 0, time.UTC)
+	id, err := store.CreateTask(db.Task{OwnerID: testUserID, Text: "Single", CreatedDate: day, ExpectedDate: day,
+		Status: db.StatusTesting})
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	NewTaskHandler(store).Register(mux)
+
+	testCases := []struct {
+		path string
+		code int
+	}{
+		{path: fmt.Sprintf("/api/tasks/%d", id), code: http.StatusOK},
+		{path: "/api/tasks/999", code: http.StatusNotFound},
+		{path: "/api/tasks/abc", code: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		req, err := http.NewRequestWithContext(userCtx, "GET", tc.path, nil)
+		assert.NoError(t, err)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+
+		assert.Equal(t, tc.code, rr.Code, tc.path)
+	}
+
+	req, err := http.NewRequestWithContext(userCtx, "GET", fmt.Sprintf("/api/tasks/%d", id), nil)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	var taskDTO db.TaskDTO
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &taskDTO))
+	assert.Equal(t, db.TaskDTO{
+		ID: id, Text: "Single", CreatedDate: "2023-04-04", ExpectedDate: "2023-04-04", Status: "testing",
+		Priority: "normal", Tags: []string{}, Version: 1,
+	}, taskDTO)
+	assert.Equal(t, `"1"`, rr.Header().Get("ETag"))
+}
+
+// Тест для обработчика PatchTask.
+func TestPatchTask(t *testing.T) {
+	store := db.NewMemoryStore()
+	day := time.Date(2023, time.April, 4, 0, 0, 0, 0, time.UTC)
+	id, err := store.CreateTask(db.Task{OwnerID: testUserID, Text: "Patch me", CreatedDate: day,
+		ExpectedDate: day.AddDate(0, 0, 2), Status: db.StatusInProgress})
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	NewTaskHandler(store).Register(mux)
+
+	testCases := []struct {
+		name     string
+		path     string
+		body     string
+		code     int
+		expected db.TaskDTO
+	}{
+		{
+			name: "Только статус", path: fmt.Sprintf("/api/tasks/%d", id), body: `{"status":"testing"}`, code: http.StatusOK,
+			expected: db.TaskDTO{
+				ID: id, Text: "Patch me", CreatedDate: "2023-04-04", ExpectedDate: "2023-04-06", Status: "testing",
+				Priority: "normal", Tags: []string{}, Version: 2,
+			},
+		},
+		{
+			name: "Только текст", path: fmt.Sprintf("/api/tasks/%d", id), body: `{"text":"  Patched  "}`, code: http.StatusOK,
+			expected: db.TaskDTO{
+				ID: id, Text: "Patched", CreatedDate: "2023-04-04", ExpectedDate: "2023-04-06", Status: "testing",
+				Priority: "normal", Tags: []string{}, Version: 3,
+			},
+		},
+		{
+			name: "Только приоритет", path: fmt.Sprintf("/api/tasks/%d", id), body: `{"priority":"urgent"}`, code: http.StatusOK,
+			expected: db.TaskDTO{
+				ID: id, Text: "Patched", CreatedDate: "2023-04-04", ExpectedDate: "2023-04-06", Status: "testing",
+				Priority: "urgent", Tags: []string{}, Version: 4,
+			},
+		},
+		{name: "Неизвестный приоритет", path: fmt.Sprintf("/api/tasks/%d", id), body: `{"priority":"critical"}`,
+			code: http.StatusBadRequest},
+		{name: "Пустой текст", path: fmt.Sprintf("/api/tasks/%d", id), body: `{"text":" "}`, code: http.StatusBadRequest},
+		{name: "Дата раньше создания", path: fmt.Sprintf("/api/tasks/%d", id), body: `{"expectedDate":"2023-04-01"}`,
+			code: http.StatusBadRequest},
+		{name: "Неверный статус", path: fmt.Sprintf("/api/tasks/%d", id), body: `{"status":42}`, code: http.StatusBadRequest},
+		{name: "Недопустимый переход", path: fmt.Sprintf("/api/tasks/%d", id), body: `{"status":"in_progress"}`,
+			code: http.StatusBadRequest},
+		{name: "Некорректный JSON", path: fmt.Sprintf("/api/tasks/%d", id), body: `{"status":`, code: http.StatusBadRequest},
+		{name: "Задача не найдена", path: "/api/tasks/999", body: `{"status":1}`, code: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(userCtx, "PATCH", tc.path, strings.NewReader(tc.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.code, rr.Code)
+			if tc.code != http.StatusOK {
+				return
+			}
+			var taskDTO db.TaskDTO
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &taskDTO))
+			assert.Equal(t, tc.expected, taskDTO)
+		})
+	}
+
+	// Отклоненные изменения не сохраняются.
+	task, err := store.GetTaskByID(testUserID, int(id))
+	assert.NoError(t, err)
+	assert.Equal(t, "Patched", task.Text)
+	assert.Equal(t, day.AddDate(0, 0, 2), task.ExpectedDate)
+}
+
+// Тест для проверки заголовка If-Match в обработчиках изменения и удаления задачи.
+func TestIfMatch(t *testing.T) {
+	store := db.NewMemoryStore()
+	day := time.Date(2023, time.April, 4, 0, 0, 0, 0, time.UTC)
+	id, err := store.CreateTask(db.Task{OwnerID: testUserID, Text: "Shared", CreatedDate: day, ExpectedDate: day,
+		Status: db.StatusInProgress})
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	NewTaskHandler(store).Register(mux)
+	path := fmt.Sprintf("/api/tasks/%d", id)
+	full := `{"text":"Shared","createdDate":"2023-04-04","expectedDate":"2023-04-04","status":"testing"}`
+
+	testCases := []struct {
+		name    string
+		method  string
+		body    string
+		ifMatch string
+		code    int
+		etag    string
+	}{
+		{name: "Частичное обновление с актуальной версией", method: "PATCH", body: `{"status":"testing"}`, ifMatch: `"1"`,
+			code: http.StatusOK, etag: `"2"`},
+		{name: "Частичное обновление с устаревшей версией", method: "PATCH", body: `{"status":"completed"}`, ifMatch: `"1"`,
+			code: http.StatusPreconditionFailed},
+		{name: "Устаревшая версия в теле запроса", method: "PATCH", body: `{"status":"completed","version":1}`,
+			code: http.StatusPreconditionFailed},
+		{name: "Полное обновление с устаревшей версией", method: "PUT", body: full, ifMatch: `"1"`,
+			code: http.StatusPreconditionFailed},
+		{name: "Полное обновление без If-Match", method: "PUT", body: full, code: http.StatusOK, etag: `"3"`},
+		{name: "Любая версия", method: "PATCH", body: `{"status":"completed"}`, ifMatch: "*", code: http.StatusOK,
+			etag: `"4"`},
+		{name: "Слабый ETag", method: "PATCH", body: `{"status":"returned"}`, ifMatch: `W/"4"`,
+			code: http.StatusPreconditionFailed},
+		{name: "Удаление с устаревшей версией", method: "DELETE", ifMatch: `"3"`, code: http.StatusPreconditionFailed},
+		{name: "Удаление с актуальной версией", method: "DELETE", ifMatch: `"4"`, code: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(userCtx, tc.method, path, strings.NewReader(tc.body))
+			assert.NoError(t, err)
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.code, rr.Code)
+			assert.Equal(t, tc.etag, rr.Header().Get("ETag"))
+		})
+	}
+}
+
+// Тест для ответа с ошибками проверки задачи.
+func TestCreateTaskValidationErrors(t *testing.T) {
+	h := NewTaskHandler(db.NewMemoryStore())
+
+	body := `{"text":"  ","createdDate":"2023-10-02","expectedDate":"2023-10-01","status":9}`
+	req, err := http.NewRequestWithContext(userCtx, "POST", "/api/tasks", strings.NewReader(body))
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "/problems/validation-error",
+		"title": "Validation failed",
+		"status": 400,
+		"detail": "Task text cannot be empty; Expected date cannot be earlier than created date; Incorrect task status",
+		"instance": "/api/tasks",
+		"errors": [
+			{"field":"text","code":"required","message":"Task text cannot be empty"},
+			{"field":"expectedDate","code":"date_order","message":"Expected date cannot be earlier than created date"},
+			{"field":"status","code":"invalid","message":"Incorrect task status"}
+		]
+	}`, rr.Body.String())
+}
+
+// Тест для ответа 404 на изменение и удаление несуществующей задачи по всем маршрутам.
+func TestTaskNotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	stores := map[string]db.TaskStore{
+		"Память":     db.NewMemoryStore(),
+		"PostgreSQL": db.NewPostgresStore(mockDB),
+	}
+	full := `{"text":"Task","createdDate":"2023-10-01","expectedDate":"2023-10-01","status":0}`
+
+	testCases := []struct {
+		method string
+		path   string
+		body   string
+		expect func()
+	}{
+		{method: "GET", path: "/api/tasks/42", expect: func() {
+			mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL$`).
+				WithArgs(42, testUserID).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		}},
+		{method: "PUT", path: "/api/tasks/42", body: full, expect: func() {
+			mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL$`).
+				WithArgs(42, testUserID).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		}},
+		{method: "PUT", path: "/api/tasks/update?id=42", body: full, expect: func() {
+			mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL$`).
+				WithArgs(42, testUserID).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		}},
+		{method: "PATCH", path: "/api/tasks/42", body: `{"status":1}`, expect: func() {
+			mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL$`).
+				WithArgs(42, testUserID).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		}},
+		{method: "DELETE", path: "/api/tasks/42", expect: func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL `).
+				WithArgs(int64(42), testUserID).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectRollback()
+		}},
+		{method: "DELETE", path: "/api/tasks/delete?id=42", expect: func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL `).
+				WithArgs(int64(42), testUserID).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectRollback()
+		}},
+	}
+
+	for name, store := range stores {
+		mux := http.NewServeMux()
+		NewTaskHandler(store).Register(mux)
+
+		for _, tc := range testCases {
+			t.Run(name+" "+tc.method+" "+tc.path, func(t *testing.T) {
+				if _, ok := store.(*db.PostgresStore); ok {
+					tc.expect()
+				}
+				req, err := http.NewRequestWithContext(userCtx, tc.method, tc.path, strings.NewReader(tc.body))
+				assert.NoError(t, err)
+				rr := httptest.NewRecorder()
+				mux.ServeHTTP(rr, req)
+
+				assert.Equal(t, http.StatusNotFound, rr.Code)
+				assert.Contains(t, rr.Body.String(), `"detail":"Task 42 does not exist"`)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			})
+		}
+	}
+}
+
+// Тест для обработчика GetTaskHistory: история сохраняется после удаления задачи.
+func TestGetTaskHistory(t *testing.T) {
+	store := db.NewMemoryStore()
+	day := time.Date(2023, time.April, 4, 0, 0, 0, 0, time.UTC)
+	id, err := store.CreateTask(db.Task{OwnerID: testUserID, Text: "History", CreatedDate: day, ExpectedDate: day,
+		Status: db.StatusInProgress})
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	NewTaskHandler(store).Register(mux)
+	path := fmt.Sprintf("/api/tasks/%d", id)
+
+	for _, body := range []string{`{"status":"testing"}`, `{"status":"returned","reason":"Падает на пустом списке"}`} {
+		req, err := http.NewRequestWithContext(userCtx, "PATCH", path, strings.NewReader(body))
+		assert.NoError(t, err)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+	req, err := http.NewRequestWithContext(userCtx, "DELETE", path, nil)
+	assert.NoError(t, err)
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+
+	req, err = http.NewRequestWithContext(userCtx, "GET", path+"/history", nil)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var events []db.TaskEventDTO
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &events))
+	assert.Len(t, events, 4)
+	assert.Equal(t, db.EventStatusChanged, events[2].Type)
+	assert.Equal(t, db.FieldChange{Old: "testing", New: "returned"}, events[2].Changes["status"])
+	assert.Equal(t, int64(3), events[2].Version)
+	assert.Equal(t, "Падает на пустом списке", events[2].Reason)
+	assert.Empty(t, events[1].Reason)
+	assert.Equal(t, db.EventDeleted, events[3].Type)
+	for _, event := range events[1:] {
+		if assert.NotNil(t, event.ActorID) {
+			assert.Equal(t, testUserID, *event.ActorID)
+		}
+	}
+	// Задача создана в обход API, поэтому пользователь её создания неизвестен.
+	assert.Nil(t, events[0].ActorID)
+
+	// Причина без смены статуса отклоняется.
+	req, err = http.NewRequestWithContext(userCtx, "POST", path+"/restore", nil)
+	assert.NoError(t, err)
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+	req, err = http.NewRequestWithContext(userCtx, "PATCH", path, strings.NewReader(`{"reason":"Просто так"}`))
+	assert.NoError(t, err)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"reason"`)
+
+	req, err = http.NewRequestWithContext(userCtx, "GET", "/api/tasks/999/history", nil)
+	assert.NoError(t, err)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+// Тест для корзины: удаленная задача недоступна по своему адресу, видна в /api/trash
+// и возвращается в список после восстановления.
+func TestTrash(t *testing.T) {
+	store := db.NewMemoryStore()
+	day := time.Date(2023, time.April, 4, 0, 0, 0, 0, time.UTC)
+	id, err := store.CreateTask(db.Task{OwnerID: testUserID, Text: "Trash", CreatedDate: day, ExpectedDate: day,
+		Status: db.StatusInProgress})
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	NewTaskHandler(store).Register(mux)
+	path := fmt.Sprintf("/api/tasks/%d", id)
+
+	// serve выполняет запрос и возвращает ответ.
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		req, err := http.NewRequestWithContext(userCtx, method, target, nil)
+		assert.NoError(t, err)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusOK, serve("DELETE", path).Code)
+	assert.Equal(t, http.StatusNotFound, serve("GET", path).Code)
+	assert.Equal(t, "[]\n", serve("GET", "/api/tasks").Body.String())
+
+	rr := serve("GET", "/api/trash?status=in_progress")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var trash []db.TaskDTO
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &trash))
+	if assert.Len(t, trash, 1) {
+		assert.Equal(t, id, trash[0].ID)
+		assert.Equal(t, db.StatusRef("in_progress"), trash[0].Status)
+		assert.NotEmpty(t, trash[0].DeletedAt)
+	}
+
+	rr = serve("POST", path+"/restore")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+	var restored db.TaskDTO
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &restored))
+	assert.Equal(t, "Trash", restored.Text)
+	assert.Empty(t, restored.DeletedAt)
+
+	assert.Equal(t, http.StatusOK, serve("GET", path).Code)
+	assert.Equal(t, http.StatusNotFound, serve("POST", path+"/restore").Code)
+	assert.Equal(t, "[]\n", serve("GET", "/api/trash").Body.String())
+}
+
+// Тест для изоляции задач пользователей: по чужому ID задачу нельзя получить, изменить, удалить,
+// восстановить или посмотреть её историю - для другого пользователя её не существует.
+func TestTaskIsolation(t *testing.T) {
+	store := db.NewMemoryStore()
+	day := time.Date(2023, time.April, 4, 0, 0, 0, 0, time.UTC)
+	id, err := store.CreateTask(db.Task{OwnerID: testUserID, Text: "Private", CreatedDate: day, ExpectedDate: day})
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	NewTaskHandler(store).Register(mux)
+	path := fmt.Sprintf("/api/tasks/%d", id)
+	bobCtx := auth.WithUser(context.Background(), db.User{ID: 2, Username: "bob"})
+	full := `{"text":"Stolen","createdDate":"2023-04-04","expectedDate":"2023-04-04","status":"in_progress"}`
+
+	testCases := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{method: "GET", path: path},
+		{method: "GET", path: path + "/history"},
+		{method: "PUT", path: path, body: full},
+		{method: "PATCH", path: path, body: `{"text":"Stolen"}`},
+		{method: "DELETE", path: path},
+		{method: "DELETE", path: "/api/tasks/delete?id=" + fmt.Sprint(id)},
+		{method: "POST", path: path + "/restore"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(bobCtx, tc.method, tc.path, strings.NewReader(tc.body))
+			assert.NoError(t, err)
+			req.Header.Set("If-Match", `"1"`)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		})
+	}
+
+	for _, target := range []string{"/api/tasks", "/api/trash"} {
+		req, err := http.NewRequestWithContext(bobCtx, "GET", target, nil)
+		assert.NoError(t, err)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		assert.Equal(t, "[]\n", rr.Body.String(), target)
+	}
+
+	// Задача владельца не изменилась.
+	task, err := store.GetTaskByID(testUserID, int(id))
+	assert.NoError(t, err)
+	assert.Equal(t, "Private", task.Text)
+	assert.Equal(t, int64(1), task.Version)
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Mr-Cheen1/todo_list/server/auth"
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/Mr-Cheen1/todo_list/server/validation"
+)
+
+// Ошибка запроса с токеном, которому не выдано нужное разрешение.
+var errInsufficientScope = errors.New("insufficient token scope")
+
+// Ошибка запроса с токеном к маршруту, доступному только через сессию: управлению токенами,
+// участниками общих списков и рабочим процессом.
+var errSessionRequired = errors.New("session required")
+
+// Обработчик для получения персональных токенов доступа текущего пользователя.
+// Сами токены не возвращаются: они хранятся только в виде хеша.
+func (h *AuthHandler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.store.GetAPITokens(userID(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	dtos := make([]db.APITokenDTO, 0, len(tokens))
+	for _, token := range tokens {
+		dtos = append(dtos, token.ToDTO())
+	}
+	writeJSON(w, http.StatusOK, dtos)
+}
+
+// Обработчик для создания персонального токена доступа. Токен возвращается только в этом ответе.
+func (h *AuthHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	var req db.APITokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, badRequest("Invalid token JSON: %v", err))
+		return
+	}
+	now := time.Now().UTC()
+	token, err := validation.NewAPIToken(req, now)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	raw, hash, err := auth.NewToken()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	token.UserID = userID(r)
+	token.TokenHash = hash
+	token.ID, err = h.store.CreateAPIToken(token)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	token.CreatedAt = now
+
+	dto := token.ToDTO()
+	dto.Token = raw
+	writeJSON(w, http.StatusCreated, dto)
+}
+
+// Обработчик для отзыва персонального токена доступа. Отозванный токен перестает действовать сразу.
+func (h *AuthHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(w, r, badRequest("Invalid token ID: %q", idStr))
+		return
+	}
+	if err := h.store.DeleteAPIToken(userID(r), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Функция requireSession отклоняет запросы, аутентифицированные токеном, с кодом 403 Forbidden.
+// Применяется внутри RequireUser к маршрутам управления токенами.
+func requireSession(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.TokenFrom(r.Context()); ok {
+			writeError(w, r, errSessionRequired)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// Маршруты API, к которым относятся разрешения tasks:read и tasks:write (вместе с вложенными путями).
+var taskScopeRoutes = []string{"/api/tasks", "/api/trash", "/api/projects", "/api/tags"}
+
+// Маршруты API, которые токен может только читать с разрешением tasks:read: изменять участников
+// списков и рабочий процесс можно только после входа.
+var readOnlyScopeRoutes = []string{"/api/statuses", "/api/lists"}
+
+// Функция projectStatusesRoute сообщает, относится ли путь к рабочему процессу проекта
+// (/api/projects/{id}/statuses), который, как и рабочий процесс по умолчанию, токен может только читать.
+func projectStatusesRoute(path string) bool {
+	parts := strings.Split(strings.TrimPrefix(path, "/api/projects/"), "/")
+	return strings.HasPrefix(path, "/api/projects/") && len(parts) > 1 && parts[1] == "statuses"
+}
+
+// Функция RequireScope проверяет разрешения запроса, аутентифицированного токеном:
+// чтение (GET, HEAD) задач, корзины, проектов и меток требует tasks:read, их изменение - tasks:write.
+// Статусы (в том числе рабочие процессы проектов) и общие списки токен может только читать (tasks:read),
+// а остальные маршруты ему недоступны.
+// Запросы без нужного разрешения получают 403 Forbidden; запросам с сессией разрешено все.
+// Применяется внутри RequireUser.
+func RequireScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.TokenFrom(r.Context()); !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		read := r.Method == http.MethodGet || r.Method == http.MethodHead
+		switch {
+		case !read && projectStatusesRoute(r.URL.Path):
+			writeError(w, r, errSessionRequired)
+			return
+		case matchesRoute(r.URL.Path, taskScopeRoutes):
+		case read && matchesRoute(r.URL.Path, readOnlyScopeRoutes):
+		default:
+			writeError(w, r, errSessionRequired)
+			return
+		}
+
+		scope := db.ScopeTasksWrite
+		if read {
+			scope = db.ScopeTasksRead
+		}
+		if !auth.HasScope(r.Context(), scope) {
+			writeError(w, r, &scopeError{scope: scope})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Функция matchesRoute сообщает, совпадает ли путь с одним из маршрутов routes или вложен в него.
+func matchesRoute(path string, routes []string) bool {
+	for _, route := range routes {
+		if path == route || strings.HasPrefix(path, route+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Структура scopeError - ошибка запроса с токеном без разрешения scope.
+type scopeError struct {
+	scope string
+}
+
+func (e *scopeError) Error() string {
+	return errInsufficientScope.Error() + ": " + e.scope
+}
+
+func (e *scopeError) Unwrap() error {
+	return errInsufficientScope
+}
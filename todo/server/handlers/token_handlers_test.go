@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mr-Cheen1/todo_list/server/auth"
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для выпуска, получения и отзыва персональных токенов доступа.
+func TestTokenHandlers(t *testing.T) {
+	store := db.NewMemoryStore()
+	userID, err := store.CreateUser(db.User{Username: "alice"})
+	assert.NoError(t, err)
+	mux := http.NewServeMux()
+	h := NewAuthHandler(store)
+	mux.Handle("GET /api/tokens", requireSession(h.ListTokens))
+	mux.Handle("POST /api/tokens", requireSession(h.CreateToken))
+	mux.Handle("DELETE /api/tokens/{id}", requireSession(h.RevokeToken))
+
+	// serve выполняет запрос от имени пользователя alice, вошедшего по сессии.
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		ctx := auth.WithUser(context.Background(), db.User{ID: userID, Username: "alice"})
+		req, err := http.NewRequestWithContext(ctx, method, path, strings.NewReader(body))
+		assert.NoError(t, err)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve("POST", "/api/tokens", `{"name":"ci","scopes":["tasks:read","tasks:write"]}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var created db.APITokenDTO
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.Equal(t, "ci", created.Name)
+	assert.Equal(t, []string{db.ScopeTasksRead, db.ScopeTasksWrite}, created.Scopes)
+	assert.Len(t, created.Token, 43)
+	assert.Empty(t, created.LastUsedAt)
+
+	// Хранится только хеш токена.
+	user, token, err := store.GetTokenUser(auth.HashToken(created.Token), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, userID, user.ID)
+	assert.Equal(t, created.ID, token.ID)
+
+	rr = serve("GET", "/api/tokens", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var tokens []db.APITokenDTO
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &tokens))
+	if assert.Len(t, tokens, 1) {
+		assert.Empty(t, tokens[0].Token)
+		assert.NotEmpty(t, tokens[0].LastUsedAt)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/api/tokens", `{"name":"ci","scopes":["admin"]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("DELETE", "/api/tokens/abc", "").Code)
+	assert.Equal(t, http.StatusOK, serve("DELETE", "/api/tokens/1", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve("DELETE", "/api/tokens/1", "").Code)
+	assert.JSONEq(t, `[]`, serve("GET", "/api/tokens", "").Body.String())
+}
+
+// Тест для разрешений токена по маршрутам: tasks:write относится только к задачам, корзине,
+// проектам и меткам, а участниками списков и рабочими процессами (в том числе проектов)
+// можно управлять только после входа.
+func TestRequireScopeRoutes(t *testing.T) {
+	handler := RequireScope(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	token := db.APIToken{Scopes: []string{db.ScopeTasksRead, db.ScopeTasksWrite}}
+	tokenCtx := auth.WithToken(context.Background(), token)
+
+	testCases := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{method: "POST", path: "/api/tasks", status: http.StatusOK},
+		{method: "PATCH", path: "/api/tasks/1", status: http.StatusOK},
+		{method: "POST", path: "/api/tasks/1/restore", status: http.StatusOK},
+		{method: "GET", path: "/api/trash", status: http.StatusOK},
+		{method: "PUT", path: "/api/projects/3", status: http.StatusOK},
+		{method: "GET", path: "/api/tags", status: http.StatusOK},
+		{method: "GET", path: "/api/statuses", status: http.StatusOK},
+		{method: "GET", path: "/api/lists/1/members", status: http.StatusOK},
+		{method: "POST", path: "/api/statuses", status: http.StatusForbidden},
+		{method: "PUT", path: "/api/statuses/testing", status: http.StatusForbidden},
+		{method: "DELETE", path: "/api/statuses/returned", status: http.StatusForbidden},
+		{method: "GET", path: "/api/projects/3/statuses", status: http.StatusOK},
+		{method: "POST", path: "/api/projects/3/statuses", status: http.StatusForbidden},
+		{method: "PUT", path: "/api/projects/3/statuses/testing", status: http.StatusForbidden},
+		{method: "DELETE", path: "/api/projects/3/statuses/returned", status: http.StatusForbidden},
+		{method: "PUT", path: "/api/lists/1/members/bob", status: http.StatusForbidden},
+		{method: "DELETE", path: "/api/lists/1/members/bob", status: http.StatusForbidden},
+		{method: "POST", path: "/api/tasksx", status: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		req, err := http.NewRequestWithContext(tokenCtx, tc.method, tc.path, nil)
+		assert.NoError(t, err)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, tc.status, rr.Code, tc.method+" "+tc.path)
+	}
+
+	// Запросам с сессией разрешены все маршруты.
+	req, err := http.NewRequestWithContext(userCtx, "PUT", "/api/lists/1/members/bob", nil)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+// Тест для аутентификации по токену в заголовке Authorization и проверки его разрешений.
+func TestRequireUserWithToken(t *testing.T) {
+	store := db.NewMemoryStore()
+	userID, err := store.CreateUser(db.User{Username: "alice"})
+	assert.NoError(t, err)
+	// newToken сохраняет токен с указанными разрешениями и сроком действия и возвращает его.
+	newToken := func(expiresAt time.Time, scopes ...string) string {
+		token, hash, err := auth.NewToken()
+		assert.NoError(t, err)
+		_, err = store.CreateAPIToken(db.APIToken{UserID: userID, Name: "test", TokenHash: hash, Scopes: scopes,
+			ExpiresAt: expiresAt})
+		assert.NoError(t, err)
+		return token
+	}
+	readOnly := newToken(time.Now().Add(time.Hour), db.ScopeTasksRead)
+	expired := newToken(time.Now().Add(-time.Hour), db.ScopeTasksRead, db.ScopeTasksWrite)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ := auth.UserFrom(r.Context())
+		assert.Equal(t, userID, user.ID)
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := NewAuthHandler(store).RequireUser(RequireScope(next))
+
+	testCases := []struct {
+		name          string
+		method        string
+		authorization string
+		status        int
+	}{
+		{name: "Чтение с разрешением tasks:read", method: "GET", authorization: "Bearer " + readOnly, status: http.StatusOK},
+		{name: "Схема без учета регистра", method: "GET", authorization: "bearer " + readOnly, status: http.StatusOK},
+		{name: "Запись без разрешения tasks:write", method: "POST", authorization: "Bearer " + readOnly,
+			status: http.StatusForbidden},
+		{name: "Истекший токен", method: "GET", authorization: "Bearer " + expired, status: http.StatusUnauthorized},
+		{name: "Неизвестный токен", method: "GET", authorization: "Bearer unknown", status: http.StatusUnauthorized},
+		{name: "Другая схема", method: "GET", authorization: "Basic " + readOnly, status: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), tc.method, "/api/tasks", nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", tc.authorization)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.status, rr.Code)
+			if tc.status == http.StatusUnauthorized {
+				assert.Contains(t, rr.Header().Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+}
@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,40 +18,88 @@ import (
 )
 
 func main() {
+	// Тип хранилища задач задается флагом -store или переменной окружения TASK_STORE.
+	storeKind := flag.String("store", envOrDefault("TASK_STORE", "postgres"),
+		"task store: postgres, memory or DSN (postgres://..., sqlite://path/to/todo.db, memory://)")
+	staticDir := flag.String("static", envOrDefault("STATIC_DIR", "/app/static"), "directory with frontend files")
+	migrateOnStart := flag.Bool("migrate", envOrDefault("AUTO_MIGRATE", "true") == "true",
+		"apply pending schema migrations on startup")
+	// Срок хранения удаленных задач в корзине (по умолчанию 30 дней); 0 отключает очистку корзины.
+	defaultRetention, err := time.ParseDuration(envOrDefault("TRASH_RETENTION", "720h"))
+	if err != nil {
+		log.Fatalf("Invalid TRASH_RETENTION: %v", err)
+	}
+	trashRetention := flag.Duration("trash-retention", defaultRetention,
+		"how long deleted tasks are kept in the trash before purging (0 disables purging)")
+	// Администраторы сервера (идентификаторы пользователей через запятую) могут изменять рабочий процесс.
+	// Имена для этого не подходят: регистрация открыта, и занять имя администратора может любой.
+	adminUsers := flag.String("admins", os.Getenv("ADMIN_USER_IDS"),
+		"comma-separated user IDs of server administrators allowed to change the workflow")
+	// Пользователь, которому при запуске передаются задачи без автора, созданные до появления пользователей.
+	defaultLegacyOwner, err := strconv.ParseInt(envOrDefault("LEGACY_TASKS_OWNER_ID", "0"), 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid LEGACY_TASKS_OWNER_ID: %v", err)
+	}
+	legacyOwner := flag.Int64("legacy-owner", defaultLegacyOwner,
+		"ID of the user who gets the tasks created before user accounts existed (0 leaves them unassigned)")
+	flag.Parse()
+	admins, err := adminIDs(*adminUsers)
+	if err != nil {
+		log.Fatalf("Invalid ADMIN_USER_IDS: %v", err)
+	}
+
 	// Проверка аргументов командной строки.
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: go run server/main.go <address> <port>")
+	migrateCmd := flag.Arg(0) == "migrate"
+	if !migrateCmd && flag.NArg() < 2 {
+		fmt.Println("Usage: go run ./server [-store postgres|memory|<dsn>] [-static dir] [-migrate=false] " +
+			"[-trash-retention 720h] [-admins 1,2] [-legacy-owner 1] <address> <port>")
+		fmt.Println("       go run ./server [-store postgres|<dsn>] migrate [up|down [N]|status]")
 		os.Exit(1)
 	}
 
-	address := os.Args[1]
-	port := os.Args[2]
+	// Инициализация хранилища задач.
+	dsn := storeDSN(*storeKind)
+	store, closeStore, err := db.OpenStore(dsn)
+	if err != nil {
+		log.Fatalf("Failed to open task store: %v", err)
+	}
+
+	// Подкоманда migrate управляет схемой базы данных и завершает работу без запуска сервера.
+	if migrateCmd {
+		err = runMigrate(store, flag.Args()[1:], os.Stdout)
+		closeStore()
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
 
-	// Получение параметров подключения к базе данных из переменных окружения.
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
+	if *migrateOnStart {
+		if err := autoMigrate(store); err != nil {
+			closeStore()
+			log.Fatalf("Migration failed: %v", err)
+		}
+	}
+	if *legacyOwner != 0 {
+		claimed, err := store.ClaimOwnerlessTasks(*legacyOwner)
+		if err != nil {
+			closeStore()
+			log.Fatalf("Failed to assign ownerless tasks to user %d: %v", *legacyOwner, err)
+		}
+		log.Printf("Assigned %d ownerless tasks to user %d", claimed, *legacyOwner)
+	}
+	defer closeStore()
 
-	// Инициализация подключения к базе данных.
-	db.InitDB(dbHost, dbPort, dbUser, dbPassword, dbName)
-	defer db.CloseDB()
+	address := flag.Arg(0)
+	port := flag.Arg(1)
 
 	// Создание экземпляра сервера.
 	srv := &http.Server{
 		Addr:              fmt.Sprintf("%s:%s", address, port),
-		Handler:           nil,
+		Handler:           newRouter(store, *staticDir, admins),
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
-	// Регистрация обработчиков маршрутов.
-	http.Handle("/", http.FileServer(http.Dir("/app/static")))
-	http.HandleFunc("/api/tasks", handlers.GetTasks)
-	http.HandleFunc("/api/tasks/create", handlers.CreateTask)
-	http.HandleFunc("/api/tasks/update", handlers.UpdateTask)
-	http.HandleFunc("/api/tasks/delete", handlers.DeleteTask)
-
 	// Запуск сервера в отдельной горутине.
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -56,7 +107,16 @@ func main() {
 		}
 	}()
 
-	log.Printf("Server listening on %s:%s", address, port)
+	// Очистка корзины в фоне до завершения работы сервера.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if *trashRetention > 0 {
+		go purgeTrash(ctx, store, *trashRetention, trashPurgeInterval)
+	}
+
+	// В журнал выводится только схема DSN, чтобы не раскрывать пароль.
+	scheme, _, _ := strings.Cut(dsn, "://")
+	log.Printf("Server listening on %s:%s (store: %s)", address, port, scheme)
 
 	// Ожидание сигнала завершения и корректное завершение работы сервера.
 	if err := gracefulShutdown(srv); err != nil {
@@ -65,6 +125,72 @@ func main() {
 	}
 }
 
+// Функция newRouter регистрирует обработчики маршрутов. API задач и статусов доступно только
+// после входа пользователя или с персональным токеном доступа (в пределах его разрешений),
+// а статические файлы и маршруты аутентификации открыты всем. Изменять статусы рабочего процесса
+// могут только администраторы сервера admins (по идентификаторам пользователей).
+func newRouter(store db.TaskStore, staticDir string, admins []int64) http.Handler {
+	authHandler := handlers.NewAuthHandler(store)
+
+	api := http.NewServeMux()
+	handlers.NewTaskHandler(store).Register(api)
+	handlers.NewStatusHandler(store, admins).Register(api)
+	handlers.NewListHandler(store).Register(api)
+	handlers.NewProjectHandler(store).Register(api)
+	handlers.NewTagHandler(store).Register(api)
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.Dir(staticDir)))
+	authHandler.Register(mux)
+	mux.Handle("/api/", authHandler.RequireUser(handlers.RequireScope(handlers.Problems(api))))
+	return mux
+}
+
+// Функция storeDSN преобразует значение флага -store в DSN хранилища задач.
+// Короткие имена "postgres" и "memory" поддерживаются для совместимости,
+// любое другое значение считается DSN (postgres://, sqlite://, memory://).
+func storeDSN(kind string) string {
+	switch kind {
+	case "memory":
+		return "memory://"
+	case "postgres":
+		// Получение параметров подключения к базе данных из переменных окружения.
+		return db.PostgresDSN(
+			os.Getenv("DB_HOST"),
+			os.Getenv("DB_PORT"),
+			os.Getenv("DB_USER"),
+			os.Getenv("DB_PASSWORD"),
+			os.Getenv("DB_NAME"),
+		)
+	default:
+		return kind
+	}
+}
+
+// Функция adminIDs разбирает идентификаторы администраторов сервера, перечисленные через запятую.
+func adminIDs(value string) ([]int64, error) {
+	var ids []int64
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid user id %q", field)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Функция envOrDefault возвращает значение переменной окружения или значение по умолчанию.
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
 func gracefulShutdown(srv *http.Server) error {
 	// Ожидание сигнала завершения.
 	quit := make(chan os.Signal, 1)
@@ -6,49 +6,80 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Mr-Cheen1/todo_list/server/auth"
 	"github.com/Mr-Cheen1/todo_list/server/db"
 	"github.com/Mr-Cheen1/todo_list/server/handlers"
 	"github.com/stretchr/testify/assert"
 )
 
-func setupMockDB(t *testing.T) (sqlmock.Sqlmock, func()) {
+func setupMockDB(t *testing.T) (sqlmock.Sqlmock, db.TaskStore, func()) {
 	t.Helper() // Add this line to mark the function as a test helper
 	mockDB, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
-	db.DB = mockDB
-	return mock, func() { mockDB.Close() }
+	return mock, db.NewPostgresStore(mockDB), func() { mockDB.Close() }
 }
 
-func setupServer() *httptest.Server {
+// Пользователь, от имени которого выполняются запросы к серверу из setupServer.
+const testUserID int64 = 1
+
+// Колонки строк задач, которые возвращает хранилище PostgresStore.
+var taskRowColumns = []string{
+	"id", "task_text", "createdDate", "expectedDate", "status", "version", "project_id", "priority", "tags",
+}
+
+// Колонки строк задач, которые PostgresStore считывает с блокировкой перед изменением.
+var lockedRowColumns = append(slices.Clone(taskRowColumns), "owner_id", "deleted_at")
+
+// Функция setupServer создает сервер API задач и статусов. Запросы к нему выполняются от имени
+// пользователя testUserID, как после проверки сессии в RequireUser.
+func setupServer(store db.TaskStore) *httptest.Server {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/tasks/get", handlers.GetTasks)
-	mux.HandleFunc("/tasks/create", handlers.CreateTask)
-	mux.HandleFunc("/tasks/update", handlers.UpdateTask)
-	mux.HandleFunc("/tasks/delete", handlers.DeleteTask)
-	return httptest.NewServer(mux)
+	handlers.NewTaskHandler(store).Register(mux)
+	handlers.NewStatusHandler(store, []int64{testUserID}).Register(mux)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := db.User{ID: testUserID, Username: "alice"}
+		handlers.Problems(mux).ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), user)))
+	}))
+}
+
+// Функция expectWorkflow ожидает запрос статусов и возвращает встроенный рабочий процесс.
+func expectWorkflow(mock sqlmock.Sqlmock) {
+	rows := sqlmock.NewRows([]string{"id", "project_id", "name", "title", "position", "color", "terminal", "next"})
+	for _, def := range db.DefaultWorkflow().Statuses {
+		for _, next := range def.Transitions {
+			rows.AddRow(int(def.ID), 0, def.Name, def.Title, def.Position, def.Color, def.Terminal, next)
+		}
+	}
+	mock.ExpectQuery(`^SELECT (.+) FROM statuses s`).WillReturnRows(rows)
 }
 
 func TestGetTasks(t *testing.T) {
-	mock, teardown := setupMockDB(t)
+	mock, store, teardown := setupMockDB(t)
 	defer teardown()
 
 	fixedTime := time.Now()
-	rows := sqlmock.NewRows([]string{"id", "task_text", "createdDate", "expectedDate", "status"}).
-		AddRow(1, "Test Task", fixedTime, fixedTime.Add(24*time.Hour), db.StatusInProgress)
-	mock.ExpectQuery("^SELECT (.+) FROM tasks$").WillReturnRows(rows)
+	rows := sqlmock.NewRows(taskRowColumns).
+		AddRow(1, "Test Task", fixedTime, fixedTime.Add(24*time.Hour), db.StatusInProgress, 1, nil, 0, "")
+	expectWorkflow(mock)
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \$1 AND deleted_at IS NULL ` +
+		`ORDER BY priority DESC, expectedDate, id$`).
+		WithArgs(testUserID).
+		WillReturnRows(rows)
 
-	server := setupServer()
+	server := setupServer(store)
 	defer server.Close()
 
-	req, err := http.NewRequestWithContext(context.Background(), "GET", server.URL+"/tasks/get", nil)
+	req, err := http.NewRequestWithContext(context.Background(), "GET", server.URL+"/api/tasks", nil)
 	if err != nil {
 		t.Fatalf("could not create request: %v", err)
 	}
@@ -63,32 +94,42 @@ func TestGetTasks(t *testing.T) {
 	}
 	assert.Equal(t, 1, len(taskDTOs))
 	assert.Equal(t, "Test Task", taskDTOs[0].Text)
+	assert.Equal(t, db.StatusRef("in_progress"), taskDTOs[0].Status)
 }
 
 func TestCreateTask(t *testing.T) {
-	mock, teardown := setupMockDB(t)
+	mock, store, teardown := setupMockDB(t)
 	defer teardown()
 
 	createdDate := time.Now().Truncate(24 * time.Hour)
 	expectedDate := createdDate.AddDate(0, 0, 1)
+	expectWorkflow(mock)
+	mock.ExpectBegin()
 	mock.ExpectQuery(
-		"INSERT INTO tasks \\(task_text, createdDate, expectedDate, status\\) "+
-			"VALUES \\(\\$1, \\$2, \\$3, \\$4\\) RETURNING id",
+		"INSERT INTO tasks \\(task_text, createdDate, expectedDate, status, owner_id, project_id, priority\\) "+
+			"VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7\\) RETURNING id, version",
 	).
 		WithArgs(
 			"New Task",
 			createdDate.Format("2006-01-02"),
 			expectedDate.Format("2006-01-02"),
 			db.StatusInProgress,
+			testUserID,
+			nil,
+			db.PriorityNormal,
 		).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1))
+	mock.ExpectExec("INSERT INTO task_events").
+		WithArgs(int64(1), testUserID, testUserID, db.EventCreated, nil, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
-	server := setupServer()
+	server := setupServer(store)
 	defer server.Close()
 
 	newTaskDTO := db.TaskDTO{
 		Text:         "New Task",
-		Status:       db.StatusInProgress,
+		Status:       "in_progress",
 		CreatedDate:  createdDate.Format("2006-01-02"),
 		ExpectedDate: expectedDate.Format("2006-01-02"),
 	}
@@ -100,7 +141,7 @@ func TestCreateTask(t *testing.T) {
 	req, err := http.NewRequestWithContext(
 		context.Background(),
 		"POST",
-		server.URL+"/tasks/create",
+		server.URL+"/api/tasks",
 		bytes.NewBuffer(body),
 	)
 	if err != nil {
@@ -118,13 +159,13 @@ func TestCreateTask(t *testing.T) {
 		t.Fatalf("could not decode response: %v", err)
 	}
 	assert.Equal(t, "New Task", createdTask.Text)
-	assert.Equal(t, db.StatusInProgress, createdTask.Status)
+	assert.Equal(t, db.StatusRef("in_progress"), createdTask.Status)
 	assert.Equal(t, createdDate.Format("2006-01-02"), createdTask.CreatedDate)
 	assert.Equal(t, expectedDate.Format("2006-01-02"), createdTask.ExpectedDate)
 }
 
 func TestUpdateTask(t *testing.T) {
-	mock, teardown := setupMockDB(t)
+	mock, store, teardown := setupMockDB(t)
 	defer teardown()
 
 	fixedTime := time.Now()
@@ -135,31 +176,49 @@ func TestUpdateTask(t *testing.T) {
 		Text:         "Updated Task",
 		CreatedDate:  fixedTime.Format("2006-01-02"),
 		ExpectedDate: expectedTime.Format("2006-01-02"),
-		Status:       db.StatusInProgress,
+		Status:       "in_progress",
 	}
 
-	mock.ExpectExec(`UPDATE tasks SET task_text = \$1, createdDate = \$2, `+
-		`expectedDate = \$3, status = \$4 WHERE id = \$5`).
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL$`).
+		WithArgs(1, testUserID).
+		WillReturnRows(sqlmock.NewRows(taskRowColumns).
+			AddRow(1, "Task", fixedTime, expectedTime, db.StatusInProgress, 3, nil, 0, ""))
+	expectWorkflow(mock)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL `+
+		`ORDER BY id FOR UPDATE$`).
+		WithArgs(taskToUpdate.ID, testUserID).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns).
+			AddRow(1, "Task", fixedTime, expectedTime, db.StatusInProgress, 3, nil, 0, "", testUserID, nil))
+	mock.ExpectExec(`UPDATE tasks SET task_text = \$1, createdDate = \$2, expectedDate = \$3, status = \$4, `+
+		`project_id = \$5, priority = \$6, version = \$7 WHERE id = \$8`).
 		WithArgs(
 			taskToUpdate.Text,
 			taskToUpdate.CreatedDate,
 			taskToUpdate.ExpectedDate,
-			taskToUpdate.Status,
+			db.StatusInProgress,
+			nil,
+			db.PriorityNormal,
+			int64(4),
 			taskToUpdate.ID,
 		).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO task_events").
+		WithArgs(taskToUpdate.ID, testUserID, testUserID, db.EventUpdated, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
-	server := setupServer()
+	server := setupServer(store)
 	defer server.Close()
 
-	taskJSON := fmt.Sprintf(`{"id":%d,"text":"%s","status":%d,"createdDate":"%s","expectedDate":"%s"}`,
+	taskJSON := fmt.Sprintf(`{"id":%d,"text":"%s","status":%q,"createdDate":"%s","expectedDate":"%s"}`,
 		taskToUpdate.ID, taskToUpdate.Text, taskToUpdate.Status,
 		taskToUpdate.CreatedDate, taskToUpdate.ExpectedDate)
 
 	req, err := http.NewRequestWithContext(
 		context.Background(),
 		"PUT",
-		server.URL+"/tasks/update?id=1",
+		server.URL+"/api/tasks/1",
 		strings.NewReader(taskJSON),
 	)
 	if err != nil {
@@ -173,21 +232,31 @@ func TestUpdateTask(t *testing.T) {
 }
 
 func TestDeleteTask(t *testing.T) {
-	mock, teardown := setupMockDB(t)
+	mock, store, teardown := setupMockDB(t)
 	defer teardown()
 
 	taskIDToDelete := 1
-	mock.ExpectExec(`^DELETE FROM tasks WHERE id = \$1$`).
-		WithArgs(taskIDToDelete).
+	day := time.Date(2023, time.April, 4, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NULL `).
+		WithArgs(int64(taskIDToDelete), testUserID).
+		WillReturnRows(sqlmock.NewRows(lockedRowColumns).
+			AddRow(taskIDToDelete, "Task", day, day, db.StatusInProgress, 1, nil, 0, "", testUserID, nil))
+	mock.ExpectExec(`^UPDATE tasks SET deleted_at = \$1, version = version \+ 1 WHERE id = \$2$`).
+		WithArgs(sqlmock.AnyArg(), taskIDToDelete).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO task_events").
+		WithArgs(int64(taskIDToDelete), testUserID, testUserID, db.EventDeleted, nil, sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
-	server := setupServer()
+	server := setupServer(store)
 	defer server.Close()
 
 	req, err := http.NewRequestWithContext(
 		context.Background(),
 		"DELETE",
-		fmt.Sprintf("%s/tasks/delete?id=%d", server.URL, taskIDToDelete),
+		fmt.Sprintf("%s/api/tasks/%d", server.URL, taskIDToDelete),
 		nil,
 	)
 	if err != nil {
@@ -200,3 +269,313 @@ func TestDeleteTask(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 }
+
+func TestMemoryStoreRoundTrip(t *testing.T) {
+	server := setupServer(db.NewMemoryStore())
+	defer server.Close()
+
+	createdDate := time.Now().Truncate(24 * time.Hour)
+	body, err := json.Marshal(db.TaskDTO{
+		Text:         "Memory Task",
+		Status:       "in_progress",
+		CreatedDate:  createdDate.Format("2006-01-02"),
+		ExpectedDate: createdDate.AddDate(0, 0, 1).Format("2006-01-02"),
+	})
+	if err != nil {
+		t.Fatalf("could not marshal request body: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), "POST", server.URL+"/api/tasks", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("could not create request: %v", err)
+	}
+	w := httptest.NewRecorder()
+	server.Config.Handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "/api/tasks/1", w.Header().Get("Location"))
+
+	req, err = http.NewRequestWithContext(context.Background(), "GET", server.URL+"/api/tasks", nil)
+	if err != nil {
+		t.Fatalf("could not create request: %v", err)
+	}
+	w = httptest.NewRecorder()
+	server.Config.Handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var taskDTOs []db.TaskDTO
+	if err := json.NewDecoder(w.Body).Decode(&taskDTOs); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	assert.Equal(t, 1, len(taskDTOs))
+	assert.Equal(t, "Memory Task", taskDTOs[0].Text)
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	server := setupServer(db.NewMemoryStore())
+	defer server.Close()
+
+	testCases := []struct {
+		method string
+		path   string
+		allow  []string
+	}{
+		{method: "DELETE", path: "/api/tasks", allow: []string{"GET", "POST"}},
+		{method: "POST", path: "/api/tasks/1", allow: []string{"GET", "PUT", "DELETE"}},
+		{method: "POST", path: "/api/tasks/delete?id=1", allow: []string{"DELETE"}},
+	}
+
+	for _, tc := range testCases {
+		req, err := http.NewRequestWithContext(context.Background(), tc.method, server.URL+tc.path, nil)
+		if err != nil {
+			t.Fatalf("could not create request: %v", err)
+		}
+		w := httptest.NewRecorder()
+		server.Config.Handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code, tc.method+" "+tc.path)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `"type":"/problems/method-not-allowed"`)
+		for _, method := range tc.allow {
+			assert.Contains(t, w.Header().Get("Allow"), method, tc.method+" "+tc.path)
+		}
+	}
+}
+
+// Тест для неизвестного маршрута API: ServeMux отвечает 404 в формате application/problem+json.
+func TestRouteNotFound(t *testing.T) {
+	server := setupServer(db.NewMemoryStore())
+	defer server.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), "GET", server.URL+"/api/nope", nil)
+	if err != nil {
+		t.Fatalf("could not create request: %v", err)
+	}
+	w := httptest.NewRecorder()
+	server.Config.Handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	var problem handlers.Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, handlers.Problem{Type: "/problems/not-found", Title: "Not found", Status: http.StatusNotFound,
+		Detail: "The path does not match any API route", Instance: "/api/nope"}, problem)
+}
+
+func TestDeprecatedRoutes(t *testing.T) {
+	store := db.NewMemoryStore()
+	server := setupServer(store)
+	defer server.Close()
+
+	createdDate := time.Now().Truncate(24 * time.Hour).Format("2006-01-02")
+	taskJSON := fmt.Sprintf(`{"text":"Legacy","status":0,"createdDate":"%s","expectedDate":"%s"}`,
+		createdDate, createdDate)
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+		code   int
+	}{
+		{method: "POST", path: "/api/tasks/create", body: taskJSON, code: http.StatusCreated},
+		{method: "PUT", path: "/api/tasks/update?id=1", body: taskJSON, code: http.StatusOK},
+		{method: "DELETE", path: "/api/tasks/delete?id=1", code: http.StatusOK},
+	}
+
+	for _, r := range requests {
+		req, err := http.NewRequestWithContext(context.Background(), r.method, server.URL+r.path, strings.NewReader(r.body))
+		if err != nil {
+			t.Fatalf("could not create request: %v", err)
+		}
+		w := httptest.NewRecorder()
+		server.Config.Handler.ServeHTTP(w, req)
+
+		assert.Equal(t, r.code, w.Code, r.path)
+		assert.Equal(t, "true", w.Header().Get("Deprecation"), r.path)
+		assert.Contains(t, w.Header().Get("Link"), "successor-version", r.path)
+	}
+
+	page, err := store.GetAllTasks(db.TaskQuery{Owner: testUserID})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Tasks)
+}
+
+// Тест для защиты API: без входа API задач недоступно, созданная после входа задача
+// записывается на текущего пользователя, а другим пользователям она не видна.
+func TestAuthRequired(t *testing.T) {
+	store := db.NewMemoryStore()
+	server := httptest.NewServer(newRouter(store, t.TempDir(), nil))
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	assert.NoError(t, err)
+	client := &http.Client{Jar: jar}
+
+	for _, path := range []string{"/api/tasks", "/api/tasks/1", "/api/trash", "/api/statuses"} {
+		resp, err := client.Get(server.URL + path)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, path)
+	}
+
+	resp, err := client.Post(server.URL+"/api/auth/register", "application/json",
+		strings.NewReader(`{"username":"alice","password":"password"}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, err = client.Post(server.URL+"/api/tasks", "application/json",
+		strings.NewReader(`{"text":"Owned","createdDate":"2023-10-01","expectedDate":"2023-10-02"}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	user, err := store.GetUserByName("alice")
+	assert.NoError(t, err)
+	task, err := store.GetTaskByID(user.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, task.OwnerID)
+
+	resp, err = client.Get(server.URL + "/api/tasks")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	bobJar, err := cookiejar.New(nil)
+	assert.NoError(t, err)
+	bob := &http.Client{Jar: bobJar}
+	resp, err = bob.Post(server.URL+"/api/auth/register", "application/json",
+		strings.NewReader(`{"username":"bob","password":"password"}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, err = bob.Get(server.URL + "/api/tasks/1")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+// Тест для администраторов сервера: они задаются идентификаторами пользователей, поэтому
+// зарегистрированное кем-то другим имя администратора (в том числе в другом регистре)
+// не дает права изменять рабочий процесс.
+func TestStatusAdminByID(t *testing.T) {
+	store := db.NewMemoryStore()
+	server := httptest.NewServer(newRouter(store, t.TempDir(), []int64{1}))
+	defer server.Close()
+
+	register := func(username string) (*http.Client, int) {
+		jar, err := cookiejar.New(nil)
+		assert.NoError(t, err)
+		client := &http.Client{Jar: jar}
+		resp, err := client.Post(server.URL+"/api/auth/register", "application/json",
+			strings.NewReader(`{"username":"`+username+`","password":"password"}`))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return client, resp.StatusCode
+	}
+	createStatus := func(client *http.Client, name string) int {
+		resp, err := client.Post(server.URL+"/api/statuses", "application/json",
+			strings.NewReader(`{"name":"`+name+`","title":"Заблокировано","color":"#f44336"}`))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	root, code := register("root")
+	assert.Equal(t, http.StatusCreated, code)
+	mallory, code := register("admin")
+	assert.Equal(t, http.StatusCreated, code)
+	_, code = register("Root")
+	assert.Equal(t, http.StatusConflict, code)
+
+	assert.Equal(t, http.StatusForbidden, createStatus(mallory, "blocked"))
+	assert.Equal(t, http.StatusCreated, createStatus(root, "blocked"))
+}
+
+// Тест для персональных токенов доступа: токен работает в пределах своих разрешений,
+// не управляет токенами и перестает действовать после отзыва.
+func TestAPITokens(t *testing.T) {
+	server := httptest.NewServer(newRouter(db.NewMemoryStore(), t.TempDir(), nil))
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	assert.NoError(t, err)
+	client := &http.Client{Jar: jar}
+	resp, err := client.Post(server.URL+"/api/auth/register", "application/json",
+		strings.NewReader(`{"username":"alice","password":"password"}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	resp, err = client.Post(server.URL+"/api/tokens", "application/json",
+		strings.NewReader(`{"name":"ci","scopes":["tasks:read"]}`))
+	assert.NoError(t, err)
+	var created db.APITokenDTO
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// bearer выполняет запрос с токеном в заголовке Authorization и возвращает код ответа.
+	bearer := func(method, path, body string) int {
+		req, err := http.NewRequestWithContext(context.Background(), method, server.URL+path, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+created.Token)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, bearer("GET", "/api/tasks", ""))
+	assert.Equal(t, http.StatusOK, bearer("GET", "/api/auth/me", ""))
+	assert.Equal(t, http.StatusForbidden, bearer("POST", "/api/tasks",
+		`{"text":"Task","createdDate":"2023-10-01","expectedDate":"2023-10-02"}`))
+	assert.Equal(t, http.StatusForbidden, bearer("GET", "/api/tokens", ""))
+
+	req, err := http.NewRequestWithContext(context.Background(), "DELETE",
+		fmt.Sprintf("%s/api/tokens/%d", server.URL, created.ID), nil)
+	assert.NoError(t, err)
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, bearer("GET", "/api/tasks", ""))
+}
+
+// Тест для общего списка задач: наблюдатель видит задачи автора, но не может их удалить.
+func TestSharedList(t *testing.T) {
+	store := db.NewMemoryStore()
+	server := httptest.NewServer(newRouter(store, t.TempDir(), nil))
+	defer server.Close()
+
+	// register регистрирует пользователя и возвращает клиент с его сессией.
+	register := func(name string) *http.Client {
+		jar, err := cookiejar.New(nil)
+		assert.NoError(t, err)
+		client := &http.Client{Jar: jar}
+		resp, err := client.Post(server.URL+"/api/auth/register", "application/json",
+			strings.NewReader(fmt.Sprintf(`{"username":%q,"password":"password"}`, name)))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return client
+	}
+	// do выполняет запрос клиента и возвращает код ответа.
+	do := func(client *http.Client, method, path, body string) int {
+		req, err := http.NewRequestWithContext(context.Background(), method, server.URL+path, strings.NewReader(body))
+		assert.NoError(t, err)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	alice, bob := register("alice"), register("bob")
+
+	assert.Equal(t, http.StatusCreated, do(alice, "POST", "/api/tasks",
+		`{"text":"Shared","createdDate":"2023-10-01","expectedDate":"2023-10-02"}`))
+	assert.Equal(t, http.StatusNotFound, do(bob, "GET", "/api/tasks?list=1", ""))
+	assert.Equal(t, http.StatusOK, do(alice, "PUT", "/api/lists/1/members/bob", `{"role":"viewer"}`))
+
+	assert.Equal(t, http.StatusOK, do(bob, "GET", "/api/tasks/1?list=1", ""))
+	assert.Equal(t, http.StatusForbidden, do(bob, "DELETE", "/api/tasks/delete?id=1&list=1", ""))
+	_, err := store.GetTaskByID(1, 1)
+	assert.NoError(t, err)
+}
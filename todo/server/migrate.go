@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+)
+
+// Ошибка для хранилищ без схемы (например, хранилища в памяти).
+var errNotMigratable = errors.New("task store does not support migrations")
+
+// Функция autoMigrate применяет недостающие миграции при запуске сервера.
+// Хранилища без схемы пропускаются.
+func autoMigrate(store db.TaskStore) error {
+	migratable, ok := store.(db.Migratable)
+	if !ok {
+		return nil
+	}
+
+	applied, err := migratable.Migrator().Up()
+	for _, m := range applied {
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+	}
+	return err
+}
+
+// Функция runMigrate выполняет подкоманду migrate: up, down [N] или status.
+func runMigrate(store db.TaskStore, args []string, out io.Writer) error {
+	migratable, ok := store.(db.Migratable)
+	if !ok {
+		return errNotMigratable
+	}
+	migrator := migratable.Migrator()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			fmt.Fprintf(out, "applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(out, "schema is up to date")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of steps: %s", args[1])
+			}
+			steps = n
+		}
+		reverted, err := migrator.Down(steps)
+		for _, m := range reverted {
+			fmt.Fprintf(out, "reverted %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%04d_%s\t%s\n", s.Version, s.Name, state)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command: %s (expected up, down [N] or status)", command)
+	}
+}
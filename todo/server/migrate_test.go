@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunMigrate(t *testing.T) {
+	store, closeStore, err := db.OpenStore("sqlite://" + filepath.Join(t.TempDir(), "todo.db"))
+	if err != nil {
+		t.Fatalf("could not open store: %v", err)
+	}
+	defer closeStore()
+
+	var out bytes.Buffer
+	assert.NoError(t, runMigrate(store, []string{"status"}, &out))
+	assert.Contains(t, out.String(), "0001_create_tasks\tapplied")
+
+	out.Reset()
+	assert.NoError(t, runMigrate(store, []string{"down", "1000"}, &out))
+	assert.Contains(t, out.String(), "reverted 0001_create_tasks")
+
+	out.Reset()
+	assert.NoError(t, runMigrate(store, nil, &out))
+	assert.Contains(t, out.String(), "applied 0001_create_tasks")
+
+	assert.Error(t, runMigrate(store, []string{"down", "zero"}, &out))
+	assert.Error(t, runMigrate(store, []string{"sideways"}, &out))
+}
+
+func TestRunMigrateMemoryStore(t *testing.T) {
+	var out bytes.Buffer
+	assert.ErrorIs(t, runMigrate(db.NewMemoryStore(), nil, &out), errNotMigratable)
+	assert.NoError(t, autoMigrate(db.NewMemoryStore()))
+}
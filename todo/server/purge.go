@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+)
+
+// Интервал между запусками очистки корзины.
+const trashPurgeInterval = time.Hour
+
+// Функция purgeTrash окончательно удаляет задачи, пролежавшие в корзине дольше retention:
+// сразу при запуске и затем раз в interval, пока не будет отменен ctx.
+func purgeTrash(ctx context.Context, store db.TaskStore, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purgeExpired(store, retention, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Функция purgeExpired удаляет из корзины задачи, удаленные раньше now - retention.
+// Ошибка только записывается в журнал: очистка повторится при следующем запуске.
+func purgeExpired(store db.TaskStore, retention time.Duration, now time.Time) {
+	purged, err := store.PurgeDeletedTasks(now.Add(-retention))
+	if err != nil {
+		log.Printf("Failed to purge trash: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Purged %d tasks from trash", purged)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для очистки корзины: удаляются только задачи, пролежавшие в корзине дольше срока хранения.
+func TestPurgeExpired(t *testing.T) {
+	store := db.NewMemoryStore()
+	kept, _ := store.CreateTask(db.Task{OwnerID: testUserID, Text: "kept"})
+	deleted, _ := store.CreateTask(db.Task{OwnerID: testUserID, Text: "deleted"})
+	assert.NoError(t, store.DeleteTask(testUserID, testUserID, int(deleted), 0))
+
+	// Срок хранения еще не истек.
+	purgeExpired(store, time.Hour, time.Now())
+	page, _ := store.GetAllTasks(db.TaskQuery{Owner: testUserID, Deleted: true})
+	assert.Len(t, page.Tasks, 1)
+
+	purgeExpired(store, time.Hour, time.Now().Add(2*time.Hour))
+	page, _ = store.GetAllTasks(db.TaskQuery{Owner: testUserID, Deleted: true})
+	assert.Empty(t, page.Tasks)
+
+	_, err := store.GetTaskByID(testUserID, int(kept))
+	assert.NoError(t, err)
+}
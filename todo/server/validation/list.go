@@ -0,0 +1,20 @@
+package validation
+
+import "github.com/Mr-Cheen1/todo_list/server/db"
+
+// Функция NewListMember проверяет запрос на открытие списка задач пользователю и возвращает его роль.
+func NewListMember(req db.ListMemberRequest) (db.Role, error) {
+	var errs Errors
+
+	switch {
+	case req.Role == "":
+		errs.add("role", CodeRequired, "Role is required")
+	case !req.Role.Valid():
+		errs.add("role", CodeInvalid, "Role must be one of: viewer, editor, admin")
+	}
+
+	if len(errs) > 0 {
+		return "", errs
+	}
+	return req.Role, nil
+}
@@ -0,0 +1,39 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для проверки роли при открытии списка задач пользователю.
+func TestNewListMember(t *testing.T) {
+	testCases := []struct {
+		name     string
+		role     db.Role
+		expected Errors
+	}{
+		{name: "Наблюдатель", role: db.RoleViewer},
+		{name: "Администратор", role: db.RoleAdmin},
+		{name: "Нет роли", expected: Errors{{Field: "role", Code: CodeRequired, Message: "Role is required"}}},
+		{name: "Неизвестная роль", role: "owner", expected: Errors{
+			{Field: "role", Code: CodeInvalid, Message: "Role must be one of: viewer, editor, admin"},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			role, err := NewListMember(db.ListMemberRequest{Role: tc.role})
+			if tc.expected == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.role, role)
+				return
+			}
+			var errs Errors
+			assert.True(t, errors.As(err, &errs))
+			assert.Equal(t, tc.expected, errs)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+)
+
+// Максимальная длина имени проекта в символах (соответствует колонке name VARCHAR(64)).
+const MaxProjectNameLength = 64
+
+// Функция NewProject проверяет запрос на создание или переименование проекта.
+// Имя обрезается по краям; занятость имени проверяет хранилище (db.ErrProjectExists).
+func NewProject(req db.ProjectRequest) (db.Project, error) {
+	var errs Errors
+	name := strings.TrimSpace(req.Name)
+
+	switch {
+	case name == "":
+		errs.add("name", CodeRequired, "Project name is required")
+	case utf8.RuneCountInString(name) > MaxProjectNameLength:
+		errs.add("name", CodeTooLong, "Project name cannot exceed 64 characters")
+	}
+
+	if len(errs) > 0 {
+		return db.Project{}, errs
+	}
+	return db.Project{Name: name}, nil
+}
+
+// Функция UnknownProject возвращает нарушение для задачи, проект которой не найден в списке задач.
+func UnknownProject() error {
+	var errs Errors
+	errs.add("projectId", CodeInvalid, "Project does not exist")
+	return errs
+}
@@ -0,0 +1,43 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для проверки имени проекта.
+func TestNewProject(t *testing.T) {
+	testCases := []struct {
+		name     string
+		project  string
+		expected Errors
+	}{
+		{name: "Корректное имя", project: "Работа"},
+		{name: "Имя обрезается по краям", project: "  Работа  "},
+		{name: "Длина в символах, а не в байтах", project: strings.Repeat("я", 64)},
+		{name: "Пустое имя", project: "   ", expected: Errors{
+			{Field: "name", Code: CodeRequired, Message: "Project name is required"},
+		}},
+		{name: "Длинное имя", project: strings.Repeat("a", 65), expected: Errors{
+			{Field: "name", Code: CodeTooLong, Message: "Project name cannot exceed 64 characters"},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			project, err := NewProject(db.ProjectRequest{Name: tc.project})
+			if tc.expected == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, strings.TrimSpace(tc.project), project.Name)
+				return
+			}
+			var errs Errors
+			assert.True(t, errors.As(err, &errs))
+			assert.Equal(t, tc.expected, errs)
+		})
+	}
+}
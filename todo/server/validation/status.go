@@ -0,0 +1,81 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+)
+
+// Максимальная длина названия статуса в символах (соответствует колонке statuses.title).
+const MaxStatusTitleLength = 50
+
+var (
+	// Имя статуса - идентификатор в API: строчные латинские буквы, цифры и "_", не длиннее 32 символов.
+	statusNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+	// Цвет статуса в формате #rrggbb.
+	colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+// Функция NewStatus проверяет новый статус рабочего процесса workflow.
+// Занятость имени проверяет хранилище (db.ErrStatusExists).
+func NewStatus(workflow db.Workflow, def db.StatusDef) (db.StatusDef, error) {
+	def, errs := checkStatus(workflow, def)
+	if !statusNamePattern.MatchString(def.Name) {
+		errs.add("name", CodeInvalid,
+			"Status name must start with a letter and contain only a-z, 0-9 and _ (up to 32 characters)")
+	}
+	return statusResult(def, errs)
+}
+
+// Функция UpdatedStatus проверяет новое состояние существующего статуса рабочего процесса workflow.
+// Имя статуса не проверяется: оно неизменно и берется из пути запроса.
+func UpdatedStatus(workflow db.Workflow, def db.StatusDef) (db.StatusDef, error) {
+	def, errs := checkStatus(workflow, def)
+	return statusResult(def, errs)
+}
+
+// Функция checkStatus проверяет название, цвет и переходы статуса и собирает все нарушения.
+// Название обрезается по краям, а повторяющиеся переходы удаляются.
+func checkStatus(workflow db.Workflow, def db.StatusDef) (db.StatusDef, Errors) {
+	var errs Errors
+
+	def.Title = strings.TrimSpace(def.Title)
+	switch {
+	case def.Title == "":
+		errs.add("title", CodeRequired, "Status title cannot be empty")
+	case utf8.RuneCountInString(def.Title) > MaxStatusTitleLength:
+		errs.add("title", CodeTooLong, fmt.Sprintf("Status title cannot exceed %d characters", MaxStatusTitleLength))
+	}
+
+	if !colorPattern.MatchString(def.Color) {
+		errs.add("color", CodeInvalid, "Status color must be in #rrggbb format")
+	}
+
+	transitions := []string{}
+	for _, name := range def.Transitions {
+		next, ok := workflow.Lookup(name)
+		switch {
+		case !ok || next.Name != name:
+			errs.add("transitions", CodeInvalid, fmt.Sprintf("Unknown status %q in transitions", name))
+		case name == def.Name:
+			errs.add("transitions", CodeInvalid, "Status cannot transition to itself")
+		case !slices.Contains(transitions, name):
+			transitions = append(transitions, name)
+		}
+	}
+	def.Transitions = transitions
+
+	return def, errs
+}
+
+// Функция statusResult возвращает статус или, если найдены нарушения, ошибку со всеми ними.
+func statusResult(def db.StatusDef, errs Errors) (db.StatusDef, error) {
+	if len(errs) > 0 {
+		return db.StatusDef{}, errs
+	}
+	return def, nil
+}
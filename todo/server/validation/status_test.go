@@ -0,0 +1,66 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для проверки нового статуса рабочего процесса.
+func TestNewStatus(t *testing.T) {
+	valid := db.StatusDef{Name: "blocked", Title: "Заблокировано", Position: 15, Color: "#F44336",
+		Transitions: []string{"in_progress"}}
+
+	testCases := []struct {
+		name     string
+		modify   func(def *db.StatusDef)
+		expected Errors
+	}{
+		{name: "Корректный статус", modify: func(*db.StatusDef) {}},
+		{name: "Неверное имя", modify: func(def *db.StatusDef) { def.Name = "In Review" }, expected: Errors{
+			{Field: "name", Code: CodeInvalid,
+				Message: "Status name must start with a letter and contain only a-z, 0-9 and _ (up to 32 characters)"},
+		}},
+		{name: "Пустое название и неверный цвет", modify: func(def *db.StatusDef) { def.Title, def.Color = " ", "red" },
+			expected: Errors{
+				{Field: "title", Code: CodeRequired, Message: "Status title cannot be empty"},
+				{Field: "color", Code: CodeInvalid, Message: "Status color must be in #rrggbb format"},
+			}},
+		{name: "Неизвестный переход", modify: func(def *db.StatusDef) { def.Transitions = []string{"done", "0"} },
+			expected: Errors{
+				{Field: "transitions", Code: CodeInvalid, Message: `Unknown status "done" in transitions`},
+				{Field: "transitions", Code: CodeInvalid, Message: `Unknown status "0" in transitions`},
+			}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			def := valid
+			tc.modify(&def)
+
+			_, err := NewStatus(db.DefaultWorkflow(), def)
+			if tc.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var errs Errors
+			assert.True(t, errors.As(err, &errs))
+			assert.Equal(t, tc.expected, errs)
+		})
+	}
+}
+
+// Тест для проверки изменения статуса: повторяющиеся переходы удаляются, переход в себя запрещен.
+func TestUpdatedStatus(t *testing.T) {
+	def, err := UpdatedStatus(db.DefaultWorkflow(), db.StatusDef{Name: "testing", Title: " Проверка ", Color: "#ff9800",
+		Transitions: []string{"completed", "returned", "completed"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Проверка", def.Title)
+	assert.Equal(t, []string{"completed", "returned"}, def.Transitions)
+
+	_, err = UpdatedStatus(db.DefaultWorkflow(), db.StatusDef{Name: "testing", Title: "Проверка", Color: "#ff9800",
+		Transitions: []string{"testing"}})
+	assert.EqualError(t, err, "Status cannot transition to itself")
+}
@@ -0,0 +1,59 @@
+package validation
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Ограничения меток задачи: длина имени в символах (соответствует колонке name VARCHAR(32))
+// и количество меток у одной задачи.
+const (
+	MaxTagLength = 32
+	MaxTags      = 20
+)
+
+// Функция NormalizeTag приводит имя метки к виду, в котором оно хранится.
+// Метки не зависят от регистра, поэтому "Bug" и "bug" - одна метка.
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Функция checkTags проверяет метки задачи и возвращает их нормализованными, без повторов и в порядке имен.
+// Запятая в имени запрещена: через нее метки перечисляются в фильтре списка задач.
+func checkTags(errs *Errors, field string, names []string) []string {
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		tag := NormalizeTag(name)
+		switch {
+		case tag == "":
+			errs.add(field, CodeRequired, "Tag cannot be empty")
+			return nil
+		case utf8.RuneCountInString(tag) > MaxTagLength:
+			errs.add(field, CodeTooLong, "Tag cannot exceed 32 characters")
+			return nil
+		case strings.Contains(tag, ","):
+			errs.add(field, CodeInvalid, "Tag cannot contain commas")
+			return nil
+		}
+		tags = append(tags, tag)
+	}
+
+	slices.Sort(tags)
+	tags = slices.Compact(tags)
+	if len(tags) > MaxTags {
+		errs.add(field, CodeTooLong, "Task cannot have more than 20 tags")
+		return nil
+	}
+	return tags
+}
+
+// Функция TagFilter разбирает фильтр списка задач по меткам - имена через запятую (напр. "bug,ui").
+func TagFilter(value string) ([]string, error) {
+	var errs Errors
+	tags := checkTags(&errs, "tags", strings.Split(value, ","))
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return tags, nil
+}
@@ -0,0 +1,52 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для проверки меток задачи и фильтра по меткам.
+func TestTagFilter(t *testing.T) {
+	many := make([]string, 0, MaxTags+1)
+	for i := 0; i <= MaxTags; i++ {
+		many = append(many, fmt.Sprint("tag", i))
+	}
+
+	testCases := []struct {
+		name     string
+		filter   string
+		tags     []string
+		expected Errors
+	}{
+		{name: "Одна метка", filter: "bug", tags: []string{"bug"}},
+		{name: "Метки нормализуются, повторы удаляются", filter: " UI ,bug,ui", tags: []string{"bug", "ui"}},
+		{name: "Длина в символах, а не в байтах", filter: strings.Repeat("я", 32), tags: []string{strings.Repeat("я", 32)}},
+		{name: "Пустая метка", filter: "bug,,ui", expected: Errors{
+			{Field: "tags", Code: CodeRequired, Message: "Tag cannot be empty"},
+		}},
+		{name: "Длинная метка", filter: strings.Repeat("a", 33), expected: Errors{
+			{Field: "tags", Code: CodeTooLong, Message: "Tag cannot exceed 32 characters"},
+		}},
+		{name: "Слишком много меток", filter: strings.Join(many, ","), expected: Errors{
+			{Field: "tags", Code: CodeTooLong, Message: "Task cannot have more than 20 tags"},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tags, err := TagFilter(tc.filter)
+			if tc.expected == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.tags, tags)
+				return
+			}
+			var errs Errors
+			assert.True(t, errors.As(err, &errs))
+			assert.Equal(t, tc.expected, errs)
+		})
+	}
+}
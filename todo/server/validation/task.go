@@ -0,0 +1,190 @@
+// Пакет validation содержит проверки входных данных API.
+// Проверки собирают все нарушения сразу, чтобы клиент мог подсветить каждое неверное поле.
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+)
+
+// Максимальная длина текста задачи в символах (соответствует колонке task_text VARCHAR(255)).
+const MaxTextLength = 255
+
+// Максимальная длина причины смены статуса в символах.
+const MaxReasonLength = 255
+
+// Формат дат задачи в API.
+const dateLayout = "2006-01-02"
+
+// Коды нарушений, по которым клиент может определить тип ошибки без разбора текста сообщения.
+const (
+	CodeRequired  = "required"
+	CodeTooShort  = "too_short"
+	CodeTooLong   = "too_long"
+	CodeInvalid   = "invalid"
+	CodeDateOrder = "date_order"
+	// CodeTransition - недопустимая смена статуса задачи.
+	CodeTransition = "invalid_transition"
+)
+
+// Структура FieldError описывает нарушение в одном поле задачи.
+// Field совпадает с именем поля в JSON, чтобы клиент мог сопоставить ошибку с полем ввода.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Тип Errors - список нарушений, найденных при проверке.
+type Errors []FieldError
+
+// Метод Error объединяет сообщения всех нарушений в одну строку.
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Метод add добавляет нарушение в список.
+func (e *Errors) add(field, code, message string) {
+	*e = append(*e, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Метод has сообщает, есть ли в списке нарушение в поле field.
+func (e Errors) has(field string) bool {
+	for _, fieldErr := range e {
+		if fieldErr.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Функция NewTask проверяет новую задачу из запроса и преобразует её в db.Task.
+// Статус задачи разрешается по рабочему процессу её проекта из workflows: новая задача может иметь
+// любой его статус, поскольку переходы проверяются только при изменении задачи. Если статус
+// не указан, задача получает начальный статус.
+func NewTask(workflows db.Workflows, dto db.TaskDTO) (db.Task, error) {
+	if initial, ok := workflows.For(dtoProject(dto)).Initial(); ok && dto.Status == "" {
+		dto.Status = db.StatusRef(initial.Name)
+	}
+	return result(check(workflows, dto))
+}
+
+// Функция UpdatedTask проверяет новое состояние задачи current из запроса и преобразует его в db.Task.
+// Помимо полей проверяется, что смена статуса разрешена переходами рабочего процесса проекта задачи.
+// Если задача переносится в проект с другим рабочим процессом, переходы не проверяются: как и новая
+// задача, она может получить любой статус нового рабочего процесса. Если приоритет не указан, задача
+// сохраняет текущий приоритет. Причина (reason) обрезается по краям и допускается только вместе
+// со сменой статуса.
+func UpdatedTask(workflows db.Workflows, current db.Task, dto db.TaskDTO) (db.Task, error) {
+	task, errs := check(workflows, dto)
+	if dto.Priority == "" {
+		task.Priority = current.Priority
+	}
+	workflow := workflows.For(task.ProjectID)
+	sameWorkflow := workflows.Scope(task.ProjectID) == workflows.Scope(current.ProjectID)
+	if !errs.has("status") && sameWorkflow && !workflow.CanTransition(current.Status, task.Status) {
+		from, _ := workflow.ByID(current.Status)
+		errs.add("status", CodeTransition, fmt.Sprintf("Cannot change task status from %s to %s (allowed: %s)",
+			workflow.Name(current.Status), workflow.Name(task.Status), strings.Join(from.Transitions, ", ")))
+	}
+
+	task.Reason = strings.TrimSpace(dto.Reason)
+	switch {
+	case utf8.RuneCountInString(task.Reason) > MaxReasonLength:
+		errs.add("reason", CodeTooLong, fmt.Sprintf("Reason cannot exceed %d characters", MaxReasonLength))
+	case task.Reason != "" && !errs.has("status") && task.Status == current.Status:
+		errs.add("reason", CodeInvalid, "Reason can only be given when the task status changes")
+	}
+	return result(task, errs)
+}
+
+// Функция dtoProject возвращает ID проекта задачи из запроса (0 - без проекта).
+func dtoProject(dto db.TaskDTO) int64 {
+	if dto.ProjectID == nil {
+		return 0
+	}
+	return *dto.ProjectID
+}
+
+// Функция check проверяет поля задачи и собирает все нарушения.
+// Текст задачи обрезается по краям, а статус ищется в рабочем процессе проекта задачи.
+func check(workflows db.Workflows, dto db.TaskDTO) (db.Task, Errors) {
+	var errs Errors
+	task := db.Task{
+		ID:      dto.ID,
+		Text:    strings.TrimSpace(dto.Text),
+		Version: dto.Version,
+	}
+
+	switch {
+	case task.Text == "":
+		errs.add("text", CodeRequired, "Task text cannot be empty")
+	case utf8.RuneCountInString(task.Text) > MaxTextLength:
+		errs.add("text", CodeTooLong, "Task text cannot exceed 255 characters")
+	}
+
+	task.CreatedDate = parseDate(&errs, "createdDate", dto.CreatedDate, "Task created date")
+	task.ExpectedDate = parseDate(&errs, "expectedDate", dto.ExpectedDate, "Task expected date")
+	if !task.CreatedDate.IsZero() && !task.ExpectedDate.IsZero() && task.ExpectedDate.Before(task.CreatedDate) {
+		errs.add("expectedDate", CodeDateOrder, "Expected date cannot be earlier than created date")
+	}
+
+	if status, ok := workflows.For(dtoProject(dto)).Lookup(string(dto.Status)); ok {
+		task.Status = status.ID
+	} else {
+		errs.add("status", CodeInvalid, "Incorrect task status")
+	}
+
+	// Существование проекта проверяет обработчик по хранилищу (UnknownProject).
+	if dto.ProjectID != nil {
+		if task.ProjectID = *dto.ProjectID; task.ProjectID <= 0 {
+			errs.add("projectId", CodeInvalid, "Project ID must be a positive number")
+		}
+	}
+
+	// Без поля priority задача получает обычный приоритет.
+	if dto.Priority != "" {
+		if priority, ok := db.ParsePriority(dto.Priority); ok {
+			task.Priority = priority
+		} else {
+			errs.add("priority", CodeInvalid,
+				"Priority must be one of "+strings.Join(db.PriorityNames(), ", "))
+		}
+	}
+
+	// Без поля tags метки остаются nil: при обновлении это означает, что метки задачи не изменяются.
+	if dto.Tags != nil {
+		task.Tags = checkTags(&errs, "tags", dto.Tags)
+	}
+	return task, errs
+}
+
+// Функция result возвращает задачу или, если найдены нарушения, ошибку со всеми ними.
+func result(task db.Task, errs Errors) (db.Task, error) {
+	if len(errs) > 0 {
+		return db.Task{}, errs
+	}
+	return task, nil
+}
+
+// Функция parseDate разбирает дату поля задачи, добавляя нарушение, если дата не указана или некорректна.
+func parseDate(errs *Errors, field, value, name string) time.Time {
+	if value == "" {
+		errs.add(field, CodeRequired, name+" is required")
+		return time.Time{}
+	}
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		errs.add(field, CodeInvalid, name+" must be in YYYY-MM-DD format")
+		return time.Time{}
+	}
+	return date
+}
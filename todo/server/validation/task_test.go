@@ -0,0 +1,230 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для проверки полей новой задачи.
+func TestNewTask(t *testing.T) {
+	valid := db.TaskDTO{Text: "Task", CreatedDate: "2023-10-01", ExpectedDate: "2023-10-02", Status: "in_progress"}
+
+	testCases := []struct {
+		name     string
+		modify   func(dto *db.TaskDTO)
+		expected Errors
+	}{
+		{name: "Корректная задача", modify: func(*db.TaskDTO) {}},
+		{name: "Пустой текст", modify: func(dto *db.TaskDTO) { dto.Text = "   " }, expected: Errors{
+			{Field: "text", Code: CodeRequired, Message: "Task text cannot be empty"},
+		}},
+		{name: "Длинный текст", modify: func(dto *db.TaskDTO) { dto.Text = strings.Repeat("a", 256) }, expected: Errors{
+			{Field: "text", Code: CodeTooLong, Message: "Task text cannot exceed 255 characters"},
+		}},
+		{name: "Длина в символах, а не в байтах", modify: func(dto *db.TaskDTO) { dto.Text = strings.Repeat("я", 255) }},
+		{name: "Нет дат", modify: func(dto *db.TaskDTO) { dto.CreatedDate, dto.ExpectedDate = "", "" }, expected: Errors{
+			{Field: "createdDate", Code: CodeRequired, Message: "Task created date is required"},
+			{Field: "expectedDate", Code: CodeRequired, Message: "Task expected date is required"},
+		}},
+		{name: "Неверный формат даты", modify: func(dto *db.TaskDTO) { dto.ExpectedDate = "02.10.2023" }, expected: Errors{
+			{Field: "expectedDate", Code: CodeInvalid, Message: "Task expected date must be in YYYY-MM-DD format"},
+		}},
+		{name: "Дата завершения раньше создания", modify: func(dto *db.TaskDTO) { dto.ExpectedDate = "2023-09-30" },
+			expected: Errors{
+				{Field: "expectedDate", Code: CodeDateOrder, Message: "Expected date cannot be earlier than created date"},
+			}},
+		{name: "Несколько нарушений", modify: func(dto *db.TaskDTO) { dto.Text, dto.Status = "", "42" }, expected: Errors{
+			{Field: "text", Code: CodeRequired, Message: "Task text cannot be empty"},
+			{Field: "status", Code: CodeInvalid, Message: "Incorrect task status"},
+		}},
+		{name: "Статус по номеру", modify: func(dto *db.TaskDTO) { dto.Status = "0" }},
+		{name: "Неизвестный статус", modify: func(dto *db.TaskDTO) { dto.Status = "done" }, expected: Errors{
+			{Field: "status", Code: CodeInvalid, Message: "Incorrect task status"},
+		}},
+		{name: "Новая задача не в начальном статусе", modify: func(dto *db.TaskDTO) { dto.Status = "completed" }},
+		{name: "Задача в проекте", modify: func(dto *db.TaskDTO) { project := int64(3); dto.ProjectID = &project }},
+		{name: "Неверный ID проекта", modify: func(dto *db.TaskDTO) { project := int64(0); dto.ProjectID = &project },
+			expected: Errors{
+				{Field: "projectId", Code: CodeInvalid, Message: "Project ID must be a positive number"},
+			}},
+		{name: "Задача с метками", modify: func(dto *db.TaskDTO) { dto.Tags = []string{"Bug", "ui"} }},
+		{name: "Метка с запятой", modify: func(dto *db.TaskDTO) { dto.Tags = []string{"bug,ui"} }, expected: Errors{
+			{Field: "tags", Code: CodeInvalid, Message: "Tag cannot contain commas"},
+		}},
+		{name: "Срочная задача", modify: func(dto *db.TaskDTO) { dto.Priority = "urgent" }},
+		{name: "Неизвестный приоритет", modify: func(dto *db.TaskDTO) { dto.Priority = "critical" }, expected: Errors{
+			{Field: "priority", Code: CodeInvalid, Message: "Priority must be one of low, normal, high, urgent"},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dto := valid
+			tc.modify(&dto)
+
+			_, err := NewTask(defaultWorkflows(), dto)
+			if tc.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var errs Errors
+			assert.True(t, errors.As(err, &errs))
+			assert.Equal(t, tc.expected, errs)
+		})
+	}
+}
+
+// Тест для преобразования проверенной задачи. Задача без статуса получает начальный статус.
+func TestTaskConversion(t *testing.T) {
+	task, err := NewTask(defaultWorkflows(), db.TaskDTO{ID: 7, Text: "  Task  ", CreatedDate: "2023-10-01",
+		ExpectedDate: "2023-10-02", Version: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, db.Task{
+		ID:           7,
+		Text:         "Task",
+		CreatedDate:  time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC),
+		ExpectedDate: time.Date(2023, 10, 2, 0, 0, 0, 0, time.UTC),
+		Status:       db.StatusInProgress,
+		Version:      3,
+	}, task)
+}
+
+// Тест для проверки смены статуса при изменении задачи.
+func TestUpdatedTask(t *testing.T) {
+	current := db.Task{ID: 1, Status: db.StatusInProgress}
+	dto := db.TaskDTO{Text: "Task", CreatedDate: "2023-10-01", ExpectedDate: "2023-10-02", Status: "testing"}
+	workflows := defaultWorkflows()
+
+	task, err := UpdatedTask(workflows, current, dto)
+	assert.NoError(t, err)
+	assert.Equal(t, db.StatusTesting, task.Status)
+
+	dto.Status = "completed"
+	dto.Text = ""
+	_, err = UpdatedTask(workflows, current, dto)
+	var errs Errors
+	assert.True(t, errors.As(err, &errs))
+	assert.Equal(t, Errors{
+		{Field: "text", Code: CodeRequired, Message: "Task text cannot be empty"},
+		{Field: "status", Code: CodeTransition,
+			Message: "Cannot change task status from in_progress to completed (allowed: testing)"},
+	}, errs)
+
+	// Переходы берутся из рабочего процесса, а не из встроенной таблицы.
+	workflows.Default.Statuses[0].Transitions = append(workflows.Default.Statuses[0].Transitions, "completed")
+	dto.Text = "Task"
+	task, err = UpdatedTask(workflows, current, dto)
+	assert.NoError(t, err)
+	assert.Equal(t, db.StatusCompleted, task.Status)
+}
+
+// Тест для рабочего процесса проекта: статус задачи ищется в рабочем процессе её проекта,
+// а при переносе в проект с другим рабочим процессом переходы не проверяются.
+func TestUpdatedTaskProjectWorkflow(t *testing.T) {
+	workflows := defaultWorkflows()
+	workflows.Projects = map[int64]db.Workflow{7: {Statuses: []db.StatusDef{
+		{ID: 10, Name: "todo", Position: 10, Transitions: []string{"review"}},
+		{ID: 11, Name: "review", Position: 20, Transitions: []string{"todo"}},
+		{ID: 12, Name: "done", Position: 30, Terminal: true},
+	}}}
+	project := int64(7)
+	dto := db.TaskDTO{Text: "Task", CreatedDate: "2023-10-01", ExpectedDate: "2023-10-02", ProjectID: &project}
+
+	task, err := NewTask(workflows, dto)
+	assert.NoError(t, err)
+	assert.Equal(t, db.Status(10), task.Status)
+
+	dto.Status = "done"
+	task, err = UpdatedTask(workflows, db.Task{ID: 1, Status: db.StatusInProgress}, dto)
+	assert.NoError(t, err)
+	assert.Equal(t, db.Status(12), task.Status)
+
+	testCases := []struct {
+		name    string
+		current db.Task
+		status  db.StatusRef
+		code    string
+	}{
+		{name: "Статус другого рабочего процесса", current: db.Task{ID: 1, Status: db.StatusInProgress},
+			status: "testing", code: CodeInvalid},
+		{name: "Переход внутри проекта", current: db.Task{ID: 1, Status: 10, ProjectID: project},
+			status: "done", code: CodeTransition},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dto.Status = tc.status
+			_, err := UpdatedTask(workflows, tc.current, dto)
+			var errs Errors
+			if assert.True(t, errors.As(err, &errs)) && assert.Len(t, errs, 1) {
+				assert.Equal(t, "status", errs[0].Field)
+				assert.Equal(t, tc.code, errs[0].Code)
+			}
+		})
+	}
+}
+
+// Тест для приоритета при изменении задачи: без поля priority задача сохраняет текущий приоритет.
+func TestUpdatedTaskPriority(t *testing.T) {
+	current := db.Task{ID: 1, Status: db.StatusInProgress, Priority: db.PriorityHigh}
+	dto := db.TaskDTO{Text: "Task", CreatedDate: "2023-10-01", ExpectedDate: "2023-10-02", Status: "in_progress"}
+
+	task, err := UpdatedTask(defaultWorkflows(), current, dto)
+	assert.NoError(t, err)
+	assert.Equal(t, db.PriorityHigh, task.Priority)
+
+	dto.Priority = "low"
+	task, err = UpdatedTask(defaultWorkflows(), current, dto)
+	assert.NoError(t, err)
+	assert.Equal(t, db.PriorityLow, task.Priority)
+}
+
+// Тест для причины смены статуса: причина обрезается по краям и принимается только вместе со сменой статуса.
+func TestUpdatedTaskReason(t *testing.T) {
+	current := db.Task{ID: 1, Status: db.StatusInProgress}
+	dto := db.TaskDTO{Text: "Task", CreatedDate: "2023-10-01", ExpectedDate: "2023-10-02", Status: "testing",
+		Reason: "  Готово к проверке "}
+
+	task, err := UpdatedTask(defaultWorkflows(), current, dto)
+	assert.NoError(t, err)
+	assert.Equal(t, "Готово к проверке", task.Reason)
+
+	testCases := []struct {
+		name   string
+		status db.StatusRef
+		reason string
+		code   string
+	}{
+		{name: "Причина без смены статуса", status: "in_progress", reason: "Без изменений", code: CodeInvalid},
+		{name: "Слишком длинная причина", status: "testing", reason: strings.Repeat("а", MaxReasonLength+1),
+			code: CodeTooLong},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dto.Status, dto.Reason = tc.status, tc.reason
+			_, err := UpdatedTask(defaultWorkflows(), current, dto)
+			var errs Errors
+			if assert.True(t, errors.As(err, &errs)) && assert.Len(t, errs, 1) {
+				assert.Equal(t, "reason", errs[0].Field)
+				assert.Equal(t, tc.code, errs[0].Code)
+			}
+		})
+	}
+}
+
+// Тест для текста ошибки со списком нарушений.
+func TestErrorsError(t *testing.T) {
+	errs := Errors{{Field: "text", Message: "first"}, {Field: "status", Message: "second"}}
+	assert.Equal(t, "first; second", errs.Error())
+}
+
+// Функция defaultWorkflows возвращает рабочие процессы, в которых есть только встроенный рабочий процесс.
+func defaultWorkflows() db.Workflows {
+	return db.Workflows{Default: db.DefaultWorkflow()}
+}
@@ -0,0 +1,63 @@
+package validation
+
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+)
+
+// Максимальная длина имени токена в символах (соответствует колонке name VARCHAR(64)).
+const MaxTokenNameLength = 64
+
+// Срок действия токена по умолчанию и максимальный срок действия.
+const (
+	DefaultTokenTTL = 90 * 24 * time.Hour
+	MaxTokenTTL     = 365 * 24 * time.Hour
+)
+
+// Функция NewAPIToken проверяет запрос на создание персонального токена доступа на момент now
+// и возвращает токен без владельца и хеша. Повторяющиеся разрешения отбрасываются,
+// а без указанного срока действия токен действует DefaultTokenTTL.
+func NewAPIToken(req db.APITokenRequest, now time.Time) (db.APIToken, error) {
+	var errs Errors
+	token := db.APIToken{Name: strings.TrimSpace(req.Name)}
+
+	switch {
+	case token.Name == "":
+		errs.add("name", CodeRequired, "Token name is required")
+	case utf8.RuneCountInString(token.Name) > MaxTokenNameLength:
+		errs.add("name", CodeTooLong, "Token name cannot exceed 64 characters")
+	}
+
+	if len(req.Scopes) == 0 {
+		errs.add("scopes", CodeRequired, "At least one scope is required")
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(db.Scopes, scope) {
+			errs.add("scopes", CodeInvalid, "Unknown scope: "+scope)
+			continue
+		}
+		if !slices.Contains(token.Scopes, scope) {
+			token.Scopes = append(token.Scopes, scope)
+		}
+	}
+
+	token.ExpiresAt = now.Add(DefaultTokenTTL)
+	if req.ExpiresAt != nil {
+		token.ExpiresAt = *req.ExpiresAt
+	}
+	switch {
+	case !token.ExpiresAt.After(now):
+		errs.add("expiresAt", CodeInvalid, "Token expiration must be in the future")
+	case token.ExpiresAt.Sub(now) > MaxTokenTTL:
+		errs.add("expiresAt", CodeInvalid, "Token cannot be valid for more than 365 days")
+	}
+
+	if len(errs) > 0 {
+		return db.APIToken{}, errs
+	}
+	return token, nil
+}
@@ -0,0 +1,73 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для проверки запроса на создание персонального токена доступа.
+func TestNewAPIToken(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	tooLate := now.Add(MaxTokenTTL + time.Hour)
+
+	testCases := []struct {
+		name     string
+		req      db.APITokenRequest
+		expected Errors
+	}{
+		{name: "Корректный запрос", req: db.APITokenRequest{Name: "ci", Scopes: []string{db.ScopeTasksRead}}},
+		{name: "Нет данных", req: db.APITokenRequest{Name: " "}, expected: Errors{
+			{Field: "name", Code: CodeRequired, Message: "Token name is required"},
+			{Field: "scopes", Code: CodeRequired, Message: "At least one scope is required"},
+		}},
+		{name: "Длинное имя", req: db.APITokenRequest{Name: strings.Repeat("я", 65), Scopes: []string{db.ScopeTasksRead}},
+			expected: Errors{
+				{Field: "name", Code: CodeTooLong, Message: "Token name cannot exceed 64 characters"},
+			}},
+		{name: "Неизвестное разрешение", req: db.APITokenRequest{Name: "ci", Scopes: []string{"tasks:admin"}},
+			expected: Errors{
+				{Field: "scopes", Code: CodeInvalid, Message: "Unknown scope: tasks:admin"},
+			}},
+		{name: "Срок в прошлом", req: db.APITokenRequest{Name: "ci", Scopes: []string{db.ScopeTasksRead}, ExpiresAt: &past},
+			expected: Errors{
+				{Field: "expiresAt", Code: CodeInvalid, Message: "Token expiration must be in the future"},
+			}},
+		{name: "Слишком долгий срок",
+			req: db.APITokenRequest{Name: "ci", Scopes: []string{db.ScopeTasksRead}, ExpiresAt: &tooLate},
+			expected: Errors{
+				{Field: "expiresAt", Code: CodeInvalid, Message: "Token cannot be valid for more than 365 days"},
+			}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewAPIToken(tc.req, now)
+			if tc.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var errs Errors
+			assert.True(t, errors.As(err, &errs))
+			assert.Equal(t, tc.expected, errs)
+		})
+	}
+}
+
+// Тест для значений по умолчанию: срок действия и удаление повторяющихся разрешений.
+func TestNewAPITokenDefaults(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	token, err := NewAPIToken(db.APITokenRequest{
+		Name:   " ci ",
+		Scopes: []string{db.ScopeTasksRead, db.ScopeTasksWrite, db.ScopeTasksRead},
+	}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, "ci", token.Name)
+	assert.Equal(t, []string{db.ScopeTasksRead, db.ScopeTasksWrite}, token.Scopes)
+	assert.Equal(t, now.Add(DefaultTokenTTL), token.ExpiresAt)
+}
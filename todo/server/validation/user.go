@@ -0,0 +1,51 @@
+package validation
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+)
+
+// Ограничения длины пароля в байтах: bcrypt учитывает только первые 72 байта.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// Имя пользователя: латинские буквы, цифры, ".", "_" и "-", от 3 до 32 символов.
+var usernamePattern = regexp.MustCompile(`^[a-z0-9._-]{3,32}$`)
+
+// Функция NewUser проверяет имя и пароль при регистрации пользователя.
+// Имя приводится к нижнему регистру, поэтому "Alice" и "alice" - один пользователь.
+// Занятость имени проверяет хранилище (db.ErrUserExists).
+func NewUser(creds db.Credentials) (db.Credentials, error) {
+	var errs Errors
+	creds.Username = NormalizeUsername(creds.Username)
+
+	switch {
+	case creds.Username == "":
+		errs.add("username", CodeRequired, "Username is required")
+	case !usernamePattern.MatchString(creds.Username):
+		errs.add("username", CodeInvalid, "Username must be 3-32 characters: letters, digits, '.', '_' or '-'")
+	}
+
+	switch {
+	case creds.Password == "":
+		errs.add("password", CodeRequired, "Password is required")
+	case len(creds.Password) < MinPasswordLength:
+		errs.add("password", CodeTooShort, "Password must be at least 8 characters")
+	case len(creds.Password) > MaxPasswordLength:
+		errs.add("password", CodeTooLong, "Password cannot exceed 72 bytes")
+	}
+
+	if len(errs) > 0 {
+		return db.Credentials{}, errs
+	}
+	return creds, nil
+}
+
+// Функция NormalizeUsername приводит имя пользователя к виду, в котором оно хранится.
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
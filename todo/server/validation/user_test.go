@@ -0,0 +1,56 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Mr-Cheen1/todo_list/server/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тест для проверки имени и пароля при регистрации.
+func TestNewUser(t *testing.T) {
+	testCases := []struct {
+		name     string
+		creds    db.Credentials
+		expected Errors
+	}{
+		{name: "Корректные данные", creds: db.Credentials{Username: "alice", Password: "password"}},
+		{name: "Нет данных", creds: db.Credentials{Username: "  "}, expected: Errors{
+			{Field: "username", Code: CodeRequired, Message: "Username is required"},
+			{Field: "password", Code: CodeRequired, Message: "Password is required"},
+		}},
+		{name: "Недопустимые символы", creds: db.Credentials{Username: "alice smith", Password: "password"}, expected: Errors{
+			{Field: "username", Code: CodeInvalid,
+				Message: "Username must be 3-32 characters: letters, digits, '.', '_' or '-'"},
+		}},
+		{name: "Короткий пароль", creds: db.Credentials{Username: "alice", Password: "secret"}, expected: Errors{
+			{Field: "password", Code: CodeTooShort, Message: "Password must be at least 8 characters"},
+		}},
+		{name: "Длинный пароль", creds: db.Credentials{Username: "alice", Password: strings.Repeat("я", 37)},
+			expected: Errors{
+				{Field: "password", Code: CodeTooLong, Message: "Password cannot exceed 72 bytes"},
+			}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewUser(tc.creds)
+			if tc.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var errs Errors
+			assert.True(t, errors.As(err, &errs))
+			assert.Equal(t, tc.expected, errs)
+		})
+	}
+}
+
+// Тест для приведения имени пользователя к нижнему регистру.
+func TestNewUserNormalizesUsername(t *testing.T) {
+	creds, err := NewUser(db.Credentials{Username: " Alice ", Password: "password"})
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", creds.Username)
+}
@@ -1,8 +1,295 @@
+// Статусы рабочего процесса по умолчанию в порядке следования (загружаются с сервера).
+let statuses = [];
+
+// Статусы рабочих процессов проектов выбранного списка по ID проекта. Для проекта без собственного
+// рабочего процесса сервер возвращает рабочий процесс по умолчанию.
+let projectStatuses = {};
+
+// Названия приоритетов задачи по их именам в API.
+const priorityTitles = { low: 'Низкий', normal: 'Обычный', high: 'Высокий', urgent: 'Срочный' };
+
 // Обработчик события DOMContentLoaded.
 document.addEventListener('DOMContentLoaded', async function() {
+  const response = await fetch('/api/auth/me');
+  if (response.ok) {
+    await showApp(await response.json());
+  } else {
+    document.getElementById('auth-form').style.display = 'block';
+  }
+});
+
+// Функция отображения списка задач после входа пользователя.
+async function showApp(user) {
+  document.getElementById('auth-form').style.display = 'none';
+  document.getElementById('app').style.display = 'block';
+  document.getElementById('current-user').textContent = user.username;
+  await loadLists();
+  await loadStatuses();
+  await loadProjects();
+  await refreshTaskList();
+}
+
+// Функция входа или регистрации пользователя по данным из формы входа.
+async function authenticate(path) {
+  const response = await fetch(path, {
+    method: 'POST',
+    headers: {
+      'Content-Type': 'application/json'
+    },
+    body: JSON.stringify({
+      username: document.getElementById('username-input').value,
+      password: document.getElementById('password-input').value
+    })
+  });
+
+  if (!response.ok) {
+    const error = await responseError(response, 'Error when logging in');
+    if (error instanceof ValidationError) {
+      highlightInvalidFields({
+        username: document.getElementById('username-input'),
+        password: document.getElementById('password-input')
+      }, error.errors);
+    }
+    alert(error.message);
+    return;
+  }
+  await showApp(await response.json());
+}
+
+// Обработчик отправки формы входа.
+document.getElementById('auth-form').addEventListener('submit', async function(e) {
+  e.preventDefault();
+  await authenticate('/api/auth/login');
+});
+
+// Обработчик кнопки регистрации.
+document.getElementById('register-btn').addEventListener('click', async function() {
+  await authenticate('/api/auth/register');
+});
+
+// Обработчик кнопки выхода.
+document.getElementById('logout-btn').addEventListener('click', async function() {
+  await fetch('/api/auth/logout', { method: 'POST' });
+  window.location.reload();
+});
+
+// Функция загрузки статусов рабочего процесса по умолчанию.
+async function loadStatuses() {
+  const response = await fetch('/api/statuses');
+  if (!response.ok) {
+    throw await responseError(response, 'Error when loading statuses');
+  }
+  statuses = await response.json();
+}
+
+// Функция получения статусов рабочего процесса задач проекта projectId (null - задач без проекта).
+function workflowStatuses(projectId) {
+  return (projectId !== null && projectStatuses[projectId]) || statuses;
+}
+
+// Функция заполнения фильтра по статусу статусами выбранного проекта. Если проект не выбран,
+// в фильтре перечисляются статусы всех рабочих процессов без повторов имен.
+// Выбранный статус сохраняется, если он есть среди новых вариантов.
+function fillStatusFilter() {
+  const projectFilter = document.getElementById('project-filter').value;
+  let options = workflowStatuses(projectFilter === 'none' ? null : selectedProjectId());
+  if (projectFilter === '') {
+    options = [statuses, ...Object.values(projectStatuses)].flat()
+      .filter((status, index, all) => all.findIndex(other => other.name === status.name) === index);
+  }
+
+  const statusFilter = document.getElementById('status-filter');
+  const selected = statusFilter.value;
+  // Первый вариант - "Все" - не зависит от рабочего процесса.
+  while (statusFilter.options.length > 1) {
+    statusFilter.remove(1);
+  }
+  options.forEach(status => {
+    const option = document.createElement('option');
+    option.value = status.name;
+    option.textContent = status.title;
+    statusFilter.appendChild(option);
+  });
+  statusFilter.value = options.some(status => status.name === selected) ? selected : '';
+}
+
+// Названия ролей участников общего списка задач.
+const ROLE_TITLES = {
+  viewer: 'наблюдатель',
+  editor: 'редактор',
+  admin: 'администратор'
+};
+
+// Списки задач, доступные пользователю (первым - собственный), и выбранный список.
+let taskLists = [];
+let currentList = null;
+
+// Функция загрузки списков задач и заполнения переключателя списков.
+async function loadLists() {
+  const response = await fetch('/api/lists');
+  if (!response.ok) {
+    throw await responseError(response, 'Error when loading task lists');
+  }
+  taskLists = await response.json();
+  currentList = taskLists[0] || null;
+
+  const listSwitcher = document.getElementById('list-switcher');
+  taskLists.forEach((list, index) => {
+    const option = document.createElement('option');
+    option.value = list.id;
+    option.textContent = index === 0 ? 'Мои задачи' : `${list.owner} (${ROLE_TITLES[list.role] || list.role})`;
+    listSwitcher.appendChild(option);
+  });
+  // Переключатель нужен, только если пользователю открыты чужие списки.
+  listSwitcher.style.display = taskLists.length > 1 ? 'inline' : 'none';
+}
+
+// Функция добавления к пути API параметра list, если выбран чужой общий список.
+function listPath(path) {
+  if (!currentList || currentList === taskLists[0]) {
+    return path;
+  }
+  return `${path}${path.includes('?') ? '&' : '?'}list=${currentList.id}`;
+}
+
+// Функция проверки, может ли пользователь изменять задачи выбранного списка.
+function canEdit() {
+  return !currentList || currentList.role !== 'viewer';
+}
+
+// Обработчик переключения списка задач. Наблюдателю форма создания задачи не показывается.
+// У каждого списка свои проекты, поэтому они загружаются заново.
+document.getElementById('list-switcher').addEventListener('change', async function(e) {
+  currentList = taskLists[e.target.selectedIndex];
+  document.getElementById('task-form').style.display = canEdit() ? '' : 'none';
+  document.getElementById('new-project-btn').style.display = canEdit() ? '' : 'none';
+  await loadProjects();
   await refreshTaskList();
 });
 
+// Проекты выбранного списка задач в порядке имен (загружаются с сервера).
+let projects = [];
+
+// Функция загрузки проектов выбранного списка и их рабочих процессов и заполнения фильтров
+// по проекту и статусу. Выбранный проект сохраняется, если он есть в загруженном списке.
+async function loadProjects() {
+  const response = await fetch(listPath('/api/projects'));
+  if (!response.ok) {
+    throw await responseError(response, 'Error when loading projects');
+  }
+  projects = await response.json();
+
+  projectStatuses = {};
+  for (const project of projects) {
+    const statusesResponse = await fetch(listPath(`/api/projects/${project.id}/statuses`));
+    if (!statusesResponse.ok) {
+      throw await responseError(statusesResponse, 'Error when loading project statuses');
+    }
+    projectStatuses[project.id] = await statusesResponse.json();
+  }
+
+  const projectFilter = document.getElementById('project-filter');
+  const selected = projectFilter.value;
+  // Первые два варианта - "Все" и "Без проекта" - не зависят от списка.
+  while (projectFilter.options.length > 2) {
+    projectFilter.remove(2);
+  }
+  projects.forEach(project => {
+    const option = document.createElement('option');
+    option.value = project.id;
+    option.textContent = project.name;
+    projectFilter.appendChild(option);
+  });
+  projectFilter.value = Array.from(projectFilter.options).some(option => option.value === selected) ? selected : '';
+  fillStatusFilter();
+}
+
+// Функция получения ID проекта, выбранного в фильтре, или null, если проект не выбран.
+function selectedProjectId() {
+  const value = document.getElementById('project-filter').value;
+  return /^\d+$/.test(value) ? parseInt(value) : null;
+}
+
+// Обработчик изменения фильтра по проекту. У проекта может быть свой рабочий процесс,
+// поэтому фильтр по статусу заполняется заново.
+document.getElementById('project-filter').addEventListener('change', async function() {
+  fillStatusFilter();
+  await refreshTaskList();
+});
+
+// Обработчик кнопки создания проекта. Созданный проект выбирается в фильтре.
+document.getElementById('new-project-btn').addEventListener('click', async function() {
+  const name = prompt('Название проекта:');
+  if (name === null || name.trim() === '') {
+    return;
+  }
+  try {
+    const response = await fetch(listPath('/api/projects'), {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json'
+      },
+      body: JSON.stringify({ name: name })
+    });
+    if (!response.ok) {
+      throw await responseError(response, 'Error when creating a project');
+    }
+    const project = await response.json();
+    await loadProjects();
+    document.getElementById('project-filter').value = project.id;
+    await refreshTaskList();
+  } catch (error) {
+    alert(error.message);
+  }
+});
+
+// Функция разбора меток, введенных через запятую.
+function parseTags(value) {
+  return value.split(',').map(tag => tag.trim().toLowerCase()).filter(tag => tag !== '');
+}
+
+// Функция загрузки подсказок меток для поля ввода: подсказывается последняя вводимая метка,
+// а варианты в списке дополняют уже введенные метки.
+async function loadTagSuggestions(input) {
+  const parts = input.value.split(',');
+  const prefix = parts.pop().trim();
+  const entered = parts.map(tag => tag.trim()).filter(tag => tag !== '');
+  const response = await fetch(listPath(`/api/tags?${new URLSearchParams({ prefix: prefix })}`));
+  if (!response.ok) {
+    return;
+  }
+  const tags = await response.json();
+
+  const datalist = document.getElementById('tag-suggestions');
+  datalist.innerHTML = '';
+  tags.filter(tag => !entered.includes(tag.name)).forEach(tag => {
+    const option = document.createElement('option');
+    option.value = [...entered, tag.name].join(', ');
+    option.label = `${tag.name} (${tag.count})`;
+    datalist.appendChild(option);
+  });
+}
+
+// Задержка перед фильтрацией по меткам, как и при поиске.
+let tagFilterTimer = null;
+
+// Обработчики ввода меток: подсказки и, для фильтра, обновление списка задач.
+document.getElementById('tags-input').addEventListener('input', function() {
+  loadTagSuggestions(this);
+});
+document.getElementById('tag-filter').addEventListener('input', function() {
+  loadTagSuggestions(this);
+  clearTimeout(tagFilterTimer);
+  tagFilterTimer = setTimeout(refreshTaskList, 300);
+});
+
+// Обработчик изменения режима фильтра по меткам.
+document.getElementById('tag-match').addEventListener('change', async function() {
+  if (parseTags(document.getElementById('tag-filter').value).length > 0) {
+    await refreshTaskList();
+  }
+});
+
 // Обработчик отправки формы создания задачи.
 document.getElementById('task-form').addEventListener('submit', async function(e) {
   e.preventDefault();
@@ -10,6 +297,8 @@ document.getElementById('task-form').addEventListener('submit', async function(e
   const taskText = taskInput.value.trim();
   const expectedDateInput = document.getElementById('expected-date-input');
   const expectedDate = expectedDateInput.value;
+  const tagsInput = document.getElementById('tags-input');
+  const priorityInput = document.getElementById('priority-input');
 
   if (taskText === '') {
     alert('Введите текст задачи');
@@ -34,19 +323,32 @@ document.getElementById('task-form').addEventListener('submit', async function(e
     return;
   }
 
+  // Статус не передается: сервер создает задачу в начальном статусе рабочего процесса.
+  // Задача создается в проекте, выбранном в фильтре.
   const task = {
     text: taskText,
     createdDate: currentDate.toISOString().slice(0, 10),
     expectedDate: new Date(expectedDate + 'T00:00:00Z').toISOString().slice(0, 10),
-    status: 0
+    projectId: selectedProjectId(),
+    tags: parseTags(tagsInput.value),
+    priority: priorityInput.value
   };
 
+  const inputs = { text: taskInput, expectedDate: expectedDateInput, tags: tagsInput, priority: priorityInput };
   try {
     await createTask(task);
+    highlightInvalidFields(inputs, []);
     taskInput.value = '';
     expectedDateInput.value = '';
+    tagsInput.value = '';
+    priorityInput.value = 'normal';
     await refreshTaskList();
   } catch (error) {
+    if (error instanceof ValidationError) {
+      highlightInvalidFields(inputs, error.errors);
+      alert(error.message);
+      return;
+    }
     console.error('Error when creating a task:', error);
     alert('An error occurred while creating a task. Please try again.');
   }
@@ -55,15 +357,38 @@ document.getElementById('task-form').addEventListener('submit', async function(e
 // Обработчик кликов по списку задач (удаление и редактирование).
 document.getElementById('task-list').addEventListener('click', async function(e) {
   if (e.target.classList.contains('delete-btn')) {
-    const taskId = e.target.parentElement.dataset.taskId;
+    const taskItem = e.target.parentElement;
     try {
-      await deleteTask(taskId);
+      await deleteTask(taskItem.dataset.taskId, taskItem.dataset.version);
       await refreshTaskList();
     } catch (error) {
+      if (await handleConflict(error, taskItem)) {
+        return;
+      }
       console.error('Error when deleting a task:', error);
     }
   }
 
+  if (e.target.classList.contains('restore-btn')) {
+    const taskItem = e.target.parentElement;
+    try {
+      await restoreTask(taskItem.dataset.taskId);
+      await refreshTaskList();
+    } catch (error) {
+      console.error('Error when restoring a task:', error);
+      alert(error.message);
+    }
+  }
+
+  if (e.target.classList.contains('history-btn')) {
+    const taskItem = e.target.parentElement;
+    try {
+      alert(await fetchTaskHistory(taskItem.dataset.taskId));
+    } catch (error) {
+      console.error('Error when loading task history:', error);
+    }
+  }
+
   if (e.target.classList.contains('edit-btn')) {
     const taskItem = e.target.parentElement;
     const taskId = taskItem.dataset.taskId;
@@ -82,16 +407,14 @@ document.getElementById('task-list').addEventListener('click', async function(e)
       statusSelect.style.display = 'none';
       e.target.textContent = 'Сохранить';
     } else {
-      const updatedTask = {
-        id: parseInt(taskId),
+      // Отправляются только редактируемые поля, остальные сервер берет из текущей задачи.
+      const changes = {
         text: editInput.value.trim(),
-        createdDate: new Date(taskItem.querySelector('.task-created-date').textContent).toISOString().slice(0, 10),
-        expectedDate: expectedDateInput.value? new Date(expectedDateInput.value).toISOString().slice(0, 10) : null,
-        status: parseInt(statusSelect.value)
+        expectedDate: expectedDateInput.value
       };
 
       // Валидация полей задачи.
-      if (updatedTask.text === '') {
+      if (changes.text === '') {
         alert('Текст задачи не может быть пустым');
         return;
       }
@@ -101,18 +424,13 @@ document.getElementById('task-list').addEventListener('click', async function(e)
         return;
       }
 
-      if (updatedTask.expectedDate && new Date(updatedTask.expectedDate) < new Date(updatedTask.createdDate)) {
-        alert('Планируемая дата завершения не может быть раньше даты создания задачи');
-        return;
-      }
-
-      if (updatedTask.text.length > 255) {
+      if (changes.text.length > 255) {
         alert('Текст задачи не может превышать 255 символов');
         return;
       }
 
       try {
-        await updateTask(updatedTask);
+        await patchTask(taskId, taskItem.dataset.version, changes);
         taskText.textContent = editInput.value;
         taskItem.querySelector('.task-expected-date').textContent = expectedDateInput.value;
         editInput.style.display = 'none';
@@ -121,9 +439,18 @@ document.getElementById('task-list').addEventListener('click', async function(e)
         taskItem.querySelector('.task-expected-date').style.display = 'inline';
         statusSelect.style.display = 'inline';
         e.target.textContent = 'Редактировать';
-        await refreshTaskList();
+        await refreshTaskItem(taskItem);
       } catch (error) {
+        if (await handleConflict(error, taskItem)) {
+          return;
+        }
+        if (error instanceof ValidationError) {
+          highlightInvalidFields({ text: editInput, expectedDate: expectedDateInput }, error.errors);
+          alert(error.message);
+          return;
+        }
         console.error('Error when updating a task:', error);
+        alert(error.message);
       }
     }
   }
@@ -134,6 +461,20 @@ document.getElementById('status-filter').addEventListener('change', async functi
   await refreshTaskList();
 });
 
+// Обработчик переключения между списком задач и корзиной.
+document.getElementById('trash-toggle').addEventListener('change', async function() {
+  await refreshTaskList();
+});
+
+// Задержка перед поиском, чтобы не отправлять запрос на каждое нажатие клавиши.
+let searchTimer = null;
+
+// Обработчик ввода поискового запроса.
+document.getElementById('search-input').addEventListener('input', function() {
+  clearTimeout(searchTimer);
+  searchTimer = setTimeout(refreshTaskList, 300);
+});
+
 // Обработчик изменения фильтра сортировки.
 document.getElementById('sort-filter').addEventListener('change', async function() {
   await refreshTaskList();
@@ -144,21 +485,20 @@ document.getElementById('task-list').addEventListener('change', async function(e
   if (e.target.classList.contains('status-select')) {
     const taskItem = e.target.closest('.task-item');
     const taskId = parseInt(taskItem.dataset.taskId);
-    const taskText = taskItem.querySelector('.task-text').textContent;
-    const statusSelect = taskItem.querySelector('.status-select');
-
-    const updatedTask = {
-      id: taskId,
-      text: taskText,
-      createdDate: new Date(taskItem.querySelector('.task-created-date').textContent).toISOString().slice(0, 10),
-      expectedDate: new Date(taskItem.querySelector('.task-expected-date').textContent).toISOString().slice(0, 10),
-      status: parseInt(statusSelect.value)
-    };
 
     try {
-      await updateTask(updatedTask);
-      await refreshTaskList();
+      await patchTask(taskId, taskItem.dataset.version, { status: e.target.value });
+      await refreshTaskItem(taskItem);
     } catch (error) {
+      if (await handleConflict(error, taskItem)) {
+        return;
+      }
+      // Недопустимая смена статуса: показываем причину и возвращаем прежний статус в списке.
+      if (error instanceof ValidationError) {
+        alert(error.message);
+        await refreshTaskItem(taskItem);
+        return;
+      }
       console.error('Error when updating task status:', error);
     }
   }
@@ -166,7 +506,7 @@ document.getElementById('task-list').addEventListener('change', async function(e
 
 // Функция создания новой задачи.
 async function createTask(task) {
-  const response = await fetch('/api/tasks/create', {
+  const response = await fetch(listPath('/api/tasks'), {
     method: 'POST',
     headers: {
       'Content-Type': 'application/json'
@@ -175,43 +515,226 @@ async function createTask(task) {
   });
 
   if (!response.ok) {
-    throw new Error('Error when creating a task');
+    throw await responseError(response, 'Error when creating a task');
+  }
+}
+
+// Ошибка проверки задачи на сервере со списком нарушений по полям.
+class ValidationError extends Error {
+  constructor(errors) {
+    super(errors.map(error => error.message).join('\n'));
+    this.errors = errors;
+  }
+}
+
+// Функция чтения ошибки из ответа сервера в формате application/problem+json (RFC 7807):
+// нарушения по полям возвращаются как ValidationError, остальные ошибки - с текстом из detail или title.
+async function responseError(response, fallback) {
+  const contentType = response.headers.get('Content-Type') || '';
+  if (!contentType.includes('application/problem+json')) {
+    return new Error(fallback);
   }
+  const problem = await response.json();
+  if (problem.errors) {
+    return new ValidationError(problem.errors);
+  }
+  return new Error(problem.detail || problem.title || fallback);
 }
 
-// Функция обновления задачи.
-async function updateTask(task) {
-  console.log('Updating task:', task);
-  const response = await fetch(`/api/tasks/update?id=${parseInt(task.id)}`, {
-    method: 'PUT',
+// Функция подсветки полей ввода с ошибками. inputs сопоставляет имя поля в API с полем ввода.
+function highlightInvalidFields(inputs, errors) {
+  Object.values(inputs).forEach(input => {
+    input.classList.remove('invalid');
+    input.title = '';
+  });
+  errors.forEach(error => {
+    const input = inputs[error.field];
+    if (input) {
+      input.classList.add('invalid');
+      input.title = error.message;
+    }
+  });
+}
+
+// Ошибка конфликта версий: задачу успели изменить или удалить в другой вкладке или другим пользователем.
+class ConflictError extends Error {}
+
+// Функция обработки конфликта версий: показывает сообщение и загружает актуальное состояние задачи.
+// Возвращает true, если ошибка была конфликтом и уже обработана.
+async function handleConflict(error, taskItem) {
+  if (!(error instanceof ConflictError)) {
+    return false;
+  }
+  alert('Задачу уже изменил другой пользователь. Загружена актуальная версия, повторите изменение.');
+  await refreshTaskItem(taskItem);
+  return true;
+}
+
+// Функция частичного обновления задачи: передаются только изменяемые поля.
+// Версия задачи передается в заголовке If-Match, чтобы не перезаписать чужие изменения.
+async function patchTask(taskId, version, changes) {
+  const response = await fetch(listPath(`/api/tasks/${parseInt(taskId)}`), {
+    method: 'PATCH',
     headers: {
-      'Content-Type': 'application/json'
+      'Content-Type': 'application/merge-patch+json',
+      'If-Match': `"${version}"`
     },
-    body: JSON.stringify(task)
+    body: JSON.stringify(changes)
   });
 
+  if (response.status === 412) {
+    throw new ConflictError('Task has been modified by another request');
+  }
   if (!response.ok) {
-    throw new Error('Error when updating a task');
+    throw await responseError(response, 'Error when updating a task');
   }
 }
 
 // Функция удаления задачи.
-async function deleteTask(taskId) {
-  const response = await fetch(`/api/tasks/delete?id=${taskId}`, {
+async function deleteTask(taskId, version) {
+  const response = await fetch(listPath(`/api/tasks/${parseInt(taskId)}`), {
     method: 'DELETE',
+    headers: {
+      'If-Match': `"${version}"`
+    }
   });
 
+  if (response.status === 412) {
+    throw new ConflictError('Task has been modified by another request');
+  }
   if (!response.ok) {
-    throw new Error('Error when deleting a task');
+    throw await responseError(response, 'Error when deleting a task');
+  }
+}
+
+// Функция восстановления задачи из корзины.
+async function restoreTask(taskId) {
+  const response = await fetch(listPath(`/api/tasks/${parseInt(taskId)}/restore`), {
+    method: 'POST'
+  });
+
+  if (!response.ok) {
+    throw await responseError(response, 'Error when restoring a task');
+  }
+}
+
+// Названия событий журнала изменений задачи.
+const EVENT_TITLES = {
+  created: 'Создана',
+  updated: 'Изменена',
+  status_changed: 'Смена статуса',
+  deleted: 'Перемещена в корзину',
+  restored: 'Восстановлена',
+  purged: 'Удалена окончательно'
+};
+
+// Функция загрузки журнала изменений задачи в виде текста: по строке на событие.
+async function fetchTaskHistory(taskId) {
+  const response = await fetch(listPath(`/api/tasks/${parseInt(taskId)}/history`));
+  if (!response.ok) {
+    throw await responseError(response, 'Error when loading task history');
+  }
+
+  // Задача могла переходить между проектами, поэтому статус ищется в рабочем процессе задачи,
+  // а затем во всех остальных.
+  const task = document.querySelector(`.task-item[data-task-id="${parseInt(taskId)}"]`);
+  const projectId = task && task.dataset.projectId ? parseInt(task.dataset.projectId) : null;
+  const allStatuses = [workflowStatuses(projectId), statuses, ...Object.values(projectStatuses)].flat();
+  const statusTitle = name => (allStatuses.find(status => status.name === name) || { title: name }).title;
+  const events = await response.json();
+  const lines = events.map(event => {
+    // Для создания и удаления достаточно названия события, для изменений перечисляются поля.
+    const changed = event.type === 'updated' || event.type === 'status_changed';
+    const changes = !changed ? [] : Object.entries(event.changes).map(([field, change]) => field === 'status'
+      ? `${statusTitle(change.old)} → ${statusTitle(change.new)}`
+      : `${field}: ${change.old} → ${change.new}`);
+    if (event.reason) {
+      changes.push(`причина: ${event.reason}`);
+    }
+    const time = new Date(event.createdAt).toLocaleString();
+    return [`${time} ${EVENT_TITLES[event.type] || event.type}`, ...changes].join(', ');
+  });
+  return lines.length > 0 ? lines.join('\n') : 'История изменений пуста.';
+}
+
+// Количество задач, загружаемых за один запрос.
+const PAGE_SIZE = 50;
+
+// Курсор следующей страницы списка задач (пустая строка - страниц больше нет).
+let nextCursor = '';
+
+// Функция проверки, открыта ли корзина вместо списка задач.
+function isTrashOpen() {
+  return document.getElementById('trash-toggle').checked;
+}
+
+// Функция загрузки страницы задач (или задач из корзины) с учетом текущих фильтров.
+async function fetchTaskPage(after) {
+  const params = new URLSearchParams({
+    status: document.getElementById('status-filter').value,
+    project: document.getElementById('project-filter').value,
+    sort: document.getElementById('sort-filter').value,
+    limit: PAGE_SIZE
+  });
+  // Без выбранной сортировки сервер упорядочивает задачи по приоритету и сроку,
+  // а при поиске - по релевантности.
+  const search = document.getElementById('search-input').value.trim();
+  if (search) {
+    params.set('q', search);
+  }
+  // Направление сортировки относится к дате создания, а список ключей (напр. по статусу и сроку) задает порядок сам.
+  if (['asc', 'desc'].includes(params.get('sort'))) {
+    params.set('sortField', 'createdDate');
+  }
+  const tags = parseTags(document.getElementById('tag-filter').value);
+  if (tags.length > 0) {
+    params.set('tags', tags.join(','));
+    params.set('tagMatch', document.getElementById('tag-match').value);
+  }
+  if (after) {
+    params.set('after', after);
+  }
+
+  const response = await fetch(listPath(`${isTrashOpen() ? '/api/trash' : '/api/tasks'}?${params}`));
+  nextCursor = response.headers.get('X-Next-Cursor') || '';
+  return await response.json();
+}
+
+// Функция отображения кнопки загрузки следующей страницы.
+function renderLoadMoreButton(taskList) {
+  const existing = document.getElementById('load-more-item');
+  if (existing) {
+    existing.remove();
+  }
+  if (!nextCursor) {
+    return;
   }
+
+  const item = document.createElement('li');
+  item.id = 'load-more-item';
+  item.className = 'load-more-item';
+  const button = document.createElement('button');
+  button.textContent = 'Показать ещё';
+  button.addEventListener('click', async function() {
+    button.disabled = true;
+    try {
+      const tasks = await fetchTaskPage(nextCursor);
+      tasks.forEach(task => {
+        taskList.insertBefore(createTaskItem(task), item);
+      });
+      renderLoadMoreButton(taskList);
+    } catch (error) {
+      console.error('Error when loading tasks:', error);
+      button.disabled = false;
+    }
+  });
+  item.appendChild(button);
+  taskList.appendChild(item);
 }
 
 // Функция обновления списка задач.
 async function refreshTaskList() {
-  const statusFilter = document.getElementById('status-filter').value;
-  const sortOrder = document.getElementById('sort-filter').value;
-  const response = await fetch(`/api/tasks?status=${statusFilter}&sort=${sortOrder}&sortField=createdDate`);
-  const tasks = await response.json();
+  const tasks = await fetchTaskPage('');
   const taskList = document.getElementById('task-list');
 
   taskList.innerHTML = '';
@@ -247,7 +770,7 @@ async function refreshTaskList() {
 
     const headerStatus = document.createElement('div');
     headerStatus.className = 'task-header';
-    headerStatus.textContent = 'Статус задачи';
+    headerStatus.textContent = isTrashOpen() ? 'Дата удаления' : 'Статус задачи';
     headerStatus.style.flex = '1';
     headerStatus.style.maxWidth = '130px';
     headerStatus.style.display = 'flex'; 
@@ -264,6 +787,7 @@ async function refreshTaskList() {
       const taskItem = createTaskItem(task);
       taskList.appendChild(taskItem);
     });
+    renderLoadMoreButton(taskList);
   } else {
     const emptyMessage = document.createElement('li');
     emptyMessage.textContent = 'Нет задач для отображения.';
@@ -274,27 +798,114 @@ async function refreshTaskList() {
   }
 }
 
+// Функция обновления одной задачи в списке без перезагрузки всего списка.
+async function refreshTaskItem(taskItem) {
+  const response = await fetch(listPath(`/api/tasks/${parseInt(taskItem.dataset.taskId)}`));
+  if (response.status === 404) {
+    taskItem.remove();
+    return;
+  }
+  if (!response.ok) {
+    throw await responseError(response, 'Error when loading a task');
+  }
+
+  const task = await response.json();
+  // Задача, которая больше не подходит под фильтр по статусу, убирается из списка.
+  const statusFilter = document.getElementById('status-filter').value;
+  if (statusFilter !== '' && task.status !== statusFilter) {
+    taskItem.remove();
+    return;
+  }
+  // Так же убирается задача, перенесенная из выбранного проекта.
+  const projectFilter = document.getElementById('project-filter').value;
+  if ((projectFilter === 'none' && task.projectId !== null) || (selectedProjectId() !== null && task.projectId !== selectedProjectId())) {
+    taskItem.remove();
+    return;
+  }
+  taskItem.replaceWith(createTaskItem(task));
+}
+
+// Функция создания списка статусов задачи из рабочего процесса её проекта.
+// Недоступные из текущего статуса переходы отключены.
+function createStatusSelect(task) {
+  const select = document.createElement('select');
+  select.className = 'status-select';
+  const taskStatuses = workflowStatuses(task.projectId);
+  const current = taskStatuses.find(status => status.name === task.status);
+
+  taskStatuses.forEach(status => {
+    const option = document.createElement('option');
+    option.value = status.name;
+    option.textContent = status.title;
+    option.selected = status === current;
+    option.disabled = status !== current && !(current && current.transitions.includes(status.name));
+    select.appendChild(option);
+  });
+  if (current) {
+    select.style.backgroundColor = current.color;
+  }
+
+  return select;
+}
+
+// Функция создания элемента задачи из корзины: задачу можно только восстановить или посмотреть её историю.
+function createTrashItem(task) {
+  const taskItem = document.createElement('li');
+  taskItem.classList.add('task-item', 'deleted');
+  taskItem.dataset.taskId = task.id;
+  taskItem.dataset.version = task.version;
+
+  taskItem.innerHTML = `
+    <div class="task-text">${task.text}</div>
+    <div class="task-created-date">${task.createdDate}</div>
+    <div class="task-expected-date">${task.expectedDate}</div>
+    <div class="task-deleted-at">${new Date(task.deletedAt).toLocaleString()}</div>
+    <button class="restore-btn">Восстановить</button>
+    <button class="history-btn">История</button>
+  `;
+  if (!canEdit()) {
+    taskItem.querySelector('.restore-btn').remove();
+  }
+
+  return taskItem;
+}
+
 // Функция создания элемента задачи.
 function createTaskItem(task) {
+  if (task.deletedAt) {
+    return createTrashItem(task);
+  }
+
+
   const taskItem = document.createElement('li');
   taskItem.classList.add('task-item');
   taskItem.dataset.taskId = task.id;
+  taskItem.dataset.version = task.version;
+  taskItem.dataset.projectId = task.projectId === null ? '' : task.projectId;
+  const status = workflowStatuses(task.projectId).find(status => status.name === task.status);
+  if (status && status.terminal) {
+    taskItem.classList.add('terminal');
+  }
 
   taskItem.innerHTML = `
     <div class="task-text">${task.text}</div>
     <div class="task-created-date">${task.createdDate}</div>
     <div class="task-expected-date">${task.expectedDate}</div>
+    <div class="task-priority priority-${task.priority}">${priorityTitles[task.priority]}</div>
+    <div class="task-tags">${task.tags.map(tag => `<span class="tag">${tag}</span>`).join('')}</div>
     <input type="text" class="edit-input" style="display: none;">
     <input type="date" class="expected-date-input" style="display: none;">
-    <select class="status-select">
-      <option value="0" ${task.status === 0 ? 'selected' : ''}>В процессе</option>
-      <option value="1" ${task.status === 1 ? 'selected' : ''}>Завершено</option>
-      <option value="2" ${task.status === 2 ? 'selected' : ''}>Тестирование</option>
-      <option value="3" ${task.status === 3 ? 'selected' : ''}>Возвращено</option>
-    </select>
     <button class="edit-btn">Редактировать</button>
+    <button class="history-btn">История</button>
     <button class="delete-btn">Удалить</button>
   `;
+  taskItem.insertBefore(createStatusSelect(task), taskItem.querySelector('.edit-btn'));
+  // Наблюдатель общего списка видит задачи, но не может их изменять.
+  if (!canEdit()) {
+    taskItem.querySelector('.status-select').disabled = true;
+    taskItem.querySelector('.edit-btn').remove();
+    taskItem.querySelector('.delete-btn').remove();
+  }
 
   return taskItem;
 }